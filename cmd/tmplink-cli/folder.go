@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"tmplink_uploader/internal/api"
+)
+
+// resolveFolder 按 -folder 指定的路径查找目标文件夹的目录ID，create 为 true 时创建不存在的文件夹
+// 网络错误按重试策略重试（已创建的文件夹在重试时会被找到，不会重复创建）
+func resolveFolder(ctx context.Context, config *Config, path string, create bool) (string, error) {
+	var folderID string
+	err := config.Retry.Do(ctx, func(format string, args ...interface{}) {
+		debugPrint(config, format, args...)
+	}, func() error {
+		var err error
+		folderID, err = config.API.ResolveFolderPath(ctx, path, create)
+		return err
+	})
+
+	var notFound *api.FolderNotFoundError
+	if errors.As(err, &notFound) {
+		return "", &UploadError{
+			Kind:    ErrKindDestination,
+			Message: fmt.Sprintf("%v (使用 -create-folder 自动创建)", err),
+		}
+	}
+	return folderID, err
+}
+
+// printFolders 列出 path 下的子文件夹及其目录ID，path 为空时列出根目录
+func printFolders(ctx context.Context, config *Config, path string) error {
+	parentID, err := resolveFolder(ctx, config, path, false)
+	if err != nil {
+		return err
+	}
+
+	var listResp *api.FolderListResponse
+	err = config.Retry.Do(ctx, func(format string, args ...interface{}) {
+		debugPrint(config, format, args...)
+	}, func() error {
+		var err error
+		listResp, err = config.API.ListFolders(ctx, parentID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if listResp.Status != 1 {
+		return fmt.Errorf("获取文件夹列表失败 (状态码: %d): %s", listResp.Status, listResp.Msg)
+	}
+
+	parentPath := api.JoinFolderPath(api.SplitFolderPath(path))
+	fmt.Printf("📁 %s (目录ID: %s)\n", parentPath, parentID)
+	if len(listResp.Folders) == 0 {
+		fmt.Println("   (没有子文件夹)")
+		return nil
+	}
+	for _, folder := range listResp.Folders {
+		fmt.Printf("   %-30s 目录ID: %s\n", folder.Name, folder.MrID)
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// resumeJournalMaxAge 续传记录的保留时间，超过后在启动时清理
+const resumeJournalMaxAge = 30 * 24 * time.Hour
+
+// resumeEntry 单个文件的续传记录
+// 文件路径、大小和修改时间共同决定记录是否有效，文件发生变化后视为新文件
+type resumeEntry struct {
+	TaskID   string    `json:"task_id"`
+	FilePath string    `json:"file_path"` // 绝对路径
+	FileSize int64     `json:"file_size"`
+	ModTime  time.Time `json:"mod_time"`
+	SHA1     string    `json:"sha1,omitempty"`
+
+	// 以下字段只在文件名、分片大小、有效期、目录和API服务器都不变时有效
+	FileName     string `json:"file_name"` // 上传后的文件名（uptoken由其计算）
+	APIServer    string `json:"api_server"`
+	ChunkSize    int    `json:"chunk_size"`
+	Model        int    `json:"model"`
+	MrID         string `json:"mr_id"`
+	UpToken      string `json:"uptoken,omitempty"`
+	UToken       string `json:"utoken,omitempty"`
+	UploadServer string `json:"upload_server,omitempty"`
+	AckedSlices  []int  `json:"acked_slices,omitempty"` // 服务器已确认的分片编号
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// resumeJournal 续传记录文件，所有方法可在nil上调用（记录不可用时上传照常进行）
+type resumeJournal struct {
+	mu    sync.Mutex
+	path  string
+	entry resumeEntry
+	acked map[int]bool
+}
+
+// getResumeJournalDir 获取续传记录目录
+func getResumeJournalDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".tmplink", "resume")
+	}
+	return filepath.Join(homeDir, ".tmplink", "resume")
+}
+
+// resumeJournalKey 根据文件路径、大小和修改时间生成记录文件名
+func resumeJournalKey(absPath string, size int64, modTime time.Time) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s|%d|%d", absPath, size, modTime.UnixNano())))
+	return hex.EncodeToString(sum[:])
+}
+
+// openResumeJournal 打开文件对应的续传记录，不存在时创建新记录（首次保存时写入磁盘）
+// 上传参数与记录不一致时，丢弃记录中的上传会话，只保留SHA1
+func openResumeJournal(config *Config, job uploadJob, fileInfo os.FileInfo) (*resumeJournal, error) {
+	absPath, err := filepath.Abs(job.FilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &resumeJournal{
+		path:  filepath.Join(getResumeJournalDir(), resumeJournalKey(absPath, fileInfo.Size(), fileInfo.ModTime())+".json"),
+		acked: make(map[int]bool),
+	}
+
+	if data, err := os.ReadFile(j.path); err == nil {
+		var entry resumeEntry
+		if json.Unmarshal(data, &entry) == nil && entry.FilePath == absPath &&
+			entry.FileSize == fileInfo.Size() && entry.ModTime.Equal(fileInfo.ModTime()) {
+			j.entry = entry
+		}
+	}
+
+	j.entry.TaskID = job.TaskID
+	j.entry.FilePath = absPath
+	j.entry.FileSize = fileInfo.Size()
+	j.entry.ModTime = fileInfo.ModTime()
+
+	if j.entry.FileName != job.remoteName() || j.entry.APIServer != config.Server ||
+		j.entry.ChunkSize != config.ChunkSize || j.entry.Model != config.Model || j.entry.MrID != config.MrID {
+		j.entry.FileName = job.remoteName()
+		j.entry.APIServer = config.Server
+		j.entry.ChunkSize = config.ChunkSize
+		j.entry.Model = config.Model
+		j.entry.MrID = config.MrID
+		j.entry.UpToken = ""
+		j.entry.UToken = ""
+		j.entry.UploadServer = ""
+		j.entry.AckedSlices = nil
+	}
+	for _, index := range j.entry.AckedSlices {
+		j.acked[index] = true
+	}
+
+	return j, nil
+}
+
+// sha1 返回缓存的文件SHA1
+func (j *resumeJournal) sha1() string {
+	if j == nil {
+		return ""
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.entry.SHA1
+}
+
+// setSHA1 缓存文件SHA1
+func (j *resumeJournal) setSHA1(sha1Hash string) error {
+	return j.update(func(e *resumeEntry) {
+		e.SHA1 = sha1Hash
+	})
+}
+
+// uploadInfo 返回缓存的UToken和上传服务器，没有缓存时返回nil
+func (j *resumeJournal) uploadInfo() *UploadInfo {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.entry.UToken == "" || j.entry.UploadServer == "" {
+		return nil
+	}
+	return &UploadInfo{UToken: j.entry.UToken, Server: j.entry.UploadServer}
+}
+
+// setUploadInfo 缓存UToken和上传服务器，传入nil时清除缓存
+func (j *resumeJournal) setUploadInfo(info *UploadInfo) error {
+	return j.update(func(e *resumeEntry) {
+		if info == nil {
+			e.UToken, e.UploadServer = "", ""
+			return
+		}
+		e.UToken, e.UploadServer = info.UToken, info.Server
+	})
+}
+
+// startSession 记录本次分片上传使用的uptoken，uptoken变化时清除已确认分片
+func (j *resumeJournal) startSession(upToken string) error {
+	return j.update(func(e *resumeEntry) {
+		if e.UpToken != upToken {
+			e.UpToken = upToken
+			e.AckedSlices = nil
+			j.acked = make(map[int]bool)
+		}
+	})
+}
+
+// ackedSlices 返回已确认的分片编号
+func (j *resumeJournal) ackedSlices() []int {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]int(nil), j.entry.AckedSlices...)
+}
+
+// resetSlices 服务器没有已上传分片时（如上传会话已过期）清除本地记录的分片
+func (j *resumeJournal) resetSlices() error {
+	return j.update(func(e *resumeEntry) {
+		e.AckedSlices = nil
+		j.acked = make(map[int]bool)
+	})
+}
+
+// ackSlice 记录服务器已确认的分片
+func (j *resumeJournal) ackSlice(index int) error {
+	return j.update(func(e *resumeEntry) {
+		if j.acked[index] {
+			return
+		}
+		j.acked[index] = true
+		e.AckedSlices = append(e.AckedSlices, index)
+		sort.Ints(e.AckedSlices)
+	})
+}
+
+// remove 上传完成后删除续传记录
+func (j *resumeJournal) remove() error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// update 修改记录并写入磁盘（先写临时文件再重命名，避免中断时留下不完整的记录）
+func (j *resumeJournal) update(fn func(e *resumeEntry)) error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	fn(&j.entry)
+	j.entry.UpdatedAt = time.Now()
+
+	data, err := json.MarshalIndent(j.entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0700); err != nil {
+		return err
+	}
+
+	tmpPath := j.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, j.path)
+}
+
+// loadResumeEntries 读取全部续传记录，返回记录及其文件路径
+func loadResumeEntries() (map[string]resumeEntry, error) {
+	dir := getResumeJournalDir()
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]resumeEntry)
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var entry resumeEntry
+		if json.Unmarshal(data, &entry) != nil {
+			continue
+		}
+		entries[path] = entry
+	}
+	return entries, nil
+}
+
+// findResumeEntry 按任务ID查找续传记录
+func findResumeEntry(taskID string) (*resumeEntry, error) {
+	entries, err := loadResumeEntries()
+	if err != nil {
+		return nil, fmt.Errorf("读取续传记录失败: %w", err)
+	}
+
+	var found *resumeEntry
+	for _, entry := range entries {
+		if entry.TaskID != taskID {
+			continue
+		}
+		// 同一任务ID对应多条记录时（文件在中断后被修改过）使用最新的一条
+		if found == nil || entry.UpdatedAt.After(found.UpdatedAt) {
+			entry := entry
+			found = &entry
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("未找到任务 %s 的续传记录", taskID)
+	}
+	return found, nil
+}
+
+// pruneResumeJournal 删除过期的续传记录，以及对应文件已被修改或删除的记录
+func pruneResumeJournal(maxAge time.Duration) {
+	entries, err := loadResumeEntries()
+	if err != nil {
+		return
+	}
+	for path, entry := range entries {
+		info, err := os.Stat(entry.FilePath)
+		stale := err != nil || info.Size() != entry.FileSize || !info.ModTime().Equal(entry.ModTime)
+		if stale || time.Since(entry.UpdatedAt) > maxAge {
+			os.Remove(path)
+		}
+	}
+}
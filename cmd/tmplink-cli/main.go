@@ -1,22 +1,27 @@
 package main
 
 import (
-	"bytes"
+	"bufio"
 	"context"
 	"crypto/sha1"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
-	"mime/multipart"
-	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"tmplink_uploader/internal/api"
+	"tmplink_uploader/internal/config"
+	"tmplink_uploader/internal/credential"
 	"tmplink_uploader/internal/updater"
 
 	"github.com/schollz/progressbar/v3"
@@ -29,196 +34,157 @@ var (
 	GitCommit string = "unknown"
 )
 
-// 共享配置文件结构（与GUI保持一致）
-type SharedConfig struct {
-	Token              string    `json:"token"`
-	UploadServer       string    `json:"upload_server"`
-	SelectedServerName string    `json:"selected_server_name"`
-	ChunkSize          int       `json:"chunk_size"`
-	MaxConcurrent      int       `json:"max_concurrent"`
-	QuickUpload        bool      `json:"quick_upload"`
-	SkipUpload         bool      `json:"skip_upload"`
-	LastUpdateCheck    time.Time `json:"last_update_check"`
-	// CLI专用字段
-	Model int    `json:"model"`
-	MrID  string `json:"mr_id"`
-}
-
 // 上传配置
 type Config struct {
 	Token        string
-	Server       string
+	Server       string // API服务器地址
+	LinkBase     string // 下载链接前缀
 	UploadServer string // 分片上传服务器
 	ChunkSize    int
 	Model        int
 	MrID         string
 	SkipUpload   int
-	Debug        bool // 调试模式
-}
-
-// getSharedConfigPath 获取共享配置文件路径
-func getSharedConfigPath() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return ".tmplink_config.json"
-	}
-	return filepath.Join(homeDir, ".tmplink_config.json")
+	Concurrency  int             // 并发上传的分片数
+	Retry        api.RetryPolicy // 分片上传和prepare请求的重试策略
+	Debug        bool            // 调试模式
+	API          *api.Client     // API客户端
 }
 
-// loadSharedConfig 加载共享配置
-func loadSharedConfig() SharedConfig {
-	configPath := getSharedConfigPath()
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		// 返回默认值
-		return SharedConfig{
-			ChunkSize:     3,
-			MaxConcurrent: 5,
-			QuickUpload:   true,
-			SkipUpload:    false,
-			Model:         0,
-			MrID:          "0",
-		}
-	}
-
-	var config SharedConfig
-	if err := json.Unmarshal(data, &config); err != nil {
-		// 返回默认值
-		return SharedConfig{
-			ChunkSize:     3,
-			MaxConcurrent: 5,
-			QuickUpload:   true,
-			SkipUpload:    false,
-			Model:         0,
-			MrID:          "0",
-		}
-	}
-
-	// 确保默认值
-	if config.MrID == "" {
-		config.MrID = "0"
-	}
-	if config.ChunkSize <= 0 {
-		config.ChunkSize = 3
-	}
-	if config.MaxConcurrent <= 0 {
-		config.MaxConcurrent = 5
-	}
+// credentialStore 保存API Token的凭据存储
+var credentialStore = credential.Default()
 
-	return config
+// loadSharedConfig 加载共享配置和当前账号的配置，Token从凭据存储读取
+func loadSharedConfig() config.Config {
+	return loadSettings().User
 }
 
-// saveSharedConfig 保存共享配置到文件
-func saveSharedConfig(config SharedConfig) error {
-	configPath := getSharedConfigPath()
-
-	data, err := json.MarshalIndent(config, "", "  ")
+// readSharedConfigFile 读取共享配置文件，不切换账号配置、不读取Token
+// 配置文件无法读取时使用默认配置，旧版本的配置在读取时迁移
+func readSharedConfigFile() config.Config {
+	saved, err := config.Load()
 	if err != nil {
-		return err
-	}
-
-	// 确保目录存在
-	dir := filepath.Dir(configPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
+		fmt.Fprintf(os.Stderr, "警告: %v\n", err)
 	}
-
-	return os.WriteFile(configPath, data, 0644)
+	return saved
 }
 
 // 兼容性函数：用于加载token
 func loadSavedToken() string {
-	config := loadSharedConfig()
-	return config.Token
+	saved := loadSharedConfig()
+	return saved.Token
 }
 
 // 兼容性函数：用于保存token
 func saveToken(token string) error {
-	config := loadSharedConfig()
-	config.Token = token
-	return saveSharedConfig(config)
+	saved := loadSharedConfig()
+	saved.Token = token
+	return saved.Save()
 }
 
-// debugPrint 调试输出函数
+// debugPrint 调试输出函数，输出前遮盖Token等凭据
 func debugPrint(config *Config, format string, args ...interface{}) {
 	if config.Debug {
 		logMsg := fmt.Sprintf("[DEBUG] "+format+"\n", args...)
-		fmt.Print(logMsg)
+		logMsg = credential.RedactFields(logMsg)
+		// 其他位置出现的Token也一并遮盖（过短的Token可能与普通文本重复，只按字段遮盖）
+		if len(config.Token) >= 8 {
+			logMsg = strings.ReplaceAll(logMsg, config.Token, credential.Redact(config.Token))
+		}
+		fmt.Fprint(humanOut, logMsg)
 
 		// 同时写入日志文件
-		if logFile, err := os.OpenFile("api_requests.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err == nil {
+		if logFile, err := os.OpenFile("api_requests.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600); err == nil {
 			logFile.WriteString(fmt.Sprintf("[%s] %s", time.Now().Format("2006-01-02 15:04:05"), logMsg))
 			logFile.Close()
 		}
 	}
 }
 
+// logf 输出带时间的日志，用于 watch、serve 等常驻运行的子命令
+func logf(format string, args ...interface{}) {
+	fmt.Fprintf(humanOut, "%s %s\n", time.Now().Format("2006-01-02 15:04:05"), fmt.Sprintf(format, args...))
+}
+
 // 任务状态
 type TaskStatus struct {
-	ID          string    `json:"id"`
-	Status      string    `json:"status"`
-	FilePath    string    `json:"file_path"`
-	FileName    string    `json:"file_name"`
-	FileSize    int64     `json:"file_size"`
-	Progress    float64   `json:"progress"`
-	UploadSpeed float64   `json:"upload_speed,omitempty"` // KB/s
-	ServerName  string    `json:"server_name,omitempty"`  // 上传服务器名称
-	ProcessID   int       `json:"process_id,omitempty"`   // CLI进程号
-	DownloadURL string    `json:"download_url,omitempty"`
-	ErrorMsg    string    `json:"error_msg,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID           string    `json:"id"`
+	Status       string    `json:"status"` // pending/hashing/uploading/completed/failed/cancelled
+	FilePath     string    `json:"file_path"`
+	FileName     string    `json:"file_name"`
+	FileSize     int64     `json:"file_size"`
+	HashProgress float64   `json:"hash_progress"` // SHA1计算进度（0-100）
+	Progress     float64   `json:"progress"`
+	UploadSpeed  float64   `json:"upload_speed,omitempty"` // KB/s
+	ServerName   string    `json:"server_name,omitempty"`  // 上传服务器名称
+	ProcessID    int       `json:"process_id,omitempty"`   // CLI进程号
+	Model        int       `json:"model"`                  // 文件有效期
+	MrID         string    `json:"mr_id,omitempty"`        // 目标文件夹的目录ID
+	DownloadURL  string    `json:"download_url,omitempty"`
+	ErrorMsg     string    `json:"error_msg,omitempty"`
+	ErrorType    string    `json:"error_type,omitempty"` // 失败类别: auth/quota/destination/file/network/server
+	ErrorCode    int       `json:"error_code,omitempty"` // 服务器返回的错误代码或状态码
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 // 上传结果
 type UploadResult struct {
-	DownloadURL string
-	FileID      string
+	DownloadURL   string
+	FileID        string
+	SHA1          string // 文件SHA1，计算完成后即使上传失败也会返回
+	InstantUpload bool   // 是否秒传
 }
 
 // 速度计算器
 type SpeedCalculator struct {
 	startTime    time.Time
-	lastTime     time.Time
-	lastBytes    int64
 	totalBytes   int64
-	currentSpeed float64 // KB/s
+	samples      []speedSample // 最近一个统计窗口内的进度采样
+	currentSpeed float64       // KB/s
+}
+
+// speedSample 某一时刻的累计上传字节数
+type speedSample struct {
+	at    time.Time
+	bytes int64
 }
 
+// speedWindow 计算实时速度的滑动窗口长度
+const speedWindow = 3 * time.Second
+
 // NewSpeedCalculator 创建新的速度计算器
 func NewSpeedCalculator(totalBytes int64) *SpeedCalculator {
-	now := time.Now()
 	return &SpeedCalculator{
-		startTime:    now,
-		lastTime:     now,
-		lastBytes:    0,
+		startTime:    time.Now(),
 		totalBytes:   totalBytes,
 		currentSpeed: 0,
 	}
 }
 
 // UpdateSpeed 更新上传速度
+// 进度按字节连续回调，速度取滑动窗口内的平均值；没有新数据时速度随窗口滑动逐渐归零
 func (sc *SpeedCalculator) UpdateSpeed(uploadedBytes int64) float64 {
 	now := time.Now()
-	timeDiff := now.Sub(sc.lastTime).Seconds()
 
-	// 降低时间间隔要求，对小文件更友好（0.5秒而不是1秒）
-	if timeDiff >= 0.5 {
-		bytesDiff := uploadedBytes - sc.lastBytes
-		if bytesDiff > 0 && timeDiff > 0 {
-			// 计算瞬时速度 (KB/s)
-			instantSpeed := float64(bytesDiff) / 1024.0 / timeDiff
-
-			// 使用加权平均平滑速度波动
-			if sc.currentSpeed == 0 {
-				sc.currentSpeed = instantSpeed
-			} else {
-				sc.currentSpeed = sc.currentSpeed*0.7 + instantSpeed*0.3
-			}
-		}
+	// 进度回退（分片失败后重新发送）时丢弃旧采样，避免算出负速度
+	if n := len(sc.samples); n > 0 && uploadedBytes < sc.samples[n-1].bytes {
+		sc.samples = sc.samples[:0]
+	}
+	sc.samples = append(sc.samples, speedSample{at: now, bytes: uploadedBytes})
 
-		sc.lastTime = now
-		sc.lastBytes = uploadedBytes
+	// 丢弃窗口外的采样，但保留一个窗口起点
+	cutoff := now.Add(-speedWindow)
+	drop := 0
+	for drop < len(sc.samples)-1 && sc.samples[drop+1].at.Before(cutoff) {
+		drop++
+	}
+	sc.samples = sc.samples[drop:]
+
+	first := sc.samples[0]
+	timeDiff := now.Sub(first.at).Seconds()
+	// 降低时间间隔要求，对小文件更友好（0.5秒）
+	if timeDiff >= 0.5 {
+		sc.currentSpeed = float64(uploadedBytes-first.bytes) / 1024.0 / timeDiff
 	}
 
 	return sc.currentSpeed
@@ -283,29 +249,66 @@ func getProgressBarWidth() int {
 }
 
 func main() {
+	// 子命令：watch 监视目录并自动上传，serve 在本机提供上传队列接口
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "watch":
+			os.Exit(runWatch(os.Args[2:]))
+		case "serve":
+			os.Exit(runServe(os.Args[2:]))
+		}
+	}
+
 	// 定义命令行参数
 	var (
-		filePath     = flag.String("file", "", "要上传的文件路径 (必需)")
-		token        = flag.String("token", "", "TmpLink API token (可选，优先使用已保存的token)")
-		setToken     = flag.String("set-token", "", "设置并保存API token")
-		setModel     = flag.Int("set-model", -1, "设置并保存默认文件有效期 (0=24小时, 1=3天, 2=7天, 99=无限期)")
-		setMrID      = flag.String("set-mr-id", "", "设置并保存默认目录ID")
-		uploadServer = flag.String("upload-server", "", "强制指定上传服务器地址 (可选，留空自动选择)")
-		serverName   = flag.String("server-name", "", "上传服务器名称 (用于显示)")
-		chunkSizeMB  = flag.Int("chunk-size", 3, "分块大小(MB, 1-99)")
-		statusFile   = flag.String("status-file", "", "任务状态文件路径 (可选，自动生成)")
-		taskID       = flag.String("task-id", "", "任务ID (可选，自动生成)")
-		model        = flag.Int("model", 0, "文件有效期 (0=24小时, 1=3天, 2=7天, 99=无限期)")
-		mrID         = flag.String("mr-id", "0", "目录ID (默认0=根目录)")
-		skipUpload   = flag.Int("skip-upload", 1, "跳过上传标志 (1=检查秒传)")
-		debugMode    = flag.Bool("debug", false, "调试模式，输出详细运行信息")
-		showStatus   = flag.Bool("status", false, "显示当前配置状态和token有效性")
-		checkUpdate  = flag.Bool("check-update", false, "检查是否有新版本可用")
-		autoUpdate   = flag.Bool("auto-update", false, "自动检查并下载更新")
-		showVersion  = flag.Bool("version", false, "显示当前版本号")
+		setToken      = flag.String("set-token", "", "设置并保存API token，为 - 时从标准输入读取")
+		setModel      = flag.Int("set-model", -1, "设置并保存默认文件有效期 (0=24小时, 1=3天, 2=7天, 99=无限期)")
+		setMrID       = flag.String("set-mr-id", "", "设置并保存默认目录ID")
+		setChunkSize  = flag.Int("set-chunk-size", 0, "设置并保存默认分块大小(MB, 1-99)")
+		setServer     = flag.String("set-upload-server", "", "设置并保存默认上传服务器地址，为 auto 时恢复自动选择")
+		addProfileArg = flag.String("add-profile", "", "添加账号配置，可同时使用 -set-token 等参数设置该账号")
+		useProfileArg = flag.String("use-profile", "", "设置之后默认使用的账号配置 (default 为默认账号)")
+		removeProfArg = flag.String("remove-profile", "", "删除账号配置及其保存的Token")
+		listProfArg   = flag.Bool("list-profiles", false, "列出所有账号配置")
+		uploadServer  = flag.String("upload-server", "", "强制指定上传服务器地址 (可选，默认使用已保存的值，均未设置时自动选择)")
+		serverName    = flag.String("server-name", "", "上传服务器名称 (用于显示)")
+		chunkSizeMB   = flag.Int("chunk-size", 3, "分块大小(MB, 1-99，默认使用已保存的值)")
+		statusFile    = flag.String("status-file", "", "任务状态文件路径 (可选，自动生成)")
+		taskID        = flag.String("task-id", "", "任务ID (可选，自动生成)")
+		folder        = flag.String("folder", "", "按路径指定目标文件夹，如 /Projects/2026 (不能与 -mr-id 同时使用)")
+		createFolder  = flag.Bool("create-folder", false, "-folder 指定的文件夹不存在时自动创建")
+		listFolders   = flag.Bool("list-folders", false, "列出文件夹及其目录ID (可用 -folder 指定上级文件夹)")
+		skipUpload    = flag.Int("skip-upload", 1, "跳过上传标志 (1=检查秒传)")
+		debugMode     = flag.Bool("debug", false, "调试模式，输出详细运行信息")
+		showStatus    = flag.Bool("status", false, "显示当前配置状态和token有效性")
+		checkUpdate   = flag.Bool("check-update", false, "检查是否有新版本可用")
+		autoUpdate    = flag.Bool("auto-update", false, "自动检查并下载更新")
+		showVersion   = flag.Bool("version", false, "显示当前版本号")
+		recursive     = flag.Bool("recursive", false, "递归上传目录中的所有文件")
+		output        = flag.String("output", outputText, "输出格式: text (默认), json (结束时向stdout输出JSON结果), ndjson (逐行输出JSON事件和进度)")
+		retries       = flag.Int("retries", api.DefaultRetries, "单个请求失败后的最大重试次数 (网络错误、HTTP 5xx/429，0=不重试)")
+		retryMaxWait  = flag.Duration("retry-max-wait", api.DefaultRetryMaxWait, "两次重试之间的最长等待时间，0表示立即重试")
+		retryDeadline = flag.Duration("retry-deadline", api.DefaultRetryDeadline, "单个请求（含全部重试）的最长耗时，0=不限制")
+		resume        = flag.String("resume", "", "按任务ID继续之前中断的上传")
+		remoteName    = flag.String("name", "", "上传后的文件名 (默认使用本地文件名，从标准输入上传时必需)")
+		manifest      = flag.String("manifest", "", "上传清单文件 (.json/.yaml/.yml/.csv)，重新运行时跳过已完成的文件")
+		resultsPath   = flag.String("results", "", "清单上传的结果文件路径 (默认为清单同目录的 <清单名>.results.json)")
+		parallel      = flag.Int("parallel", 1, "同时上传的文件数 (1-10)")
 	)
 
+	var filePaths stringList
+	flag.Var(&filePaths, "file", "要上传的文件路径 (必需，可重复指定，也可作为位置参数传入；- 表示标准输入)")
+	// 以下参数按 命令行参数 > TMPLINK_* 环境变量 > 项目配置 > 用户配置 > 默认值 确定，见 resolveUploadSettings
+	flag.String("profile", "", "使用的账号配置 (默认: TMPLINK_PROFILE 环境变量、项目配置或 -use-profile 保存的账号)")
+	flag.String("token", "", "TmpLink API token (可选，优先使用 TMPLINK_TOKEN 环境变量或已保存的token)")
+	flag.Int("concurrency", 5, "并发上传分片数 (1-20，默认使用 TMPLINK_CONCURRENCY、项目配置或已保存的max_concurrent)")
+	flag.Int("model", 0, "文件有效期 (0=24小时, 1=3天, 2=7天, 99=无限期)")
+	flag.String("mr-id", "0", "目录ID (默认0=根目录)")
+	flag.String("api-server", "", "API服务器地址 (可选，也可通过TMPLINK_API环境变量或项目配置设置)")
+	flag.String("link-base", "", "下载链接前缀 (可选，也可通过TMPLINK_LINK_BASE环境变量或项目配置设置)")
+
 	flag.Parse()
+	cliFlags = config.ExplicitFlags(flag.CommandLine)
 
 	// 处理版本相关的情况
 	if *showVersion {
@@ -341,22 +344,77 @@ func main() {
 		return
 	}
 
+	// 账号配置管理
+	settingChanged := *setToken != "" || *setModel >= 0 || *setMrID != "" || *setChunkSize != 0 || *setServer != ""
+	if *listProfArg {
+		if err := listProfiles(); err != nil {
+			fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *useProfileArg != "" {
+		if err := switchProfile(*useProfileArg); err != nil {
+			fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("之后默认使用账号配置: %s\n", *useProfileArg)
+		return
+	}
+	if *removeProfArg != "" {
+		if err := removeProfile(*removeProfArg); err != nil {
+			fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("已删除账号配置: %s\n", *removeProfArg)
+		return
+	}
+	if *addProfileArg != "" {
+		if err := addProfile(*addProfileArg); err != nil {
+			fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("已添加账号配置: %s\n", *addProfileArg)
+		// 同时指定的 -set-token 等参数保存到新账号
+		cliFlags[config.SettingProfile.Flag] = *addProfileArg
+		if *setToken == "" {
+			fmt.Printf("使用 -profile %s -set-token YOUR_TOKEN 设置该账号的Token\n", *addProfileArg)
+		}
+		if !settingChanged {
+			return
+		}
+	}
+
 	// 处理设置参数的情况
-	if *setToken != "" || *setModel >= 0 || *setMrID != "" {
-		config := loadSharedConfig()
+	if settingChanged {
+		settings := loadSettings()
+		saved := settings.User
+		if saved.ActiveProfile() != "" {
+			fmt.Printf("账号配置: %s\n", saved.ActiveProfile())
+		}
 		updated := false
 
 		if *setToken != "" {
+			newToken := *setToken
+			if newToken == "-" {
+				// 从标准输入读取，避免Token出现在命令行参数和shell历史中
+				line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+				if newToken = strings.TrimSpace(line); newToken == "" {
+					fmt.Fprintln(os.Stderr, "错误: 未从标准输入读取到Token")
+					os.Exit(1)
+				}
+			}
+
 			// 验证Token有效性
 			fmt.Print("正在验证Token有效性...")
-			server := "https://tmplink-sec.vxtrans.com/api_v2"
-			if uid, err := validateTokenAndGetUID(*setToken, server); err != nil {
+			server := settings.Resolve(config.SettingAPIServer, api.DefaultBaseURL).Value
+			if uid, err := validateTokenAndGetUID(context.Background(), newToken, server); err != nil {
 				fmt.Printf("\n错误: Token验证失败: %v\n", err)
 				fmt.Println("请确保Token正确且有效")
 				os.Exit(1)
 			} else {
 				fmt.Printf(" ✅\n")
-				config.Token = *setToken
+				saved.Token = newToken
 				fmt.Printf("Token已成功保存并验证 (UID: %s)\n", uid)
 				updated = true
 			}
@@ -364,7 +422,7 @@ func main() {
 
 		if *setModel >= 0 {
 			if *setModel == 0 || *setModel == 1 || *setModel == 2 || *setModel == 99 {
-				config.Model = *setModel
+				saved.Model = *setModel
 				modelDesc := map[int]string{0: "24小时", 1: "3天", 2: "7天", 99: "无限期"}
 				fmt.Printf("默认文件有效期已设置为: %s\n", modelDesc[*setModel])
 				updated = true
@@ -375,13 +433,36 @@ func main() {
 		}
 
 		if *setMrID != "" {
-			config.MrID = *setMrID
+			saved.MrID = *setMrID
 			fmt.Printf("默认目录ID已设置为: %s\n", *setMrID)
 			updated = true
 		}
 
+		if *setChunkSize != 0 {
+			if *setChunkSize < 1 || *setChunkSize > 99 {
+				fmt.Fprintf(os.Stderr, "错误: 分块大小必须在1-99MB之间，当前值: %dMB\n", *setChunkSize)
+				os.Exit(1)
+			}
+			saved.ChunkSize = *setChunkSize
+			fmt.Printf("默认分块大小已设置为: %dMB\n", *setChunkSize)
+			updated = true
+		}
+
+		if *setServer != "" {
+			if *setServer == "auto" {
+				saved.UploadServer = ""
+				saved.SelectedServerName = ""
+				fmt.Println("上传服务器已设置为: 自动选择")
+			} else {
+				saved.UploadServer = *setServer
+				saved.SelectedServerName = *serverName
+				fmt.Printf("默认上传服务器已设置为: %s\n", *setServer)
+			}
+			updated = true
+		}
+
 		if updated {
-			if err := saveSharedConfig(config); err != nil {
+			if err := saved.Save(); err != nil {
 				fmt.Fprintf(os.Stderr, "错误: 保存配置失败: %v\n", err)
 				os.Exit(1)
 			}
@@ -396,42 +477,85 @@ func main() {
 		return
 	}
 
-	// 加载保存的配置作为默认值
-	savedConfig := loadSharedConfig()
-
-	// 参数优先级处理: 命令行参数 > 保存的配置 > 默认值
-	finalToken := *token
-	if finalToken == "" {
-		finalToken = savedConfig.Token
+	// 参数优先级: 命令行参数 > TMPLINK_* 环境变量 > 项目配置 .tmplink.json > 用户配置 > 默认值
+	resolved, err := resolveUploadSettings(loadSettings())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		os.Exit(exitFailure)
+	}
+	finalToken := resolved.token
+	finalModel := resolved.model
+	finalMrID := resolved.mrID
+	finalConcurrency := resolved.concurrency
+	*chunkSizeMB = resolved.chunkSizeMB
+	*uploadServer = resolved.uploadServer
+	*serverName = resolved.serverName
+
+	// 汇总待上传路径：重复的 -file 参数 + 位置参数
+	inputPaths := append([]string{}, filePaths...)
+	inputPaths = append(inputPaths, flag.Args()...)
+
+	// 验证输出格式，JSON模式下面向用户的文本改为输出到stderr
+	out := newReporter(*output)
+	if *output != outputText && !out.machine() {
+		fmt.Fprintf(os.Stderr, "错误: 不支持的输出格式: %s (可选: text, json, ndjson)\n", *output)
+		os.Exit(exitFailure)
+	}
+	if out.machine() {
+		humanOut = os.Stderr
+	}
+
+	// 清理过期的续传记录，并按任务ID找回中断的上传
+	pruneResumeJournal(resumeJournalMaxAge)
+	if *resume != "" {
+		if len(inputPaths) > 0 {
+			fmt.Fprintf(os.Stderr, "错误: -resume 不能与 -file 同时使用\n")
+			os.Exit(exitFailure)
+		}
+		entry, err := findResumeEntry(*resume)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+			os.Exit(exitFailure)
+		}
+		inputPaths = []string{entry.FilePath}
+		// 沿用中断时的上传文件名
+		if *remoteName == "" {
+			*remoteName = entry.FileName
+		}
 	}
 
-	// 检查model参数是否被显式设置
-	modelFlag := flag.Lookup("model")
-	finalModel := *model
-	if !isFlagSet(modelFlag) {
-		// 如果用户没有指定model参数，使用保存的配置
-		finalModel = savedConfig.Model
+	// 列出文件夹
+	if *listFolders {
+		if finalToken == "" {
+			fmt.Fprintf(os.Stderr, "错误: 未找到token，请使用 -token 参数或先用 -set-token 保存token\n")
+			os.Exit(exitFailure)
+		}
+		config := &Config{Token: finalToken, Server: resolved.apiServer, Retry: api.DefaultRetryPolicy(), Debug: *debugMode}
+		config.API = newAPIClient(config)
+		if err := printFolders(context.Background(), config, *folder); err != nil {
+			fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+			kind, _ := classifyError(err)
+			os.Exit(kind.exitCode())
+		}
+		return
 	}
 
-	// 检查mr-id参数是否被显式设置
-	mrIDFlag := flag.Lookup("mr-id")
-	finalMrID := *mrID
-	if !isFlagSet(mrIDFlag) {
-		// 如果用户没有指定mr-id参数，使用保存的配置
-		finalMrID = savedConfig.MrID
+	if *manifest != "" && (len(inputPaths) > 0 || *resume != "" || *remoteName != "") {
+		fmt.Fprintf(os.Stderr, "错误: -manifest 不能与 -file、-resume 或 -name 同时使用\n")
+		os.Exit(exitFailure)
 	}
 
 	// 验证必需参数
-	if *filePath == "" {
+	if len(inputPaths) == 0 && *manifest == "" {
 		fmt.Fprintf(os.Stderr, "错误: 缺少必需参数 -file\n")
 		flag.Usage()
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 
 	if finalToken == "" {
 		fmt.Fprintf(os.Stderr, "错误: 未找到token，请使用 -token 参数或先用 -set-token 保存token\n")
 		flag.Usage()
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 
 	// 检测是否为CLI模式（用户未提供task-id）
@@ -444,9 +568,19 @@ func main() {
 	// 确定是否应该保存状态文件：GUI模式 或 用户显式提供了 -status-file
 	shouldSaveStatus := !cliMode || statusFileExplicit
 
-	// 自动生成task-id (如果未提供)
+	opts := runOptions{
+		cliMode:          cliMode,
+		progressBars:     cliMode && *parallel == 1, // 多个文件同时上传时不显示单个文件的进度条
+		shouldSaveStatus: shouldSaveStatus,
+		serverName:       *serverName,
+	}
+
+	// 自动生成task-id (如果未提供)，续传时沿用原任务ID
 	if cliMode {
 		*taskID = fmt.Sprintf("upload_%d", time.Now().Unix())
+		if *resume != "" {
+			*taskID = *resume
+		}
 	}
 
 	// 自动生成status-file (如果未提供)
@@ -457,53 +591,25 @@ func main() {
 	// 验证分块大小
 	if *chunkSizeMB < 1 || *chunkSizeMB > 99 {
 		fmt.Fprintf(os.Stderr, "错误: 分块大小必须在1-99MB之间，当前值: %dMB\n", *chunkSizeMB)
-		os.Exit(1)
-	}
-
-	// 验证文件存在
-	if _, err := os.Stat(*filePath); os.IsNotExist(err) {
-		fmt.Fprintf(os.Stderr, "错误: 文件不存在: %s\n", *filePath)
-		os.Exit(1)
-	}
-
-	// 启动时检查更新（后台进行，不阻塞用户操作）
-	updater.CheckUpdateOnStartup("cli", Version, os.Args)
-
-	// 获取文件信息
-	fileInfo, err := os.Stat(*filePath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "错误: 获取文件信息失败: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 
-	// 验证文件大小限制 (50GB)
-	const maxFileSize = 50 * 1024 * 1024 * 1024 // 50GB
-	if fileInfo.Size() > maxFileSize {
-		fmt.Fprintf(os.Stderr, "错误: 文件大小超出限制，最大支持50GB，当前文件: %.2fGB\n",
-			float64(fileInfo.Size())/(1024*1024*1024))
-		os.Exit(1)
+	// 验证并发数
+	if finalConcurrency < 1 || finalConcurrency > 20 {
+		fmt.Fprintf(os.Stderr, "错误: 并发数必须在1-20之间，当前值: %d\n", finalConcurrency)
+		os.Exit(exitFailure)
 	}
 
-	// 初始化任务状态
-	task := &TaskStatus{
-		ID:         *taskID,
-		Status:     "pending",
-		FilePath:   *filePath,
-		FileName:   filepath.Base(*filePath),
-		FileSize:   fileInfo.Size(),
-		Progress:   0.0,
-		ServerName: *serverName,
-		ProcessID:  os.Getpid(), // 记录当前进程号
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
+	// 验证同时上传的文件数
+	if *parallel < 1 || *parallel > 10 {
+		fmt.Fprintf(os.Stderr, "错误: 同时上传的文件数必须在1-10之间，当前值: %d\n", *parallel)
+		os.Exit(exitFailure)
 	}
 
-	// 只有当需要保存状态时才保存初始状态到文件
-	if shouldSaveStatus {
-		if err := saveTaskStatus(*statusFile, task); err != nil {
-			fmt.Fprintf(os.Stderr, "错误: 保存任务状态失败: %v\n", err)
-			os.Exit(1)
-		}
+	// 验证重试策略
+	if *retries < 0 || *retryMaxWait < 0 || *retryDeadline < 0 {
+		fmt.Fprintf(os.Stderr, "错误: 重试次数和等待时间不能为负数\n")
+		os.Exit(exitFailure)
 	}
 
 	// 转换分块大小从MB到字节
@@ -511,1400 +617,1993 @@ func main() {
 
 	// 创建上传配置
 	config := &Config{
-		Token:        finalToken,                               // 使用最终确定的token
-		Server:       "https://tmplink-sec.vxtrans.com/api_v2", // 固定API服务器地址
-		UploadServer: *uploadServer,                            // 用户指定的上传服务器
+		Token:        finalToken,         // 使用最终确定的token
+		Server:       resolved.apiServer, // API服务器地址
+		LinkBase:     resolved.linkBase,  // 下载链接前缀
+		UploadServer: *uploadServer,      // 用户指定的上传服务器
 		ChunkSize:    chunkSizeBytes,
 		Model:        finalModel, // 使用最终确定的model
 		MrID:         finalMrID,  // 使用最终确定的mrID
 		SkipUpload:   *skipUpload,
-		Debug:        *debugMode,
+		Concurrency:  finalConcurrency,
+		Retry: api.RetryPolicy{
+			MaxRetries: *retries,
+			MaxWait:    *retryMaxWait,
+			Deadline:   *retryDeadline,
+		},
+		Debug: *debugMode,
 	}
 
-	debugPrint(config, "启动CLI上传程序")
-	debugPrint(config, "文件路径: %s", *filePath)
-	debugPrint(config, "分片大小: %d bytes (%dMB)", chunkSizeBytes, *chunkSizeMB)
-	debugPrint(config, "API服务器: %s", config.Server)
-
-	// 创建速度计算器
-	speedCalc := NewSpeedCalculator(fileInfo.Size())
-
-	// 设置进度回调
-	progressCallback := createProgressCallback(cliMode, shouldSaveStatus, fileInfo.Size(), speedCalc, task, *statusFile)
+	config.API = newAPIClient(config)
 
-	// 验证Token有效性
-	debugPrint(config, "验证Token有效性...")
-	if _, err := validateTokenAndGetUID(finalToken, config.Server); err != nil {
-		task.Status = "failed"
-		task.ErrorMsg = fmt.Sprintf("Token验证失败: %v", err)
-		task.UpdatedAt = time.Now()
+	// 目标文件夹 (-folder、TMPLINK_FOLDER 或项目配置) 按路径确定目录ID，清单中单独指定了目录ID的条目除外
+	if resolved.folder != "" {
+		folderID, err := resolveFolder(context.Background(), config, resolved.folder, *createFolder)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "错误: 查找目标文件夹失败: %v\n", err)
+			kind, _ := classifyError(err)
+			os.Exit(kind.exitCode())
+		}
+		finalMrID = folderID
+		config.MrID = folderID
+		fmt.Fprintf(humanOut, "📁 目标文件夹: %s (目录ID: %s)\n", api.JoinFolderPath(api.SplitFolderPath(resolved.folder)), folderID)
+	}
 
-		// CLI模式：显示失败信息
-		if cliMode {
-			fmt.Printf("❌ Token验证失败!\n")
-			fmt.Printf("❗ 错误信息: %v\n", err)
-			fmt.Println("💡 请使用 -set-token 命令重新设置有效的API Token")
-		} else {
-			fmt.Fprintf(os.Stderr, "Token验证失败: %v\n", err)
+	// 生成上传任务：上传清单，或 -file 和位置参数指定的文件
+	var (
+		jobs    []uploadJob
+		results *manifestResults
+		skipped int
+	)
+	if *manifest != "" {
+		if *resultsPath == "" {
+			*resultsPath = defaultResultsPath(*manifest)
 		}
-		// 保存失败状态到文件
-		if shouldSaveStatus {
-			if saveErr := saveTaskStatus(*statusFile, task); saveErr != nil {
-				fmt.Fprintf(os.Stderr, "错误: 保存失败状态失败: %v\n", saveErr)
-			}
+		jobs, results, skipped, err = loadManifestJobs(*manifest, *resultsPath, finalModel, finalMrID, *taskID, *statusFile)
+		if err == nil {
+			err = results.save()
 		}
-		os.Exit(1)
+	} else {
+		jobs, err = buildUploadJobs(inputPaths, *recursive, *remoteName, *taskID, *statusFile)
 	}
-	debugPrint(config, "Token验证成功")
-
-	// 开始上传
-	task.Status = "uploading"
-	task.UpdatedAt = time.Now()
-	// 保存上传中状态到文件
-	if shouldSaveStatus {
-		saveTaskStatus(*statusFile, task)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		os.Exit(exitFailure)
 	}
 
-	ctx := context.Background()
+	// 启动时检查更新（后台进行，不阻塞用户操作）
+	// JSON输出模式通常用于脚本，不进行可能重启程序的自动更新
+	if !out.machine() {
+		updater.CheckUpdateOnStartup("cli", Version, os.Args)
+	}
 
-	result, err := uploadFile(ctx, config, *filePath, progressCallback)
-	if err != nil {
-		// 上传失败
-		task.Status = "failed"
-		task.ErrorMsg = err.Error()
-		task.UpdatedAt = time.Now()
+	if results != nil {
+		fmt.Fprintf(humanOut, "📋 上传清单: %s, 共 %d 个文件, 已完成跳过 %d 个\n", *manifest, len(results.Results), skipped)
+		if len(jobs) == 0 {
+			fmt.Fprintf(humanOut, "✅ 清单中的文件均已上传，结果文件: %s\n", *resultsPath)
+			out.finish()
+			return
+		}
+	}
 
+	debugPrint(config, "启动CLI上传程序")
+	debugPrint(config, "待上传文件数: %d", len(jobs))
+	debugPrint(config, "分片大小: %d bytes (%dMB)", chunkSizeBytes, *chunkSizeMB)
+	debugPrint(config, "并发分片数: %d", config.Concurrency)
+	debugPrint(config, "重试策略: 最多%d次, 最长等待%v, 总时限%v", config.Retry.MaxRetries, config.Retry.MaxWait, config.Retry.Deadline)
+	debugPrint(config, "API服务器: %s", config.Server)
+	debugPrint(config, "下载链接前缀: %s", config.LinkBase)
+
+	// 收到 SIGINT/SIGTERM 时取消上传，所有等待和HTTP请求随上下文一起结束
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		// 恢复默认信号处理，再次按 Ctrl+C 可立即退出
+		stop()
+	}()
+
+	// 验证Token有效性（整批只验证一次）
+	debugPrint(config, "验证Token有效性...")
+	if _, err := validateTokenAndGetUID(ctx, finalToken, config.Server); err != nil {
+		if ctx.Err() != nil {
+			exitCancelled(jobs, opts, out)
+		}
 		// CLI模式：显示失败信息
 		if cliMode {
-			clearProgressBar() // 清除进度条残留
-			fmt.Printf("❌ 上传失败!\n")
-			fmt.Printf("📁 文件名: %s\n", task.FileName)
-			fmt.Printf("❗ 错误信息: %v\n", err)
+			fmt.Fprintf(humanOut, "❌ Token验证失败!\n")
+			fmt.Fprintf(humanOut, "❗ 错误信息: %v\n", err)
+			fmt.Fprintln(humanOut, "💡 请使用 -set-token 命令重新设置有效的API Token")
 		} else {
-			// GUI模式下仍然输出到stderr，供调试使用
-			fmt.Fprintf(os.Stderr, "上传失败: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Token验证失败: %v\n", err)
 		}
 		// 保存失败状态到文件
-		if shouldSaveStatus {
-			if saveErr := saveTaskStatus(*statusFile, task); saveErr != nil {
-				fmt.Fprintf(os.Stderr, "错误: 保存失败状态失败: %v\n", saveErr)
+		for _, job := range jobs {
+			task := newTaskStatus(job, opts.serverName)
+			task.Status = "failed"
+			if job.err != nil {
+				setTaskError(task, job.err)
+			} else {
+				setTaskError(task, fmt.Errorf("Token验证失败: %w", err))
+			}
+			if opts.shouldSaveStatus {
+				if saveErr := saveTaskStatus(job.StatusFile, task); saveErr != nil {
+					fmt.Fprintf(os.Stderr, "错误: 保存失败状态失败: %v\n", saveErr)
+				}
+			}
+			report := newFileReport(task, nil, 0)
+			out.result(report)
+			if results != nil {
+				results.record(job, report)
 			}
 		}
+		out.finish()
+		kind, _ := classifyError(err)
+		os.Exit(kind.exitCode())
+	}
+	debugPrint(config, "Token验证成功")
 
-		os.Exit(1)
+	// 上传文件，上传清单时每个文件结束后立即写入结果文件
+	var onDone func(job uploadJob, task *TaskStatus, report FileReport)
+	if results != nil {
+		onDone = func(job uploadJob, task *TaskStatus, report FileReport) {
+			if err := results.record(job, report); err != nil {
+				fmt.Fprintf(os.Stderr, "错误: 写入结果文件失败: %v\n", err)
+			}
+		}
+	}
+	tasks := runJobs(ctx, config, jobs, *parallel, opts, out, onDone)
+	failedCount := 0
+	for _, task := range tasks {
+		if task.Status == "failed" {
+			failedCount++
+		}
 	}
 
-	// 上传成功
-	task.Status = "completed"
-	task.Progress = 100.0
-	task.UpdatedAt = time.Now()
-	task.DownloadURL = result.DownloadURL
-	// 计算最终速度（确保小文件也有速度显示）
-	task.UploadSpeed = speedCalc.GetFinalSpeed()
+	// CLI模式：多文件时显示汇总
+	if cliMode && len(tasks) > 1 {
+		printBatchSummary(tasks)
+	}
+	if results != nil {
+		fmt.Fprintf(humanOut, "📝 结果文件: %s\n", *resultsPath)
+	}
+	out.finish()
 
-	// CLI模式：显示完成信息
-	if cliMode {
-		clearProgressBar() // 清除进度条残留
-		fmt.Printf("✅ 上传完成!\n")
-		fmt.Printf("📁 文件名: %s\n", task.FileName)
-		fmt.Printf("📊 文件大小: %s\n", formatBytes(fileInfo.Size()))
-		fmt.Printf("⚡ 平均速度: %.2f MB/s\n", task.UploadSpeed/1024) // 转换为MB/s
-		duration := time.Since(speedCalc.startTime)
-		fmt.Printf("⏱️  总耗时: %v\n", duration.Round(time.Second))
-		fmt.Printf("🔗 下载链接: %s\n", result.DownloadURL)
+	switch {
+	case ctx.Err() != nil:
+		os.Exit(exitCancel)
+	case failedCount == 0:
+		return
+	case failedCount < len(tasks):
+		os.Exit(exitPartialFailure)
+	default:
+		os.Exit(failureExitCode(tasks))
 	}
-	// 保存完成状态到文件
-	if shouldSaveStatus {
-		if err := saveTaskStatus(*statusFile, task); err != nil {
-			fmt.Fprintf(os.Stderr, "警告: 保存完成状态失败: %v\n", err)
+}
+
+// 退出码
+const (
+	exitOK             = 0   // 全部上传成功
+	exitFailure        = 1   // 参数错误或无法归类的失败
+	exitPartialFailure = 3   // 批量上传中部分文件失败（2 为 flag 包的参数解析错误）
+	exitAuth           = 10  // Token无效、过期或权限不足
+	exitQuota          = 11  // 超出上传量或存储空间限制
+	exitDestination    = 12  // 目标文件夹不存在或没有上传权限
+	exitFile           = 13  // 文件被服务器拒绝（空文件、过大、文件名不合法）
+	exitNetwork        = 14  // 网络错误，重试后仍然失败
+	exitServer         = 15  // 服务器返回了无法识别的状态
+	exitCancel         = 130 // 收到 SIGINT/SIGTERM 取消上传（与 shell 的 128+SIGINT 约定一致）
+)
+
+// failureExitCode 全部文件失败时的退出码：失败原因属于同一类别时返回该类别的退出码
+func failureExitCode(tasks []*TaskStatus) int {
+	kind := ErrorKind("")
+	for i, task := range tasks {
+		if i > 0 && ErrorKind(task.ErrorType) != kind {
+			return exitFailure
 		}
+		kind = ErrorKind(task.ErrorType)
 	}
+	return kind.exitCode()
 }
 
-// saveTaskStatus 保存任务状态到文件
-func saveTaskStatus(statusFile string, task *TaskStatus) error {
-	data, err := json.MarshalIndent(task, "", "  ")
-	if err != nil {
-		return err
-	}
+// maxFileSize 单个文件大小限制 (50GB)
+const maxFileSize = 50 * 1024 * 1024 * 1024
 
-	// 确保目录存在
-	dir := filepath.Dir(statusFile)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
+// stringList 可重复指定的字符串参数
+type stringList []string
 
-	return os.WriteFile(statusFile, data, 0644)
+func (s *stringList) String() string {
+	return strings.Join(*s, ", ")
 }
 
-// uploadFile 上传文件 - 完全按照JavaScript逻辑
-func uploadFile(ctx context.Context, config *Config, filePath string, progressCallback func(int64, int64)) (*UploadResult, error) {
-	debugPrint(config, "开始上传文件: %s", filePath)
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
 
-	// 计算文件SHA1
-	debugPrint(config, "正在计算文件SHA1...")
-	sha1Hash, err := calculateSHA1(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("计算SHA1失败: %w", err)
-	}
-	debugPrint(config, "文件SHA1: %s", sha1Hash)
+// stdinPath 表示从标准输入读取上传数据的文件参数
+const stdinPath = "-"
+
+// uploadJob 批量上传中的单个文件任务
+type uploadJob struct {
+	FilePath   string
+	FileSize   int64  // 流式输入时为0，读取完成后才能确定
+	Name       string // -name 指定的上传文件名
+	Stream     bool   // 标准输入或命名管道，只能顺序读取一次
+	TaskID     string
+	StatusFile string
+	Model      *int   // 清单中指定的文件有效期，nil时使用命令行参数
+	MrID       string // 清单中指定的目录ID，空时使用命令行参数
+
+	manifestIndex int   // 在上传清单中的位置
+	err           error // 生成任务时已发现的错误（如清单中的文件不存在），上传时直接记为失败
+}
 
-	// 获取文件信息
-	fileInfo, err := os.Stat(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("获取文件信息失败: %w", err)
+// remoteName 返回上传后的文件名
+func (job uploadJob) remoteName() string {
+	if job.Name != "" {
+		return job.Name
 	}
-	debugPrint(config, "文件大小: %d bytes (%.2f MB)", fileInfo.Size(), float64(fileInfo.Size())/(1024*1024))
-
-	fileName := filepath.Base(filePath)
+	return filepath.Base(job.FilePath)
+}
 
-	var uploadInfo *UploadInfo
+// collectUploadFiles 展开输入路径为待上传文件列表，目录需指定recursive才会递归展开
+func collectUploadFiles(paths []string, recursive bool) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
 
-	// 检查是否为GUI模式（已预设上传服务器）
-	if config.UploadServer != "" {
-		debugPrint(config, "GUI模式: 使用预设的上传服务器: %s", config.UploadServer)
-		// GUI模式下仍需要获取UToken，但直接使用预设的上传服务器
-		uploadInfo, err = getUTokenOnly(ctx, config, sha1Hash, fileName, fileInfo.Size())
+	addFile := func(path string) {
+		absPath, err := filepath.Abs(path)
 		if err != nil {
-			return nil, fmt.Errorf("获取UToken失败: %w", err)
+			absPath = path
 		}
-		// 使用预设的上传服务器
-		uploadInfo.Server = config.UploadServer
-		debugPrint(config, "获取到UToken: %s", uploadInfo.UToken)
-		debugPrint(config, "使用预设上传服务器: %s", uploadInfo.Server)
-	} else {
-		// CLI独立模式：查找可用的上传服务器
-		debugPrint(config, "CLI独立模式: 查找可用上传服务器...")
-		uploadInfo, err = getUploadServers(ctx, config, sha1Hash, fileName, fileInfo.Size())
-		if err != nil {
-			return nil, fmt.Errorf("获取上传服务器失败: %w", err)
+		if seen[absPath] {
+			return
 		}
-		debugPrint(config, "获取到UToken: %s", uploadInfo.UToken)
-		debugPrint(config, "找到上传服务器: %s", uploadInfo.Server)
+		seen[absPath] = true
+		files = append(files, path)
 	}
 
-	// 第二步：调用prepare_v4检查是否可以秒传
-	debugPrint(config, "步骤2: 检查是否支持秒传...")
-	downloadURL, needUpload, err := checkQuickUpload(ctx, config, sha1Hash, fileName, fileInfo.Size())
-	if err != nil {
-		return nil, fmt.Errorf("检查秒传失败: %w", err)
-	}
+	for _, path := range paths {
+		if path == stdinPath {
+			if !seen[stdinPath] {
+				seen[stdinPath] = true
+				files = append(files, path)
+			}
+			continue
+		}
 
-	if !needUpload {
-		debugPrint(config, "秒传成功! 下载链接: %s", downloadURL)
-		return &UploadResult{DownloadURL: downloadURL}, nil
-	}
-	debugPrint(config, "需要分片上传")
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("文件不存在: %s", path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("获取文件信息失败: %w", err)
+		}
 
-	// 第三步：执行分片上传逻辑
-	debugPrint(config, "步骤3: 开始分片上传...")
-	downloadURL, err = workerSlice(ctx, config, filePath, sha1Hash, fileName, fileInfo.Size(), uploadInfo.UToken, progressCallback)
-	if err != nil {
-		return nil, fmt.Errorf("%w", err)
+		if !info.IsDir() {
+			addFile(path)
+			continue
+		}
+
+		if !recursive {
+			return nil, fmt.Errorf("%s 是目录，请使用 -recursive 参数上传整个目录", path)
+		}
+
+		err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.Type().IsRegular() {
+				addFile(p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("遍历目录失败: %w", err)
+		}
 	}
 
-	return &UploadResult{DownloadURL: downloadURL}, nil
+	return files, nil
 }
 
-// calculateSHA1 计算文件SHA1
-func calculateSHA1(filePath string) (string, error) {
-	file, err := os.Open(filePath)
+// indexedStatusFile 为批量任务生成带序号的状态文件路径，如 status.json -> status_2.json
+func indexedStatusFile(statusFile string, index int) string {
+	ext := filepath.Ext(statusFile)
+	return fmt.Sprintf("%s_%d%s", strings.TrimSuffix(statusFile, ext), index, ext)
+}
+
+// buildUploadJobs 展开输入路径并验证文件，为每个文件生成独立的任务ID和状态文件
+func buildUploadJobs(inputPaths []string, recursive bool, remoteName, taskID, statusFile string) ([]uploadJob, error) {
+	uploadPaths, err := collectUploadFiles(inputPaths, recursive)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer file.Close()
-
-	hasher := sha1.New()
-	if _, err := io.Copy(hasher, file); err != nil {
-		return "", err
+	if len(uploadPaths) == 0 {
+		return nil, fmt.Errorf("没有找到可上传的文件")
+	}
+	if remoteName != "" && len(uploadPaths) > 1 {
+		return nil, fmt.Errorf("-name 只能在上传单个文件时使用")
 	}
 
-	return hex.EncodeToString(hasher.Sum(nil)), nil
-}
-
-// ResumeTracker 续传进度跟踪器
-type ResumeTracker struct {
-	initialized   bool  // 是否已初始化续传状态
-	totalSlices   int   // 总分片数
-	uploadedBytes int64 // 已上传字节数（基于已完成分片估算）
-}
+	jobs := make([]uploadJob, 0, len(uploadPaths))
+	for i, path := range uploadPaths {
+		job := uploadJob{
+			FilePath:   path,
+			Name:       remoteName,
+			TaskID:     taskID,
+			StatusFile: statusFile,
+		}
 
-// workerSlice 分片上传核心逻辑，基于 JavaScript 实现，支持断点续传
-func workerSlice(ctx context.Context, config *Config, filePath, sha1Hash, fileName string, fileSize int64, utoken string, progressCallback func(int64, int64)) (string, error) {
-	// 生成uptoken (基于文件特征: SHA1(sha1 + filename + filesize + slice_size))
-	upTokenData := fmt.Sprintf("%s%s%d%d", sha1Hash, fileName, fileSize, config.ChunkSize)
-	upTokenHash := sha1.Sum([]byte(upTokenData))
-	upToken := hex.EncodeToString(upTokenHash[:])
+		if path == stdinPath {
+			// 标准输入：读取完成前大小未知，必须指定文件名
+			if remoteName == "" {
+				return nil, fmt.Errorf("从标准输入上传时必须使用 -name 指定文件名")
+			}
+			if term.IsTerminal(int(os.Stdin.Fd())) {
+				return nil, fmt.Errorf("标准输入是终端，请通过管道提供要上传的数据")
+			}
+			job.Stream = true
+		} else {
+			// 获取文件信息
+			fileInfo, err := os.Stat(path)
+			if err != nil {
+				return nil, fmt.Errorf("获取文件信息失败: %w", err)
+			}
 
-	debugPrint(config, "生成uptoken: %s -> %s", upTokenData, upToken)
-	debugPrint(config, "开始分片上传状态机循环...")
+			// 命名管道等非普通文件按流式输入处理
+			if !fileInfo.Mode().IsRegular() {
+				job.Stream = true
+			} else if fileInfo.Size() > maxFileSize {
+				// 验证文件大小限制 (50GB)
+				return nil, fmt.Errorf("文件大小超出限制，最大支持50GB，当前文件: %s (%.2fGB)",
+					path, float64(fileInfo.Size())/(1024*1024*1024))
+			}
+			job.FileSize = fileInfo.Size()
+		}
 
-	client := &http.Client{}
+		// 多文件时为每个文件追加序号，保证状态记录互不覆盖
+		if len(uploadPaths) > 1 {
+			job.TaskID = fmt.Sprintf("%s_%d", taskID, i+1)
+			job.StatusFile = indexedStatusFile(statusFile, i+1)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
 
-	// 初始化续传跟踪器
-	resumeTracker := &ResumeTracker{
-		initialized:   false,
-		totalSlices:   0,
-		uploadedBytes: 0,
+// newTaskStatus 根据上传任务创建初始任务状态
+func newTaskStatus(job uploadJob, serverName string) *TaskStatus {
+	return &TaskStatus{
+		ID:         job.TaskID,
+		Status:     "pending",
+		FilePath:   job.FilePath,
+		FileName:   job.remoteName(),
+		FileSize:   job.FileSize,
+		Progress:   0.0,
+		ServerName: serverName,
+		ProcessID:  os.Getpid(), // 记录当前进程号
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
 	}
+}
 
-	// 添加循环计数器，防止无限循环
-	loopCount := 0
-	// 根据文件大小和分片大小动态计算最大循环次数
-	// 每个分片可能需要多次API调用（prepare + upload + 状态检查）
-	// 基础循环次数 = 分片数量 * 每个分片的平均API调用次数
-	chunkSize := int64(config.ChunkSize)
-	expectedChunks := (fileSize + chunkSize - 1) / chunkSize // 向上取整
-	maxLoops := int(expectedChunks) * 10                     // 每个分片最多允许10次循环（包括重试和状态检查）
+// setTaskError 记录任务失败原因及其错误类别和服务器错误代码
+func setTaskError(task *TaskStatus, err error) {
+	kind, code := classifyError(err)
+	task.ErrorMsg = err.Error()
+	task.ErrorType = string(kind)
+	task.ErrorCode = code
+}
 
-	debugPrint(config, "预计分片数: %d, 最大循环次数: %d", expectedChunks, maxLoops)
+// runOptions 运行上传任务时的显示和状态保存选项
+type runOptions struct {
+	cliMode          bool   // 命令行模式（用户未提供task-id）
+	progressBars     bool   // 显示单个文件的开始信息、进度条和结果（同时只上传一个文件时）
+	shouldSaveStatus bool   // 是否写入状态文件
+	serverName       string // 上传服务器名称，仅用于显示
+}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return "", ctx.Err()
-		default:
+// cancelledTaskStatus 记录未开始即被取消的任务
+func cancelledTaskStatus(job uploadJob, opts runOptions, out *reporter) (*TaskStatus, FileReport) {
+	task := newTaskStatus(job, opts.serverName)
+	task.Status = "cancelled"
+	task.ErrorMsg = "上传已取消"
+	if opts.shouldSaveStatus {
+		if err := saveTaskStatus(job.StatusFile, task); err != nil {
+			fmt.Fprintf(os.Stderr, "错误: 保存取消状态失败: %v\n", err)
 		}
+	}
+	report := newFileReport(task, nil, 0)
+	out.result(report)
+	return task, report
+}
 
-		// 检查循环次数，防止无限循环
-		loopCount++
-		if loopCount > maxLoops {
-			return "", fmt.Errorf("上传超时，状态机循环次数过多（%d次）", loopCount)
-		}
+// exitCancelled 将全部任务记录为已取消并以取消退出码退出
+func exitCancelled(jobs []uploadJob, opts runOptions, out *reporter) {
+	for _, job := range jobs {
+		cancelledTaskStatus(job, opts, out)
+	}
+	out.finish()
+	if opts.cliMode {
+		fmt.Fprintf(humanOut, "⏹️  上传已取消\n")
+	} else {
+		fmt.Fprintf(os.Stderr, "上传已取消\n")
+	}
+	os.Exit(exitCancel)
+}
 
-		// 查询分片信息 (prepare)
-		debugPrint(config, "========== API请求 #%d ==========", loopCount)
-		prepareData := fmt.Sprintf("token=%s&uptoken=%s&action=prepare&sha1=%s&filename=%s&filesize=%d&slice_size=%d&utoken=%s&mr_id=%s&model=%d",
-			config.Token, upToken, sha1Hash, fileName, fileSize, config.ChunkSize, utoken, config.MrID, config.Model)
+// runUploadJob 上传单个文件并维护其任务状态，返回最终状态和上传结果
+func runUploadJob(ctx context.Context, config *Config, job uploadJob, opts runOptions, out *reporter) (*TaskStatus, FileReport) {
+	config = jobConfig(config, job)
 
-		debugPrint(config, "请求URL: %s", config.UploadServer+"/app/upload_slice")
-		debugPrint(config, "请求方法: POST")
-		debugPrint(config, "Content-Type: application/x-www-form-urlencoded")
-		debugPrint(config, "请求参数: %s", prepareData)
+	// 初始化任务状态
+	task := newTaskStatus(job, opts.serverName)
+	task.Model, task.MrID = config.Model, config.MrID
 
-		req, err := http.NewRequestWithContext(ctx, "POST", config.UploadServer+"/app/upload_slice", strings.NewReader(prepareData))
-		if err != nil {
-			debugPrint(config, "创建请求失败: %v", err)
-			return "", err
+	// 只有当需要保存状态时才保存初始状态到文件
+	if opts.shouldSaveStatus {
+		if err := saveTaskStatus(job.StatusFile, task); err != nil {
+			fmt.Fprintf(os.Stderr, "错误: 保存任务状态失败: %v\n", err)
 		}
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
 
-		resp, err := client.Do(req)
-		if err != nil {
-			debugPrint(config, "发送请求失败: %v", err)
-			return "", fmt.Errorf("网络请求失败: %w", err)
-		}
-		defer resp.Body.Close()
+	debugPrint(config, "文件路径: %s", job.FilePath)
 
-		debugPrint(config, "HTTP状态码: %d", resp.StatusCode)
+	// 创建速度计算器
+	speedCalc := NewSpeedCalculator(job.FileSize)
 
-		if resp.StatusCode != http.StatusOK {
-			debugPrint(config, "HTTP状态码错误: %d", resp.StatusCode)
-			return "", fmt.Errorf("HTTP请求失败，状态码: %d", resp.StatusCode)
-		}
+	// 设置进度回调
+	displaySize := job.FileSize
+	if job.Stream {
+		displaySize = -1
+	}
+	progressCallback := createProgressCallback(opts.progressBars, opts.shouldSaveStatus, displaySize, speedCalc, task, job.StatusFile, out)
+	hashCallback := createHashProgressCallback(opts.progressBars, opts.shouldSaveStatus, task, job.StatusFile, out)
 
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			debugPrint(config, "读取响应失败: %v", err)
-			return "", fmt.Errorf("读取响应失败: %w", err)
-		}
+	// 开始上传：先计算SHA1，完成后由hashCallback切换为上传中
+	task.Status = "hashing"
+	task.UpdatedAt = time.Now()
+	// 保存计算中状态到文件
+	if opts.shouldSaveStatus {
+		saveTaskStatus(job.StatusFile, task)
+	}
+	out.start(task)
 
-		debugPrint(config, "响应内容: %s", string(body))
+	var result *UploadResult
+	err := job.err
+	if err == nil {
+		result, err = uploadFile(ctx, config, job, hashCallback, progressCallback)
+	}
+	if err != nil && ctx.Err() != nil {
+		// 收到取消信号
+		task.Status = "cancelled"
+		task.ErrorMsg = "上传已取消"
+		task.UpdatedAt = time.Now()
 
-		var prepareResp struct {
-			Status int         `json:"status"`
-			Data   interface{} `json:"data"`
-			Debug  interface{} `json:"debug,omitempty"`
+		if opts.progressBars {
+			clearProgressBar() // 清除进度条残留
+			fmt.Fprintf(humanOut, "⏹️  上传已取消\n")
+			fmt.Fprintf(humanOut, "📁 文件名: %s\n", task.FileName)
+			printResumeHint(job, result)
+		} else if !opts.cliMode {
+			fmt.Fprintf(os.Stderr, "上传已取消: %v\n", err)
 		}
-
-		if err := json.Unmarshal(body, &prepareResp); err != nil {
-			debugPrint(config, "JSON解析失败: %v", err)
-			return "", fmt.Errorf("JSON解析失败: %w", err)
+		if opts.shouldSaveStatus {
+			if saveErr := saveTaskStatus(job.StatusFile, task); saveErr != nil {
+				fmt.Fprintf(os.Stderr, "错误: 保存取消状态失败: %v\n", saveErr)
+			}
 		}
+		report := newFileReport(task, result, time.Since(speedCalc.startTime))
+		out.result(report)
 
-		debugPrint(config, "解析结果 - 状态码: %d, 数据: %v, Debug: %v", prepareResp.Status, prepareResp.Data, prepareResp.Debug)
+		return task, report
+	}
+	if err != nil {
+		// 上传失败
+		task.Status = "failed"
+		setTaskError(task, err)
+		task.UpdatedAt = time.Now()
 
-		switch prepareResp.Status {
-		case 1:
-			// 上传完成
-			debugPrint(config, "状态1: 上传完成")
-			if ukey, ok := prepareResp.Data.(string); ok {
-				return fmt.Sprintf("https://tmp.link/f/%s", ukey), nil
+		// CLI模式：显示失败信息
+		if opts.progressBars {
+			clearProgressBar() // 清除进度条残留
+			fmt.Fprintf(humanOut, "❌ 上传失败!\n")
+			fmt.Fprintf(humanOut, "📁 文件名: %s\n", task.FileName)
+			fmt.Fprintf(humanOut, "❗ 错误信息: %v\n", err)
+			if task.ErrorType == string(ErrKindNetwork) {
+				printResumeHint(job, result)
 			}
-			return "", fmt.Errorf("无法获取ukey")
-
-		case 6:
-			// 文件已被其他人上传，直接跳过
-			debugPrint(config, "状态6: 文件已存在，直接返回")
-			if ukey, ok := prepareResp.Data.(string); ok {
-				return fmt.Sprintf("https://tmp.link/f/%s", ukey), nil
+		} else if !opts.cliMode {
+			// GUI模式下仍然输出到stderr，供调试使用
+			fmt.Fprintf(os.Stderr, "上传失败: %v\n", err)
+		}
+		// 保存失败状态到文件
+		if opts.shouldSaveStatus {
+			if saveErr := saveTaskStatus(job.StatusFile, task); saveErr != nil {
+				fmt.Fprintf(os.Stderr, "错误: 保存失败状态失败: %v\n", saveErr)
 			}
-			return "", fmt.Errorf("无法获取ukey")
+		}
+		report := newFileReport(task, result, time.Since(speedCalc.startTime))
+		out.result(report)
 
-		case 8:
-			// 分片合并完成 - 按照JavaScript逻辑直接成功
-			debugPrint(config, "状态8: 分片合并完成，上传成功")
-			if ukey, ok := prepareResp.Data.(string); ok {
-				return fmt.Sprintf("https://tmp.link/f/%s", ukey), nil
-			}
-			// 如果data是数字，也当作ukey处理
-			if ukeyNum, ok := prepareResp.Data.(float64); ok {
-				return fmt.Sprintf("https://tmp.link/f/%d", int64(ukeyNum)), nil
-			}
-			return "", fmt.Errorf("无法获取ukey")
+		return task, report
+	}
 
-		case 9:
-			// 文件合并进程正在进行中，按照JavaScript逻辑直接成功
-			debugPrint(config, "状态9: 合并进行中，按JS逻辑直接成功")
-			if ukey, ok := prepareResp.Data.(string); ok {
-				return fmt.Sprintf("https://tmp.link/f/%s", ukey), nil
-			}
-			// 如果没有ukey，等待一下再查询
-			debugPrint(config, "状态9: 没有ukey，等待2秒...")
-			time.Sleep(2 * time.Second)
-			continue
+	// 上传成功
+	task.Status = "completed"
+	task.Progress = 100.0
+	task.UpdatedAt = time.Now()
+	task.DownloadURL = result.DownloadURL
+	// 计算最终速度（确保小文件也有速度显示），流式输入的大小在读取完成后才确定
+	speedCalc.totalBytes = task.FileSize
+	task.UploadSpeed = speedCalc.GetFinalSpeed()
 
-		case 2:
-			// 没有可上传分片，等待所有分片完成
-			debugPrint(config, "状态2: 等待分片完成，等待5秒...")
-			time.Sleep(5 * time.Second)
-			continue
+	// CLI模式：显示完成信息
+	if opts.progressBars {
+		clearProgressBar() // 清除进度条残留
+		fmt.Fprintf(humanOut, "✅ 上传完成!\n")
+		fmt.Fprintf(humanOut, "📁 文件名: %s\n", task.FileName)
+		fmt.Fprintf(humanOut, "📊 文件大小: %s\n", formatBytes(task.FileSize))
+		fmt.Fprintf(humanOut, "⚡ 平均速度: %.2f MB/s\n", task.UploadSpeed/1024) // 转换为MB/s
+		duration := time.Since(speedCalc.startTime)
+		fmt.Fprintf(humanOut, "⏱️  总耗时: %v\n", duration.Round(time.Second))
+		fmt.Fprintf(humanOut, "🔗 下载链接: %s\n", result.DownloadURL)
+	}
+	// 保存完成状态到文件
+	if opts.shouldSaveStatus {
+		if err := saveTaskStatus(job.StatusFile, task); err != nil {
+			fmt.Fprintf(os.Stderr, "警告: 保存完成状态失败: %v\n", err)
+		}
+	}
+	report := newFileReport(task, result, time.Since(speedCalc.startTime))
+	out.result(report)
 
-		case 3:
-			// 获得一个需要上传的分片编号，开始处理上传
-			debugPrint(config, "状态3: 需要上传分片")
-			if dataMap, ok := prepareResp.Data.(map[string]interface{}); ok {
-				// 解析完整的分片信息（支持续传检测）
-				var totalSlices, waitingSlices, uploadedSlices int
-				var nextSlice int = -1
-
-				// 解析总分片数
-				if total, ok := dataMap["total"].(float64); ok {
-					totalSlices = int(total)
-					resumeTracker.totalSlices = totalSlices
-					debugPrint(config, "总分片数: %d", totalSlices)
-				}
+	return task, report
+}
 
-				// 解析待上传分片数
-				if wait, ok := dataMap["wait"].(float64); ok {
-					waitingSlices = int(wait)
-					uploadedSlices = totalSlices - waitingSlices
-					debugPrint(config, "待上传分片数: %d, 已完成分片数: %d", waitingSlices, uploadedSlices)
-				}
+// jobConfig 复制配置并应用清单中为单个文件指定的有效期和目录，每个任务使用自己的副本，同时上传的文件互不影响
+func jobConfig(config *Config, job uploadJob) *Config {
+	copied := *config
+	if job.Model != nil {
+		copied.Model = *job.Model
+	}
+	if job.MrID != "" {
+		copied.MrID = job.MrID
+	}
+	return &copied
+}
 
-				// 解析下一个要上传的分片编号
-				if nextFloat, ok := dataMap["next"].(float64); ok {
-					nextSlice = int(nextFloat)
-					debugPrint(config, "下一个分片编号: %d", nextSlice)
+// runJobs 以最多parallel个文件同时上传的方式执行全部任务，返回按任务顺序排列的最终状态
+// 取消后尚未开始的任务直接记录为已取消；onDone在每个任务结束后调用（可能来自不同goroutine）
+func runJobs(ctx context.Context, config *Config, jobs []uploadJob, parallel int, opts runOptions, out *reporter, onDone func(job uploadJob, task *TaskStatus, report FileReport)) []*TaskStatus {
+	tasks := make([]*TaskStatus, len(jobs))
+	var (
+		mu       sync.Mutex
+		finished int
+		wg       sync.WaitGroup
+	)
+	queue := make(chan int)
+
+	for w := 0; w < parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				job := jobs[i]
+				var (
+					task   *TaskStatus
+					report FileReport
+				)
+				if ctx.Err() != nil {
+					// 已取消：剩余文件不再上传
+					task, report = cancelledTaskStatus(job, opts, out)
+				} else {
+					task, report = runUploadJob(ctx, config, job, opts, out)
 				}
+				tasks[i] = task
 
-				// 断点续传初始化 - 只在第一次检测到续传时执行
-				if !resumeTracker.initialized && uploadedSlices > 0 && totalSlices > 0 {
-					resumeTracker.initialized = true
-
-					// 计算已上传字节数（基于已完成分片估算）
-					estimatedBytes := int64(uploadedSlices) * int64(config.ChunkSize)
-					if estimatedBytes > fileSize {
-						estimatedBytes = fileSize
-					}
-					resumeTracker.uploadedBytes = estimatedBytes
-
-					// 计算续传进度百分比
-					progressPercent := float64(uploadedSlices) / float64(totalSlices) * 100
-
-					debugPrint(config, "🔄 检测到断点续传: 已完成 %d/%d 分片 (%.1f%%)",
-						uploadedSlices, totalSlices, progressPercent)
-					debugPrint(config, "🔄 估算已上传字节数: %d/%d (%s/%s)",
-						estimatedBytes, fileSize,
-						formatBytes(estimatedBytes), formatBytes(fileSize))
-
-					// 调用进度回调更新显示
-					if progressCallback != nil {
-						progressCallback(estimatedBytes, fileSize)
-					}
+				mu.Lock()
+				finished++
+				// 并行上传时不显示进度条，每个文件结束时输出一行结果
+				if opts.cliMode && !opts.progressBars {
+					printJobLine(finished, len(jobs), task)
 				}
-
-				// 检查是否有下一个分片需要上传
-				if nextSlice >= 0 {
-					debugPrint(config, "上传分片 #%d", nextSlice)
-
-					// 上传分片
-					err := uploadSlice(ctx, client, config, filePath, fileName, upToken, nextSlice, resumeTracker, progressCallback)
-					if err != nil {
-						return "", fmt.Errorf("分片 %d: %w", nextSlice, err)
-					}
-					debugPrint(config, "分片 #%d 上传完成", nextSlice)
-
-					// 继续下一轮查询
-					continue
+				if onDone != nil {
+					onDone(job, task, report)
 				}
+				mu.Unlock()
 			}
-			return "", fmt.Errorf("无法解析分片信息")
+		}()
+	}
 
-		case 7:
-			// 按照JavaScript逻辑：rsp.data是错误代码，直接传递给upload_final
-			debugPrint(config, "状态7: 上传失败，错误代码: %v", prepareResp.Data)
+	for i := range jobs {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	return tasks
+}
 
-			// 检查是否是特殊情况：data为0、8或9（按照JavaScript逻辑直接成功）
-			if dataFloat, ok := prepareResp.Data.(float64); ok {
-				if dataFloat == 0 {
-					debugPrint(config, "状态7但data=0: 上传成功，按JavaScript逻辑直接成功")
-					// 根据debug信息构造下载链接
-					if debugMap, ok := prepareResp.Debug.(map[string]interface{}); ok {
-						if fileinfo, ok := debugMap["fileinfo"].(map[string]interface{}); ok {
-							if sha1, ok := fileinfo["sha1"].(string); ok {
-								return fmt.Sprintf("https://tmp.link/f/%s", sha1), nil
-							}
-						}
-					}
-					// 如果无法从debug获取，返回基于SHA1的链接
-					return fmt.Sprintf("https://tmp.link/f/upload_success"), nil
-				} else if dataFloat == 8 {
-					debugPrint(config, "状态7但data=8: 合并完成，按JavaScript逻辑直接成功")
-					// 根据debug信息构造下载链接
-					if debugMap, ok := prepareResp.Debug.(map[string]interface{}); ok {
-						if fileinfo, ok := debugMap["fileinfo"].(map[string]interface{}); ok {
-							if sha1, ok := fileinfo["sha1"].(string); ok {
-								return fmt.Sprintf("https://tmp.link/f/%s", sha1), nil
-							}
-						}
-					}
-					// 如果无法从debug获取，返回基于SHA1的链接
-					return fmt.Sprintf("https://tmp.link/f/upload_success"), nil
-				} else if dataFloat == 9 {
-					debugPrint(config, "状态7但data=9: 合并进行中，按JavaScript逻辑直接成功")
-					// 根据debug信息构造下载链接
-					if debugMap, ok := prepareResp.Debug.(map[string]interface{}); ok {
-						if fileinfo, ok := debugMap["fileinfo"].(map[string]interface{}); ok {
-							if sha1, ok := fileinfo["sha1"].(string); ok {
-								return fmt.Sprintf("https://tmp.link/f/%s", sha1), nil
-							}
-						}
-					}
-					// 如果无法从debug获取，返回基于SHA1的链接
-					return fmt.Sprintf("https://tmp.link/f/upload_success"), nil
-				}
-			}
+// printJobLine 并行上传时输出单个文件的结果
+func printJobLine(finished, total int, task *TaskStatus) {
+	switch task.Status {
+	case "completed":
+		fmt.Fprintf(humanOut, "[%d/%d] ✅ %s  %s\n", finished, total, task.FileName, task.DownloadURL)
+	case "cancelled":
+		fmt.Fprintf(humanOut, "[%d/%d] ⏹️  %s  %s\n", finished, total, task.FileName, task.ErrorMsg)
+	default:
+		fmt.Fprintf(humanOut, "[%d/%d] ❌ %s  %s\n", finished, total, task.FileName, task.ErrorMsg)
+	}
+}
 
-			// 获取详细错误信息
-			errorMsg := getUploadErrorMessage(prepareResp.Data)
-			return "", fmt.Errorf("%s", errorMsg)
+// printResumeHint 已保存续传记录（SHA1计算完成）时提示如何继续上传
+func printResumeHint(job uploadJob, result *UploadResult) {
+	if job.Stream || result == nil || result.SHA1 == "" {
+		return
+	}
+	fmt.Fprintf(humanOut, "💡 重新运行相同命令或使用 -resume %s 继续上传\n", job.TaskID)
+}
+
+// printBatchSummary 显示批量上传汇总
+func printBatchSummary(tasks []*TaskStatus) {
+	succeeded, cancelled := 0, 0
+	for _, task := range tasks {
+		switch task.Status {
+		case "completed":
+			succeeded++
+		case "cancelled":
+			cancelled++
+		}
+	}
 
+	fmt.Fprintln(humanOut)
+	fmt.Fprintf(humanOut, "📋 批量上传汇总: 共 %d 个文件, 成功 %d 个, 失败 %d 个",
+		len(tasks), succeeded, len(tasks)-succeeded-cancelled)
+	if cancelled > 0 {
+		fmt.Fprintf(humanOut, ", 取消 %d 个", cancelled)
+	}
+	fmt.Fprintln(humanOut)
+	for _, task := range tasks {
+		switch task.Status {
+		case "completed":
+			fmt.Fprintf(humanOut, "  ✅ %s  %s\n", task.FilePath, task.DownloadURL)
+		case "cancelled":
+			fmt.Fprintf(humanOut, "  ⏹️  %s  %s\n", task.FilePath, task.ErrorMsg)
 		default:
-			debugPrint(config, "未知状态码: %d", prepareResp.Status)
-			return "", fmt.Errorf("未知状态码: %d", prepareResp.Status)
+			fmt.Fprintf(humanOut, "  ❌ %s  %s\n", task.FilePath, task.ErrorMsg)
 		}
 	}
 }
 
-// uploadSlice 上传单个分片，支持续传进度计算
-func uploadSlice(ctx context.Context, client *http.Client, config *Config, filePath, fileName, upToken string, sliceIndex int, resumeTracker *ResumeTracker, progressCallback func(int64, int64)) error {
-	// 打开文件
-	file, err := os.Open(filePath)
-	if err != nil {
-		return fmt.Errorf("打开文件失败: %w", err)
-	}
-	defer file.Close()
+// 输出格式
+const (
+	outputText   = "text"   // 面向用户的文本（默认）
+	outputJSON   = "json"   // 全部文件结束后向stdout输出一个JSON对象
+	outputNDJSON = "ndjson" // 每行一个JSON事件，包含上传进度
+)
 
-	// 计算分片偏移和大小
-	chunkSize := int64(config.ChunkSize)
-	offset := int64(sliceIndex) * chunkSize
+// outputSchemaVersion JSON输出格式版本，字段含义发生不兼容变化时递增
+const outputSchemaVersion = 1
+
+// humanOut 面向用户的文本输出，JSON输出模式下改为stderr，保证stdout只包含JSON
+var humanOut io.Writer = os.Stdout
+
+// FileReport JSON输出中单个文件的上传结果
+type FileReport struct {
+	Type            string  `json:"type,omitempty"` // ndjson事件类型，固定为 result
+	FilePath        string  `json:"file_path"`
+	FileName        string  `json:"file_name"`
+	FileSize        int64   `json:"file_size"`
+	SHA1            string  `json:"sha1,omitempty"`
+	Status          string  `json:"status"` // completed/failed/cancelled
+	DownloadURL     string  `json:"download_url,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	AvgSpeed        float64 `json:"avg_speed"` // 平均速度（字节/秒），秒传时为0
+	InstantUpload   bool    `json:"instant_upload"`
+	ErrorType       string  `json:"error_type,omitempty"` // 失败类别，与退出码对应
+	ErrorCode       int     `json:"error_code,omitempty"` // 服务器返回的错误代码或状态码
+	Error           string  `json:"error,omitempty"`
+}
 
-	// 读取分片数据
-	_, err = file.Seek(offset, 0)
-	if err != nil {
-		return fmt.Errorf("文件定位失败: %w", err)
-	}
+// newFileReport 根据任务最终状态生成上传结果
+func newFileReport(task *TaskStatus, result *UploadResult, duration time.Duration) FileReport {
+	report := FileReport{
+		FilePath:        task.FilePath,
+		FileName:        task.FileName,
+		FileSize:        task.FileSize,
+		Status:          task.Status,
+		DownloadURL:     task.DownloadURL,
+		DurationSeconds: duration.Seconds(),
+		ErrorType:       task.ErrorType,
+		ErrorCode:       task.ErrorCode,
+		Error:           task.ErrorMsg,
+	}
+	if result != nil {
+		report.SHA1 = result.SHA1
+		report.InstantUpload = result.InstantUpload
+	}
+	if task.Status == "completed" && !report.InstantUpload && duration > 0 {
+		report.AvgSpeed = float64(task.FileSize) / duration.Seconds()
+	}
+	return report
+}
 
-	buffer := make([]byte, chunkSize)
-	n, err := file.Read(buffer)
-	if err != nil && err != io.EOF {
-		return fmt.Errorf("读取分片数据失败: %w", err)
-	}
+// reporter 按 -output 指定的格式向stdout输出机器可读的结果，text格式下不输出
+// 并行上传时多个任务同时调用，所有方法都加锁
+type reporter struct {
+	mu      sync.Mutex
+	format  string
+	enc     *json.Encoder
+	reports []FileReport
 
-	chunkData := buffer[:n]
+	// observe 可选，任务开始、计算SHA1和上传进度变化时调用（serve模式用于推送事件），调用时持有锁
+	observe func(task *TaskStatus)
+}
 
-	// 创建multipart表单
-	debugPrint(config, "========== 上传分片 #%d ==========", sliceIndex)
-	var buf bytes.Buffer
-	writer := multipart.NewWriter(&buf)
+// newReporter 创建结果输出器
+func newReporter(format string) *reporter {
+	return &reporter{format: format, enc: json.NewEncoder(os.Stdout)}
+}
 
-	// 添加文件数据
-	fileWriter, err := writer.CreateFormFile("filedata", "slice")
-	if err != nil {
-		debugPrint(config, "创建文件字段失败: %v", err)
-		return err
+// machine 是否为机器可读输出模式
+func (r *reporter) machine() bool {
+	return r.format == outputJSON || r.format == outputNDJSON
+}
+
+// start ndjson模式下输出文件开始上传事件
+func (r *reporter) start(task *TaskStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.observe != nil {
+		r.observe(task)
 	}
-	fileWriter.Write(chunkData)
-
-	// 添加表单字段
-	writer.WriteField("uptoken", upToken)
-	writer.WriteField("filename", fileName)
-	writer.WriteField("index", fmt.Sprintf("%d", sliceIndex))
-	writer.WriteField("action", "upload_slice")
-	writer.Close()
-
-	debugPrint(config, "请求URL: %s", config.UploadServer+"/app/upload_slice")
-	debugPrint(config, "请求方法: POST")
-	debugPrint(config, "Content-Type: %s", writer.FormDataContentType())
-	debugPrint(config, "分片索引: %d", sliceIndex)
-	debugPrint(config, "分片大小: %d bytes", len(chunkData))
-	debugPrint(config, "uptoken: %s", upToken)
-	debugPrint(config, "filename: %s", fileName)
-
-	// 发送上传请求
-	uploadReq, err := http.NewRequestWithContext(ctx, "POST", config.UploadServer+"/app/upload_slice", &buf)
-	if err != nil {
-		debugPrint(config, "创建上传请求失败: %v", err)
-		return err
+	if r.format != outputNDJSON {
+		return
 	}
-	uploadReq.Header.Set("Content-Type", writer.FormDataContentType())
+	r.enc.Encode(struct {
+		Type     string `json:"type"`
+		FilePath string `json:"file_path"`
+		FileName string `json:"file_name"`
+		FileSize int64  `json:"file_size"`
+	}{"start", task.FilePath, task.FileName, task.FileSize})
+}
 
-	uploadResp, err := client.Do(uploadReq)
-	if err != nil {
-		debugPrint(config, "发送上传请求失败: %v", err)
-		return err
+// hashing ndjson模式下输出SHA1计算进度事件
+func (r *reporter) hashing(task *TaskStatus, hashed, total int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.observe != nil {
+		r.observe(task)
 	}
-	defer uploadResp.Body.Close()
-
-	debugPrint(config, "HTTP状态码: %d", uploadResp.StatusCode)
-
-	if uploadResp.StatusCode != http.StatusOK {
-		debugPrint(config, "HTTP请求失败: %d", uploadResp.StatusCode)
-		return fmt.Errorf("HTTP请求失败: %d", uploadResp.StatusCode)
+	if r.format != outputNDJSON {
+		return
 	}
+	r.enc.Encode(struct {
+		Type     string  `json:"type"`
+		FilePath string  `json:"file_path"`
+		Hashed   int64   `json:"hashed"`
+		Total    int64   `json:"total"`
+		Percent  float64 `json:"percent"`
+	}{"hashing", task.FilePath, hashed, total, task.HashProgress})
+}
 
-	uploadBody, err := io.ReadAll(uploadResp.Body)
-	if err != nil {
-		debugPrint(config, "读取上传响应失败: %v", err)
-		return err
+// progress ndjson模式下输出上传进度事件
+func (r *reporter) progress(task *TaskStatus, uploaded, total int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.observe != nil {
+		r.observe(task)
 	}
-
-	debugPrint(config, "上传响应内容: %s", string(uploadBody))
-
-	var uploadResult struct {
-		Status int `json:"status"`
+	if r.format != outputNDJSON {
+		return
 	}
+	r.enc.Encode(struct {
+		Type     string  `json:"type"`
+		FilePath string  `json:"file_path"`
+		Uploaded int64   `json:"uploaded"`
+		Total    int64   `json:"total"`
+		Percent  float64 `json:"percent"`
+		Speed    float64 `json:"speed"` // 字节/秒
+	}{"progress", task.FilePath, uploaded, total, task.Progress, task.UploadSpeed * 1024})
+}
 
-	if err := json.Unmarshal(uploadBody, &uploadResult); err != nil {
-		debugPrint(config, "JSON解析失败: %v", err)
-		return fmt.Errorf("解析上传响应失败: %w", err)
+// result 记录单个文件的结果，ndjson模式下立即输出
+func (r *reporter) result(report FileReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// 只有json/ndjson模式需要汇总，常驻模式（watch、serve）下不保留结果
+	if r.machine() {
+		r.reports = append(r.reports, report)
 	}
+	if r.format == outputNDJSON {
+		report.Type = "result"
+		r.enc.Encode(report)
+	}
+}
 
-	debugPrint(config, "解析结果 - 状态码: %d", uploadResult.Status)
-
-	// 根据JavaScript代码，状态5表示分片上传完成
-	if uploadResult.Status != 1 && uploadResult.Status != 2 && uploadResult.Status != 3 && uploadResult.Status != 5 {
-		debugPrint(config, "分片上传失败，状态码: %d", uploadResult.Status)
-		// 为分片上传失败提供更详细的错误信息
-		var errorMsg string
-		switch uploadResult.Status {
-		case 7:
-			errorMsg = "认证错误或权限不足，请检查Token是否有效"
-		case 4:
-			errorMsg = "分片大小超出限制"
-		case 6:
-			errorMsg = "没有权限上传到指定位置"
+// finish 输出汇总：json模式为包含全部结果的对象，ndjson模式为summary事件
+func (r *reporter) finish() {
+	if !r.machine() {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	summary := struct {
+		Type          string       `json:"type,omitempty"`
+		SchemaVersion int          `json:"schema_version"`
+		Succeeded     int          `json:"succeeded"`
+		Failed        int          `json:"failed"`
+		Cancelled     int          `json:"cancelled"`
+		Results       []FileReport `json:"results,omitempty"`
+	}{SchemaVersion: outputSchemaVersion}
+
+	for _, report := range r.reports {
+		switch report.Status {
+		case "completed":
+			summary.Succeeded++
+		case "cancelled":
+			summary.Cancelled++
 		default:
-			errorMsg = fmt.Sprintf("分片上传失败，状态码: %d", uploadResult.Status)
+			summary.Failed++
 		}
-		return fmt.Errorf("%s", errorMsg)
 	}
 
-	debugPrint(config, "分片 #%d 上传成功", sliceIndex)
-
-	// 更新进度（支持续传）
-	if progressCallback != nil {
-		fileInfo, _ := os.Stat(filePath)
-
-		// 简化的进度计算：基于分片索引 + 1（已完成的分片数）
-		completedSlices := int64(sliceIndex + 1)
-		totalUploadedBytes := completedSlices * int64(config.ChunkSize)
-
-		// 确保不超过文件总大小
-		if totalUploadedBytes > fileInfo.Size() {
-			totalUploadedBytes = fileInfo.Size()
+	if r.format == outputNDJSON {
+		summary.Type = "summary"
+	} else {
+		summary.Results = r.reports
+		if summary.Results == nil {
+			summary.Results = []FileReport{}
 		}
-
-		debugPrint(config, "进度更新: 分片#%d完成, 总进度: %d/%d bytes (%.1f%%)",
-			sliceIndex, totalUploadedBytes, fileInfo.Size(),
-			float64(totalUploadedBytes)/float64(fileInfo.Size())*100)
-
-		progressCallback(totalUploadedBytes, fileInfo.Size())
 	}
-
-	return nil
-}
-
-// UploadInfo 上传信息
-type UploadInfo struct {
-	UToken string
-	Server string
+	r.enc.Encode(summary)
 }
 
-// getUTokenOnly 仅获取UToken（GUI模式使用）
-func getUTokenOnly(ctx context.Context, config *Config, sha1Hash, fileName string, fileSize int64) (*UploadInfo, error) {
-	debugPrint(config, "========== 获取UToken API ==========")
-	client := &http.Client{}
-
-	formData := fmt.Sprintf("action=upload_request_select2&sha1=%s&filename=%s&filesize=%d&model=%d&token=%s",
-		sha1Hash, fileName, fileSize, config.Model, config.Token)
-
-	debugPrint(config, "请求URL: %s", config.Server+"/file")
-	debugPrint(config, "请求方法: POST")
-	debugPrint(config, "Content-Type: application/x-www-form-urlencoded")
-	debugPrint(config, "请求参数: %s", formData)
-
-	req, err := http.NewRequestWithContext(ctx, "POST", config.Server+"/file", strings.NewReader(formData))
+// saveTaskStatus 保存任务状态到文件
+func saveTaskStatus(statusFile string, task *TaskStatus) error {
+	data, err := json.MarshalIndent(task, "", "  ")
 	if err != nil {
-		debugPrint(config, "创建请求失败: %v", err)
-		return nil, err
+		return err
 	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := client.Do(req)
-	if err != nil {
-		debugPrint(config, "发送请求失败: %v", err)
-		return nil, err
+	// 确保目录存在
+	dir := filepath.Dir(statusFile)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
 	}
-	defer resp.Body.Close()
 
-	debugPrint(config, "HTTP状态码: %d", resp.StatusCode)
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		debugPrint(config, "读取响应失败: %v", err)
-		return nil, err
-	}
+	return os.WriteFile(statusFile, data, 0644)
+}
 
-	debugPrint(config, "响应内容: %s", string(body))
+// uploadFile 上传文件 - 完全按照JavaScript逻辑
+// 本地续传记录中缓存了SHA1、UToken和上传服务器时直接使用，已上传的分片仍以prepare的结果为准
+// hashCallback 报告SHA1计算进度，SHA1计算完成（或使用缓存）时以 hashed == total 回调
+func uploadFile(ctx context.Context, config *Config, job uploadJob, hashCallback, progressCallback func(int64, int64)) (*UploadResult, error) {
+	filePath := job.FilePath
+	debugPrint(config, "开始上传文件: %s", filePath)
 
-	var selectResp struct {
-		Status int `json:"status"`
-		Data   struct {
-			UToken  string      `json:"utoken"`
-			Servers interface{} `json:"servers"`
-		} `json:"data"`
+	// 流式输入只能读取一次：边读取边计算SHA1并写入临时文件，之后的分片上传和重试都从临时文件读取
+	var sha1Hash string
+	if job.Stream {
+		spoolPath, streamSHA1, err := spoolStream(ctx, filePath, hashCallback)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(spoolPath)
+		debugPrint(config, "流式输入已写入临时文件: %s", spoolPath)
+		filePath, sha1Hash = spoolPath, streamSHA1
 	}
 
-	if err := json.Unmarshal(body, &selectResp); err != nil {
-		debugPrint(config, "JSON解析失败: %v", err)
-		return nil, fmt.Errorf("解析响应失败: %w", err)
+	// 获取文件信息
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("获取文件信息失败: %w", err)
 	}
+	debugPrint(config, "文件大小: %d bytes (%.2f MB)", fileInfo.Size(), float64(fileInfo.Size())/(1024*1024))
 
-	debugPrint(config, "解析结果 - 状态码: %d, UToken: %s", selectResp.Status, selectResp.Data.UToken)
-
-	if selectResp.Status != 1 {
-		debugPrint(config, "API返回错误状态: %d", selectResp.Status)
-		var errorMsg string
-		switch selectResp.Status {
-		case 2:
-			errorMsg = "获取UToken失败: Token无效或已过期"
-		case 3:
-			errorMsg = "获取UToken失败: 用户权限不足"
-		case 0:
-			errorMsg = "获取UToken失败: 请求参数错误"
-		default:
-			errorMsg = fmt.Sprintf("获取UToken失败，状态码: %d", selectResp.Status)
+	// 打开本地续传记录，记录不可用时照常上传；流式输入无法重新读取，不保存续传记录
+	var journal *resumeJournal
+	if !job.Stream {
+		journal, err = openResumeJournal(config, job, fileInfo)
+		if err != nil {
+			debugPrint(config, "打开续传记录失败: %v", err)
 		}
-		return nil, fmt.Errorf("%s", errorMsg)
+		sha1Hash = journal.sha1()
 	}
 
-	return &UploadInfo{
-		UToken: selectResp.Data.UToken,
-		Server: "", // 在调用方设置
-	}, nil
-}
-
-// getUploadServers 获取上传服务器列表
-func getUploadServers(ctx context.Context, config *Config, sha1Hash, fileName string, fileSize int64) (*UploadInfo, error) {
-	debugPrint(config, "========== 获取上传服务器API ==========")
-	client := &http.Client{}
-
-	formData := fmt.Sprintf("action=upload_request_select2&sha1=%s&filename=%s&filesize=%d&model=%d&token=%s",
-		sha1Hash, fileName, fileSize, config.Model, config.Token)
+	// 计算文件SHA1（文件未变化时使用续传记录中的缓存）
+	if sha1Hash != "" {
+		debugPrint(config, "使用续传记录中缓存的SHA1: %s", sha1Hash)
+	} else {
+		debugPrint(config, "正在计算文件SHA1...")
+		sha1Hash, err = calculateSHA1(ctx, filePath, hashCallback)
+		if err != nil {
+			return nil, fmt.Errorf("计算SHA1失败: %w", err)
+		}
+		debugPrint(config, "文件SHA1: %s", sha1Hash)
+		if err := journal.setSHA1(sha1Hash); err != nil {
+			debugPrint(config, "保存续传记录失败: %v", err)
+		}
+	}
+	hashCallback(fileInfo.Size(), fileInfo.Size())
+	result := &UploadResult{SHA1: sha1Hash}
 
-	debugPrint(config, "请求URL: %s", config.Server+"/file")
-	debugPrint(config, "请求方法: POST")
-	debugPrint(config, "Content-Type: application/x-www-form-urlencoded")
-	debugPrint(config, "请求参数: %s", formData)
+	fileName := job.remoteName()
 
-	req, err := http.NewRequestWithContext(ctx, "POST", config.Server+"/file", strings.NewReader(formData))
-	if err != nil {
-		debugPrint(config, "创建请求失败: %v", err)
-		return nil, err
+	uploadInfo := journal.uploadInfo()
+	cachedUploadInfo := uploadInfo != nil
+	if cachedUploadInfo {
+		debugPrint(config, "使用续传记录中的UToken: %s", credential.Redact(uploadInfo.UToken))
+		if config.UploadServer != "" {
+			// 已预设上传服务器时以预设为准
+			uploadInfo.Server = config.UploadServer
+		}
+		debugPrint(config, "使用上传服务器: %s", uploadInfo.Server)
+	} else {
+		uploadInfo, err = requestUploadInfo(ctx, config, journal, sha1Hash, fileName, fileInfo.Size())
+		if err != nil {
+			return result, err
+		}
 	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := client.Do(req)
+	// 第二步：调用prepare_v4检查是否可以秒传
+	debugPrint(config, "步骤2: 检查是否支持秒传...")
+	downloadURL, needUpload, err := checkQuickUpload(ctx, config, sha1Hash, fileName, fileInfo.Size())
 	if err != nil {
-		debugPrint(config, "发送请求失败: %v", err)
-		return nil, err
+		return result, fmt.Errorf("检查秒传失败: %w", err)
 	}
-	defer resp.Body.Close()
-
-	debugPrint(config, "HTTP状态码: %d", resp.StatusCode)
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		debugPrint(config, "读取响应失败: %v", err)
-		return nil, err
+	if !needUpload {
+		debugPrint(config, "秒传成功! 下载链接: %s", downloadURL)
+		journal.remove()
+		result.DownloadURL = downloadURL
+		result.InstantUpload = true
+		return result, nil
 	}
+	debugPrint(config, "需要分片上传")
 
-	debugPrint(config, "响应内容: %s", string(body))
-
-	var selectResp struct {
-		Status int `json:"status"`
-		Data   struct {
-			UToken  string      `json:"utoken"`
-			Servers interface{} `json:"servers"`
-		} `json:"data"`
+	// 第三步：执行分片上传逻辑
+	debugPrint(config, "步骤3: 开始分片上传...")
+	downloadURL, err = workerSlice(ctx, config, journal, filePath, sha1Hash, fileName, fileInfo.Size(), uploadInfo, progressCallback)
+	if kind, _ := classifyError(err); kind == ErrKindAuth && cachedUploadInfo && ctx.Err() == nil {
+		// 缓存的UToken可能已过期，重新获取后再试一次
+		debugPrint(config, "续传记录中的UToken已失效，重新获取: %v", err)
+		uploadInfo, err = requestUploadInfo(ctx, config, journal, sha1Hash, fileName, fileInfo.Size())
+		if err != nil {
+			return result, err
+		}
+		downloadURL, err = workerSlice(ctx, config, journal, filePath, sha1Hash, fileName, fileInfo.Size(), uploadInfo, progressCallback)
+	}
+	if err != nil {
+		return result, err
 	}
 
-	if err := json.Unmarshal(body, &selectResp); err != nil {
-		debugPrint(config, "JSON解析失败: %v", err)
-		return nil, fmt.Errorf("解析响应失败: %w", err)
+	if err := journal.remove(); err != nil {
+		debugPrint(config, "删除续传记录失败: %v", err)
 	}
+	result.DownloadURL = downloadURL
+	return result, nil
+}
 
-	debugPrint(config, "解析结果 - 状态码: %d, UToken: %s, Servers: %v", selectResp.Status, selectResp.Data.UToken, selectResp.Data.Servers)
+// requestUploadInfo 获取UToken和上传服务器，并写入续传记录
+func requestUploadInfo(ctx context.Context, config *Config, journal *resumeJournal, sha1Hash, fileName string, fileSize int64) (*UploadInfo, error) {
+	var uploadInfo *UploadInfo
+	var err error
 
-	if selectResp.Status != 1 {
-		debugPrint(config, "API返回错误状态: %d", selectResp.Status)
-		var errorMsg string
-		switch selectResp.Status {
-		case 2:
-			errorMsg = "获取上传服务器失败: Token无效或已过期"
-		case 3:
-			errorMsg = "获取上传服务器失败: 用户权限不足"
-		case 0:
-			errorMsg = "获取上传服务器失败: 请求参数错误"
-		default:
-			errorMsg = fmt.Sprintf("获取上传服务器失败，状态码: %d", selectResp.Status)
+	// 检查是否为GUI模式（已预设上传服务器）
+	if config.UploadServer != "" {
+		debugPrint(config, "GUI模式: 使用预设的上传服务器: %s", config.UploadServer)
+		// GUI模式下仍需要获取UToken，但直接使用预设的上传服务器
+		uploadInfo, err = getUTokenOnly(ctx, config, sha1Hash, fileName, fileSize)
+		if err != nil {
+			return nil, fmt.Errorf("获取UToken失败: %w", err)
 		}
-		return nil, fmt.Errorf("%s", errorMsg)
-	}
-
-	// 解析第一个可用的上传服务器
-	var uploadServer string
-	if selectResp.Data.Servers != nil {
-		if serverList, ok := selectResp.Data.Servers.([]interface{}); ok && len(serverList) > 0 {
-			if serverObj, ok := serverList[0].(map[string]interface{}); ok {
-				if serverURL, ok := serverObj["url"].(string); ok {
-					uploadServer = serverURL
-				}
-			}
+		// 使用预设的上传服务器
+		uploadInfo.Server = config.UploadServer
+		debugPrint(config, "获取到UToken: %s", credential.Redact(uploadInfo.UToken))
+		debugPrint(config, "使用预设上传服务器: %s", uploadInfo.Server)
+	} else {
+		// CLI独立模式：查找可用的上传服务器
+		debugPrint(config, "CLI独立模式: 查找可用上传服务器...")
+		uploadInfo, err = getUploadServers(ctx, config, sha1Hash, fileName, fileSize)
+		if err != nil {
+			return nil, fmt.Errorf("获取上传服务器失败: %w", err)
 		}
+		debugPrint(config, "获取到UToken: %s", credential.Redact(uploadInfo.UToken))
+		debugPrint(config, "找到上传服务器: %s", uploadInfo.Server)
 	}
 
-	if uploadServer == "" {
-		return nil, fmt.Errorf("无法获取上传服务器地址")
-	}
-
-	// 检查是否用户强制指定了上传服务器
-	if config.UploadServer != "" {
-		debugPrint(config, "使用用户指定的上传服务器: %s", config.UploadServer)
-		uploadServer = config.UploadServer
-	} else {
-		// 设置从API获取的上传服务器到配置中
-		config.UploadServer = uploadServer
+	if err := journal.setUploadInfo(uploadInfo); err != nil {
+		debugPrint(config, "保存续传记录失败: %v", err)
 	}
-
-	return &UploadInfo{
-		UToken: selectResp.Data.UToken,
-		Server: uploadServer,
-	}, nil
+	return uploadInfo, nil
 }
 
-// checkQuickUpload 检查是否可以秒传
-func checkQuickUpload(ctx context.Context, config *Config, sha1Hash, fileName string, fileSize int64) (string, bool, error) {
-	debugPrint(config, "========== 检查秒传API ==========")
-	client := &http.Client{}
-
-	formData := fmt.Sprintf("action=prepare_v4&sha1=%s&filename=%s&filesize=%d&model=%d&skip_upload=%d&token=%s",
-		sha1Hash, fileName, fileSize, config.Model, config.SkipUpload, config.Token)
+// 计算SHA1时的读取参数：文件按块由多个协程并行 ReadAt 读取，再按顺序交给哈希计算
+// 获取UToken、上传服务器和秒传检查 (prepare_v4) 都需要整个文件的SHA1，因此必须在发送任何分片前单独读取一遍文件；
+// 并行读取可以让网络存储同时处理多个请求，减少这一遍读取的耗时
+const (
+	hashBlockSize = 4 * 1024 * 1024 // 每次读取的块大小
+	hashReaders   = 4               // 并行读取的协程数
+	hashReadAhead = 8               // 最多提前读取的块数
+)
 
-	debugPrint(config, "请求URL: %s", config.Server+"/file")
-	debugPrint(config, "请求方法: POST")
-	debugPrint(config, "Content-Type: application/x-www-form-urlencoded")
-	debugPrint(config, "请求参数: %s", formData)
+// hashBlock 并行读取的一个块
+type hashBlock struct {
+	buf []byte
+	err error
+}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", config.Server+"/file", strings.NewReader(formData))
+// calculateSHA1 计算文件SHA1，progress 在每个块完成后回调已计算的字节数（可为nil）
+func calculateSHA1(ctx context.Context, filePath string, progress func(hashed, total int64)) (string, error) {
+	file, err := os.Open(filePath)
 	if err != nil {
-		debugPrint(config, "创建请求失败: %v", err)
-		return "", false, err
+		return "", err
 	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	defer file.Close()
 
-	resp, err := client.Do(req)
+	fileInfo, err := file.Stat()
 	if err != nil {
-		debugPrint(config, "发送请求失败: %v", err)
-		return "", false, err
+		return "", err
 	}
-	defer resp.Body.Close()
+	total := fileInfo.Size()
+
+	// 分发协程按顺序为每个块取一个空闲缓冲区，放入 pending 保证哈希顺序，再交给读取协程；
+	// 缓冲区总数限制了提前读取的数据量，哈希计算完的块放回空闲缓冲区
+	done := make(chan struct{})
+	defer close(done)
+	free := make(chan []byte, hashReadAhead)
+	for i := 0; i < hashReadAhead; i++ {
+		free <- make([]byte, hashBlockSize)
+	}
+	type readJob struct {
+		offset int64
+		buf    []byte
+		result chan<- hashBlock
+	}
+	jobs := make(chan readJob)
+	pending := make(chan chan hashBlock, hashReadAhead)
+
+	go func() {
+		defer close(jobs)
+		defer close(pending)
+		for offset := int64(0); offset < total; offset += hashBlockSize {
+			var buf []byte
+			select {
+			case buf = <-free:
+			case <-done:
+				return
+			}
+			if remaining := total - offset; remaining < hashBlockSize {
+				buf = buf[:remaining]
+			}
+			result := make(chan hashBlock, 1)
+			select {
+			case pending <- result:
+			case <-done:
+				return
+			}
+			select {
+			case jobs <- readJob{offset: offset, buf: buf, result: result}:
+			case <-done:
+				return
+			}
+		}
+	}()
 
-	debugPrint(config, "HTTP状态码: %d", resp.StatusCode)
+	for i := 0; i < hashReaders; i++ {
+		go func() {
+			for job := range jobs {
+				if err := ctx.Err(); err != nil {
+					job.result <- hashBlock{err: err}
+					continue
+				}
+				_, err := file.ReadAt(job.buf, job.offset)
+				if err == io.EOF {
+					err = fmt.Errorf("文件在计算SHA1时被截断")
+				}
+				job.result <- hashBlock{buf: job.buf, err: err}
+			}
+		}()
+	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		debugPrint(config, "读取响应失败: %v", err)
-		return "", false, err
+	hasher := sha1.New()
+	var hashed int64
+	for result := range pending {
+		block := <-result
+		if block.err != nil {
+			return "", block.err
+		}
+		hasher.Write(block.buf)
+		hashed += int64(len(block.buf))
+		free <- block.buf[:cap(block.buf)]
+		if progress != nil {
+			progress(hashed, total)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
 	}
 
-	debugPrint(config, "响应内容: %s", string(body))
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
 
-	var prepareResp struct {
-		Status int         `json:"status"`
-		Data   interface{} `json:"data"`
+// spoolStream 将标准输入或命名管道的数据写入临时文件并同时计算SHA1，返回临时文件路径和SHA1
+// 读取完成前总大小未知，progress 以 total = -1 回调已读取的字节数
+func spoolStream(ctx context.Context, path string, progress func(hashed, total int64)) (string, string, error) {
+	var src io.Reader = os.Stdin
+	if path != stdinPath {
+		file, err := os.Open(path)
+		if err != nil {
+			return "", "", fmt.Errorf("打开输入失败: %w", err)
+		}
+		defer file.Close()
+		src = file
 	}
 
-	if err := json.Unmarshal(body, &prepareResp); err != nil {
-		debugPrint(config, "JSON解析失败: %v", err)
-		return "", false, fmt.Errorf("解析响应失败: %w", err)
+	spool, err := os.CreateTemp("", "tmplink-stream-*")
+	if err != nil {
+		return "", "", fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	fail := func(err error) (string, string, error) {
+		spool.Close()
+		os.Remove(spool.Name())
+		return "", "", err
 	}
 
-	debugPrint(config, "解析结果 - 状态码: %d, 数据: %v", prepareResp.Status, prepareResp.Data)
-
-	switch prepareResp.Status {
-	case 6, 8:
-		// 秒传成功
-		if dataMap, ok := prepareResp.Data.(map[string]interface{}); ok {
-			if ukey, exists := dataMap["ukey"].(string); exists {
-				return fmt.Sprintf("https://tmp.link/f/%s", ukey), false, nil
+	hasher := sha1.New()
+	writer := io.MultiWriter(spool, hasher)
+	reader := &contextReader{ctx: ctx, r: src}
+	buf := make([]byte, hashBlockSize)
+	var read int64
+	for {
+		n, err := reader.Read(buf)
+		if n > 0 {
+			read += int64(n)
+			if read > maxFileSize {
+				return fail(fmt.Errorf("输入数据超出50GB大小限制"))
+			}
+			if _, werr := writer.Write(buf[:n]); werr != nil {
+				return fail(fmt.Errorf("写入临时文件失败: %w", werr))
+			}
+			if progress != nil {
+				progress(read, -1)
 			}
 		}
-		return "", false, fmt.Errorf("秒传响应格式错误")
-	case 1:
-		// 需要分片上传
-		return "", true, nil
-	case 0:
-		// 需要分片上传
-		return "", true, nil
-	default:
-		return "", false, fmt.Errorf("准备上传失败，状态码: %d", prepareResp.Status)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fail(fmt.Errorf("读取输入失败: %w", err))
+		}
 	}
-}
 
-// validateTokenAndGetUID 验证token并获取用户UID
-func validateTokenAndGetUID(token, server string) (string, error) {
-	client := &http.Client{}
+	if err := spool.Close(); err != nil {
+		os.Remove(spool.Name())
+		return "", "", fmt.Errorf("写入临时文件失败: %w", err)
+	}
+	return spool.Name(), hex.EncodeToString(hasher.Sum(nil)), nil
+}
 
-	// 调用/user API验证token并获取用户信息
-	formData := fmt.Sprintf("action=get_detail&token=%s", token)
+// contextReader 上下文取消后读取立即返回错误，使大文件的长时间读取可以被中断
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
 
-	req, err := http.NewRequest("POST", server+"/user", strings.NewReader(formData))
-	if err != nil {
-		return "", err
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
 	}
+	return c.r.Read(p)
+}
 
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+// ResumeTracker 续传进度跟踪器，同时汇总并发分片的完成情况
+type ResumeTracker struct {
+	mu            sync.Mutex
+	initialized   bool  // 是否已初始化续传状态
+	totalSlices   int   // 总分片数
+	uploadedBytes int64 // 续传前已上传的字节数（基于服务器返回的已完成分片数估算）
+
+	fileSize         int64
+	ackedSlices      map[int]bool  // 本次运行中服务器已确认的分片
+	ackedBytes       int64         // 本次运行中已确认分片的实际字节数
+	inFlight         map[int]int64 // 正在上传中的分片及本次请求已发送的字节数
+	progressCallback func(int64, int64)
+	journal          *resumeJournal // 本地续传记录（可为nil）
+}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
+// newResumeTracker 创建续传进度跟踪器
+func newResumeTracker(fileSize int64, journal *resumeJournal, progressCallback func(int64, int64)) *ResumeTracker {
+	return &ResumeTracker{
+		fileSize:         fileSize,
+		ackedSlices:      make(map[int]bool),
+		inFlight:         make(map[int]int64),
+		progressCallback: progressCallback,
+		journal:          journal,
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP请求失败: %d", resp.StatusCode)
-	}
+// initResume 根据第一次prepare响应初始化续传状态，返回是否检测到断点续传
+func (rt *ResumeTracker) initResume(totalSlices, uploadedSlices int, chunkSize int64) bool {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
+	if totalSlices > 0 {
+		rt.totalSlices = totalSlices
 	}
-
-	// 使用两阶段解析处理不同的响应格式
-	var baseResp struct {
-		Status int         `json:"status"`
-		Data   interface{} `json:"data"`
+	if rt.initialized {
+		return false
 	}
+	rt.initialized = true
 
-	if err := json.Unmarshal(body, &baseResp); err != nil {
-		return "", fmt.Errorf("解析响应失败: %w", err)
+	if uploadedSlices <= 0 || totalSlices <= 0 {
+		// 服务器没有已上传的分片（如上传会话已过期），本地记录的分片随之失效
+		rt.journal.resetSlices()
+		return false
 	}
 
-	if baseResp.Status != 1 {
-		// Token无效时，data可能是字符串错误信息
-		var errorMsg string
-		switch baseResp.Status {
-		case 2:
-			errorMsg = "Token无效或已过期，请重新获取API Token"
-		case 3:
-			errorMsg = "用户账号被禁用"
-		case 0:
-			errorMsg = "请求参数错误"
-		default:
-			errorMsg = fmt.Sprintf("验证失败，状态码: %d", baseResp.Status)
-		}
-
-		// 如果data是字符串，追加详细错误信息
-		if dataStr, ok := baseResp.Data.(string); ok && dataStr != "" {
-			errorMsg += fmt.Sprintf(" (%s)", dataStr)
+	// 计算已上传字节数：本地记录与服务器一致时按分片实际大小计算，否则按已完成分片数估算
+	estimatedBytes := int64(uploadedSlices) * chunkSize
+	if prior := rt.journal.ackedSlices(); len(prior) == uploadedSlices {
+		estimatedBytes = 0
+		for _, index := range prior {
+			estimatedBytes += sliceLength(rt.fileSize, chunkSize, index)
 		}
-
-		return "", fmt.Errorf("%s", errorMsg)
 	}
-
-	// 状态为1时，data应该是包含用户信息的对象
-	if dataMap, ok := baseResp.Data.(map[string]interface{}); ok {
-		if uidFloat, exists := dataMap["uid"].(float64); exists && uidFloat > 0 {
-			return fmt.Sprintf("%.0f", uidFloat), nil
-		}
-		if uidInt, exists := dataMap["uid"].(int64); exists && uidInt > 0 {
-			return fmt.Sprintf("%d", uidInt), nil
-		}
+	if estimatedBytes > rt.fileSize {
+		estimatedBytes = rt.fileSize
 	}
-
-	return "", fmt.Errorf("无法获取用户UID")
+	rt.uploadedBytes = estimatedBytes
+	rt.reportLocked()
+	return true
 }
 
-// UploadTokens 上传所需的tokens
-type UploadTokens struct {
-	UToken       string // 从服务器获取的上传token
-	UpToken      string // 客户端生成的上传token
-	UploadServer string // 上传服务器地址
+// claim 标记分片为上传中，若该分片已被其他线程占用则返回false
+func (rt *ResumeTracker) claim(sliceIndex int) bool {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if _, busy := rt.inFlight[sliceIndex]; busy || rt.ackedSlices[sliceIndex] {
+		return false
+	}
+	rt.inFlight[sliceIndex] = 0
+	return true
 }
 
-// prepareUpload 准备上传
-func prepareUpload(ctx context.Context, config *Config, filePath, sha1Hash string, fileSize int64) (string, bool, *UploadTokens, error) {
-	client := &http.Client{}
+// release 取消分片的上传中标记（上传失败时调用）
+func (rt *ResumeTracker) release(sliceIndex int) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	delete(rt.inFlight, sliceIndex)
+}
 
-	// 第一步：upload_request_select2 - 获取上传服务器信息
-	formData := fmt.Sprintf("action=upload_request_select2&sha1=%s&filename=%s&filesize=%d&model=%d&token=%s",
-		sha1Hash, filepath.Base(filePath), fileSize, config.Model, config.Token)
+// progress 更新上传中分片已发送的字节数并回调进度
+func (rt *ResumeTracker) progress(sliceIndex int, sent int64) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
 
-	if config.MrID != "" {
-		formData += "&mr_id=" + config.MrID
+	if _, busy := rt.inFlight[sliceIndex]; !busy {
+		return
 	}
+	rt.inFlight[sliceIndex] = sent
+	rt.reportLocked()
+}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", config.Server+"/file", strings.NewReader(formData))
-	if err != nil {
-		return "", false, nil, err
+// refresh 按当前状态重新回调一次进度
+func (rt *ResumeTracker) refresh() {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.reportLocked()
+}
+
+// ack 记录服务器已确认的分片并更新进度，返回当前累计字节数
+func (rt *ResumeTracker) ack(sliceIndex int, sliceBytes int64) int64 {
+	rt.mu.Lock()
+	delete(rt.inFlight, sliceIndex)
+	if !rt.ackedSlices[sliceIndex] {
+		rt.ackedSlices[sliceIndex] = true
+		rt.ackedBytes += sliceBytes
 	}
+	total := rt.reportLocked()
+	rt.mu.Unlock()
 
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// 写入本地续传记录，失败时只影响下次续传的进度估算
+	rt.journal.ackSlice(sliceIndex)
+	return total
+}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", false, nil, err
+// sliceLength 返回分片的实际字节数（最后一个分片可能不足chunkSize），分片超出文件范围时返回负数
+func sliceLength(fileSize, chunkSize int64, sliceIndex int) int64 {
+	size := fileSize - int64(sliceIndex)*chunkSize
+	if size > chunkSize {
+		size = chunkSize
 	}
-	defer resp.Body.Close()
+	return size
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", false, nil, fmt.Errorf("HTTP请求失败: %d", resp.StatusCode)
+// reportLocked 计算累计进度并回调，调用方需持有锁以保证回调串行执行
+// 进度 = 续传前已完成的字节 + 本次已确认分片的实际字节 + 上传中分片已发送的字节
+func (rt *ResumeTracker) reportLocked() int64 {
+	total := rt.uploadedBytes + rt.ackedBytes
+	for _, sent := range rt.inFlight {
+		total += sent
+	}
+	if total > rt.fileSize {
+		total = rt.fileSize
 	}
+	if rt.progressCallback != nil {
+		rt.progressCallback(total, rt.fileSize)
+	}
+	return total
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", false, nil, err
+// sleepWithContext 等待指定时长，上下文取消时提前返回
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
+}
+
+// workerSlice 分片上传核心逻辑，基于 JavaScript 实现，支持断点续传和多分片并发上传
+// 分片上传到 uploadInfo 中的上传服务器
+func workerSlice(ctx context.Context, config *Config, journal *resumeJournal, filePath, sha1Hash, fileName string, fileSize int64, uploadInfo *UploadInfo, progressCallback func(int64, int64)) (string, error) {
+	// 生成uptoken (基于文件特征: SHA1(sha1 + filename + filesize + slice_size))
+	upToken := api.UpToken(sha1Hash, fileName, fileSize, config.ChunkSize)
 
-	var selectResp struct {
-		Status int `json:"status"`
-		Data   struct {
-			UToken  string      `json:"utoken"`
-			Servers interface{} `json:"servers"`
-		} `json:"data"`
+	debugPrint(config, "生成uptoken: %s", upToken)
+	if err := journal.startSession(upToken); err != nil {
+		debugPrint(config, "保存续传记录失败: %v", err)
 	}
 
-	// 调试信息
-	fmt.Fprintf(os.Stderr, "upload_request_select2 响应: %s\n", string(body))
+	// 初始化续传跟踪器
+	resumeTracker := newResumeTracker(fileSize, journal, progressCallback)
 
-	if err := json.Unmarshal(body, &selectResp); err != nil {
-		return "", false, nil, fmt.Errorf("解析响应失败: %w", err)
+	// 根据文件大小和分片大小动态计算每个线程的最大循环次数
+	// 每个分片可能需要多次API调用（prepare + upload + 状态检查）
+	// 基础循环次数 = 分片数量 * 每个分片的平均API调用次数
+	chunkSize := int64(config.ChunkSize)
+	expectedChunks := (fileSize + chunkSize - 1) / chunkSize // 向上取整
+	maxLoops := int(expectedChunks) * 10                     // 每个分片最多允许10次循环（包括重试和状态检查）
+	if maxLoops < 10 {
+		maxLoops = 10
 	}
 
-	if selectResp.Status != 1 {
-		return "", false, nil, fmt.Errorf("获取上传服务器失败，状态码: %d", selectResp.Status)
+	// 并发数不超过分片数
+	concurrency := config.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if int64(concurrency) > expectedChunks && expectedChunks > 0 {
+		concurrency = int(expectedChunks)
 	}
 
-	// 解析servers字段
-	var uploadServer string
-	if selectResp.Data.Servers != nil {
-		if serverList, ok := selectResp.Data.Servers.([]interface{}); ok && len(serverList) > 0 {
-			// servers是对象数组，每个对象有url字段
-			if serverObj, ok := serverList[0].(map[string]interface{}); ok {
-				if serverURL, ok := serverObj["url"].(string); ok {
-					uploadServer = serverURL
-				}
+	debugPrint(config, "预计分片数: %d, 并发线程数: %d, 单线程最大循环次数: %d", expectedChunks, concurrency, maxLoops)
+	debugPrint(config, "开始分片上传状态机循环...")
+
+	// 任一线程得到最终结果或出错时取消其余线程，并等待所有线程和进度刷新退出后再返回，
+	// 之后不会再有进度回调改写已完成或失败的任务
+	workerCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	type workerResult struct {
+		downloadURL string
+		err         error
+	}
+	results := make(chan workerResult, concurrency)
+
+	prepareReq := api.SlicePrepareRequest{
+		UpToken:   upToken,
+		UToken:    uploadInfo.UToken,
+		SHA1:      sha1Hash,
+		Filename:  fileName,
+		Filesize:  fileSize,
+		SliceSize: config.ChunkSize,
+		MrID:      config.MrID,
+		Model:     config.Model,
+	}
+
+	// 等待服务器响应或状态2等待期间也定期刷新进度，使速度如实下降
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-workerCtx.Done():
+				return
+			case <-ticker.C:
+				resumeTracker.refresh()
 			}
 		}
-	}
-
-	// 检查是否用户强制指定了上传服务器
-	if config.UploadServer != "" {
-		uploadServer = config.UploadServer
-		fmt.Fprintf(os.Stderr, "使用用户指定的上传服务器: %s\n", uploadServer)
-	} else {
-		if uploadServer == "" {
-			// 如果没有找到有效的上传服务器，使用默认服务器
-			uploadServer = strings.TrimSuffix(config.Server, "/api_v2")
+	}()
+
+	for workerID := 1; workerID <= concurrency; workerID++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+			downloadURL, err := runSliceWorker(workerCtx, config, uploadInfo.Server, workerID, maxLoops, filePath, prepareReq, resumeTracker)
+			results <- workerResult{downloadURL: downloadURL, err: err}
+		}(workerID)
+	}
+
+	var firstErr error
+	for i := 0; i < concurrency; i++ {
+		result := <-results
+		if result.err == nil {
+			return result.downloadURL, nil
+		}
+		if firstErr == nil {
+			firstErr = result.err
+			cancel()
 		}
-		fmt.Fprintf(os.Stderr, "使用API分配的上传服务器: %s\n", uploadServer)
 	}
 
-	// 生成uptoken (基于文件特征: SHA1(sha1 + filename + filesize + slice_size))
-	upTokenData := fmt.Sprintf("%s%s%d%d", sha1Hash, filepath.Base(filePath), fileSize, config.ChunkSize)
-	upTokenHash := sha1.Sum([]byte(upTokenData))
-	upToken := hex.EncodeToString(upTokenHash[:])
+	return "", firstErr
+}
 
-	tokens := &UploadTokens{
-		UToken:       selectResp.Data.UToken,
-		UpToken:      upToken,
-		UploadServer: uploadServer,
+// runSliceWorker 单个上传线程：循环调用prepare获取待上传分片并上传，直到服务器返回最终状态
+func runSliceWorker(ctx context.Context, config *Config, server string, workerID, maxLoops int, filePath string, prepareReq api.SlicePrepareRequest, resumeTracker *ResumeTracker) (string, error) {
+	logf := func(format string, args ...interface{}) {
+		debugPrint(config, fmt.Sprintf("[线程%d] ", workerID)+format, args...)
 	}
 
-	// 第二步：prepare_v4 - 准备文件上传
-	prepareData := fmt.Sprintf("action=prepare_v4&sha1=%s&filename=%s&filesize=%d&model=%d&skip_upload=%d&token=%s",
-		sha1Hash, filepath.Base(filePath), fileSize, config.Model, config.SkipUpload, config.Token)
+	// 每个线程使用独立的日志前缀，共享底层HTTP连接池
+	client := *config.API
+	client.Logf = logf
 
-	if config.MrID != "" {
-		prepareData += "&mr_id=" + config.MrID
-	}
+	// 添加循环计数器，防止无限循环
+	loopCount := 0
 
-	req2, err := http.NewRequestWithContext(ctx, "POST", config.Server+"/file", strings.NewReader(prepareData))
-	if err != nil {
-		return "", false, nil, err
-	}
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		default:
+		}
 
-	req2.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		// 检查循环次数，防止无限循环
+		loopCount++
+		if loopCount > maxLoops {
+			return "", fmt.Errorf("上传超时，状态机循环次数过多（%d次）", loopCount)
+		}
 
-	resp2, err := client.Do(req2)
-	if err != nil {
-		return "", false, nil, err
-	}
-	defer resp2.Body.Close()
+		// 查询分片信息 (prepare)
+		logf("========== API请求 #%d ==========", loopCount)
+		// 网络错误、HTTP 5xx 和 429 按重试策略自动重试
+		var prepareResp *api.SlicePrepareResponse
+		err := config.Retry.Do(ctx, logf, func() error {
+			var err error
+			prepareResp, err = client.PrepareSlice(ctx, server, prepareReq)
+			return err
+		})
+		if err != nil {
+			var httpErr *api.HTTPError
+			if errors.As(err, &httpErr) || ctx.Err() != nil {
+				return "", err
+			}
+			return "", fmt.Errorf("网络请求失败: %w", err)
+		}
 
-	if resp2.StatusCode != http.StatusOK {
-		return "", false, nil, fmt.Errorf("HTTP请求失败: %d", resp2.StatusCode)
-	}
+		logf("解析结果 - 状态码: %d, 数据: %v, Debug: %v", prepareResp.Status, prepareResp.Data, prepareResp.Debug)
 
-	body2, err := io.ReadAll(resp2.Body)
-	if err != nil {
-		return "", false, nil, err
-	}
+		switch prepareResp.Status {
+		case 1:
+			// 上传完成
+			logf("状态1: 上传完成")
+			if ukey, ok := prepareResp.Data.(string); ok {
+				return config.API.DownloadURL(ukey), nil
+			}
+			return "", fmt.Errorf("无法获取ukey")
 
-	var prepareResp struct {
-		Status int         `json:"status"`
-		Data   interface{} `json:"data"`
-	}
+		case 6:
+			// 文件已被其他人上传，直接跳过
+			logf("状态6: 文件已存在，直接返回")
+			if ukey, ok := prepareResp.Data.(string); ok {
+				return config.API.DownloadURL(ukey), nil
+			}
+			return "", fmt.Errorf("无法获取ukey")
 
-	if err := json.Unmarshal(body2, &prepareResp); err != nil {
-		return "", false, nil, fmt.Errorf("解析响应失败: %w", err)
-	}
+		case 8:
+			// 分片合并完成 - 按照JavaScript逻辑直接成功
+			logf("状态8: 分片合并完成，上传成功")
+			if ukey, ok := prepareResp.Data.(string); ok {
+				return config.API.DownloadURL(ukey), nil
+			}
+			// 如果data是数字，也当作ukey处理
+			if ukeyNum, ok := prepareResp.Data.(float64); ok {
+				return config.API.DownloadURL(strconv.FormatInt(int64(ukeyNum), 10)), nil
+			}
+			return "", fmt.Errorf("无法获取ukey")
 
-	// 调试信息
-	fmt.Fprintf(os.Stderr, "prepare_v4 响应: %s\n", string(body2))
+		case 9:
+			// 文件合并进程正在进行中，按照JavaScript逻辑直接成功
+			logf("状态9: 合并进行中，按JS逻辑直接成功")
+			if ukey, ok := prepareResp.Data.(string); ok {
+				return config.API.DownloadURL(ukey), nil
+			}
+			// 如果没有ukey，等待一下再查询
+			logf("状态9: 没有ukey，等待2秒...")
+			if err := sleepWithContext(ctx, 2*time.Second); err != nil {
+				return "", err
+			}
+			continue
 
-	switch prepareResp.Status {
-	case 6, 8:
-		// 秒传成功
-		if dataMap, ok := prepareResp.Data.(map[string]interface{}); ok {
-			if ukey, exists := dataMap["ukey"].(string); exists {
-				return fmt.Sprintf("https://tmp.link/f/%s", ukey), false, nil, nil
+		case 2:
+			// 没有可上传分片（其余分片正由其他线程上传），等待分片完成
+			logf("状态2: 等待分片完成，等待5秒...")
+			if err := sleepWithContext(ctx, 5*time.Second); err != nil {
+				return "", err
 			}
-		}
-		return "", false, nil, fmt.Errorf("秒传响应格式错误")
+			continue
 
-	case 1:
-		// 需要分片上传
-		return "", true, tokens, nil
+		case 3:
+			// 获得一个需要上传的分片编号，开始处理上传
+			logf("状态3: 需要上传分片")
+			if sliceInfo, ok := prepareResp.SliceInfo(); ok {
+				uploadedSlices := sliceInfo.Total - sliceInfo.Wait
+				logf("总分片数: %d, 待上传分片数: %d, 已完成分片数: %d, 下一个分片编号: %d",
+					sliceInfo.Total, sliceInfo.Wait, uploadedSlices, sliceInfo.Next)
+
+				// 断点续传初始化 - 只根据第一次prepare响应判断
+				if resumeTracker.initResume(sliceInfo.Total, uploadedSlices, int64(config.ChunkSize)) {
+					progressPercent := float64(uploadedSlices) / float64(sliceInfo.Total) * 100
+					logf("🔄 检测到断点续传: 已完成 %d/%d 分片 (%.1f%%)",
+						uploadedSlices, sliceInfo.Total, progressPercent)
+				}
 
-	case 0:
-		// 状态0：需要分片上传（根据CLAUDE.md，这是正常状态）
-		return "", true, tokens, nil
+				// 检查是否有下一个分片需要上传
+				if nextSlice := sliceInfo.Next; nextSlice >= 0 {
+					// 服务器返回的分片已由其他线程上传中，稍后再查询
+					if !resumeTracker.claim(nextSlice) {
+						logf("分片 #%d 已由其他线程处理，等待1秒...", nextSlice)
+						if err := sleepWithContext(ctx, time.Second); err != nil {
+							return "", err
+						}
+						continue
+					}
 
-	default:
-		return "", false, nil, fmt.Errorf("准备上传失败，状态码: %d", prepareResp.Status)
-	}
-}
+					logf("上传分片 #%d", nextSlice)
 
-// uploadChunks 分片上传
-func uploadChunks(ctx context.Context, config *Config, filePath, sha1Hash string, fileSize int64, tokens *UploadTokens, progressCallback func(int64, int64)) (string, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return "", fmt.Errorf("打开文件失败: %w", err)
-	}
-	defer file.Close()
+					// 上传分片
+					err := uploadSlice(ctx, &client, config, server, filePath, prepareReq.Filename, prepareReq.UpToken, nextSlice, resumeTracker)
+					if err != nil {
+						resumeTracker.release(nextSlice)
+						return "", fmt.Errorf("分片 %d: %w", nextSlice, err)
+					}
+					logf("分片 #%d 上传完成", nextSlice)
 
-	client := &http.Client{}
-	chunkSize := int64(config.ChunkSize)
-	totalChunks := int((fileSize + chunkSize - 1) / chunkSize)
+					// 继续下一轮查询
+					continue
+				}
+			}
+			return "", fmt.Errorf("无法解析分片信息")
+
+		case 7:
+			// 按照JavaScript逻辑：rsp.data是错误代码，直接传递给upload_final
+			logf("状态7: 上传失败，错误代码: %v", prepareResp.Data)
+
+			// 检查是否是特殊情况：data为0、8或9（按照JavaScript逻辑直接成功）
+			if dataFloat, ok := prepareResp.Data.(float64); ok {
+				if dataFloat == 0 {
+					logf("状态7但data=0: 上传成功，按JavaScript逻辑直接成功")
+					// 根据debug信息构造下载链接
+					if debugMap, ok := prepareResp.Debug.(map[string]interface{}); ok {
+						if fileinfo, ok := debugMap["fileinfo"].(map[string]interface{}); ok {
+							if sha1, ok := fileinfo["sha1"].(string); ok {
+								return config.API.DownloadURL(sha1), nil
+							}
+						}
+					}
+					// 如果无法从debug获取，返回基于SHA1的链接
+					return config.API.DownloadURL("upload_success"), nil
+				} else if dataFloat == 8 {
+					logf("状态7但data=8: 合并完成，按JavaScript逻辑直接成功")
+					// 根据debug信息构造下载链接
+					if debugMap, ok := prepareResp.Debug.(map[string]interface{}); ok {
+						if fileinfo, ok := debugMap["fileinfo"].(map[string]interface{}); ok {
+							if sha1, ok := fileinfo["sha1"].(string); ok {
+								return config.API.DownloadURL(sha1), nil
+							}
+						}
+					}
+					// 如果无法从debug获取，返回基于SHA1的链接
+					return config.API.DownloadURL("upload_success"), nil
+				} else if dataFloat == 9 {
+					logf("状态7但data=9: 合并进行中，按JavaScript逻辑直接成功")
+					// 根据debug信息构造下载链接
+					if debugMap, ok := prepareResp.Debug.(map[string]interface{}); ok {
+						if fileinfo, ok := debugMap["fileinfo"].(map[string]interface{}); ok {
+							if sha1, ok := fileinfo["sha1"].(string); ok {
+								return config.API.DownloadURL(sha1), nil
+							}
+						}
+					}
+					// 如果无法从debug获取，返回基于SHA1的链接
+					return config.API.DownloadURL("upload_success"), nil
+				}
+			}
 
-	var uploadedBytes int64
+			// 获取详细错误信息
+			return "", newUploadError(prepareResp.Data)
 
-	// 逐个上传分片
-	for chunkIndex := 0; chunkIndex < totalChunks; chunkIndex++ {
-		select {
-		case <-ctx.Done():
-			return "", ctx.Err()
 		default:
-		}
-
-		// 读取分片数据
-		chunkData, err := readChunk(file, chunkIndex, chunkSize)
-		if err != nil {
-			return "", fmt.Errorf("读取分片 %d 失败: %w", chunkIndex, err)
-		}
-
-		// 上传分片
-		err = uploadChunk(ctx, client, config, chunkIndex, chunkData, sha1Hash, filepath.Base(filePath), tokens, fileSize)
-		if err != nil {
-			return "", fmt.Errorf("上传分片 %d 失败: %w", chunkIndex, err)
-		}
-
-		// 更新进度
-		uploadedBytes += int64(len(chunkData))
-		if progressCallback != nil {
-			progressCallback(uploadedBytes, fileSize)
+			logf("未知状态码: %d", prepareResp.Status)
+			return "", &UploadError{
+				Kind:    ErrKindServer,
+				Code:    prepareResp.Status,
+				Message: fmt.Sprintf("未知状态码: %d", prepareResp.Status),
+			}
 		}
 	}
-
-	// 所有分片上传完成，通过再次调用prepare来获取最终状态和下载链接
-	return getFinalResult(ctx, client, config, sha1Hash, filepath.Base(filePath), fileSize, tokens)
 }
 
-// readChunk 读取分片数据
-func readChunk(file *os.File, chunkIndex int, chunkSize int64) ([]byte, error) {
-	offset := int64(chunkIndex) * chunkSize
-	_, err := file.Seek(offset, 0)
+// uploadSlice 上传单个分片，支持续传进度计算
+func uploadSlice(ctx context.Context, client *api.Client, config *Config, server, filePath, fileName, upToken string, sliceIndex int, resumeTracker *ResumeTracker) error {
+	// 打开文件
+	file, err := os.Open(filePath)
 	if err != nil {
-		return nil, err
-	}
-
-	buffer := make([]byte, chunkSize)
-	n, err := file.Read(buffer)
-	if err != nil && err != io.EOF {
-		return nil, err
+		return fmt.Errorf("打开文件失败: %w", err)
 	}
+	defer file.Close()
 
-	return buffer[:n], nil
-}
-
-// uploadChunk 上传单个分片
-func uploadChunk(ctx context.Context, client *http.Client, config *Config, chunkIndex int, chunkData []byte, sha1Hash, fileName string, tokens *UploadTokens, totalFileSize int64) error {
-	// 第一步：查询分片信息 (prepare) - 包含所有必需参数
-	prepareData := fmt.Sprintf("token=%s&uptoken=%s&action=prepare&sha1=%s&filename=%s&filesize=%d&slice_size=%d&utoken=%s&mr_id=%s&model=%d",
-		config.Token, tokens.UpToken, sha1Hash, fileName, totalFileSize, config.ChunkSize, tokens.UToken, config.MrID, config.Model)
-
-	// API参数已准备
-
-	// 使用tokens中的上传服务器地址
-	req, err := http.NewRequestWithContext(ctx, "POST", tokens.UploadServer+"/app/upload_slice", strings.NewReader(prepareData))
-	if err != nil {
-		return err
+	// 计算分片偏移和大小（最后一个分片可能不足chunkSize）
+	chunkSize := int64(config.ChunkSize)
+	offset := int64(sliceIndex) * chunkSize
+	sliceSize := sliceLength(resumeTracker.fileSize, chunkSize, sliceIndex)
+	if sliceSize < 0 {
+		return fmt.Errorf("分片 %d 超出文件范围", sliceIndex)
 	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := client.Do(req)
-	if err != nil {
+	debugPrint(config, "========== 上传分片 #%d ==========", sliceIndex)
+	debugPrint(config, "分片大小: %d bytes", sliceSize)
+
+	// 发送上传请求，网络错误、HTTP 5xx 和 429 按重试策略自动重试
+	var uploadResult *api.SliceUploadResponse
+	err = config.Retry.Do(ctx, func(format string, args ...interface{}) {
+		debugPrint(config, fmt.Sprintf("分片 #%d ", sliceIndex)+format, args...)
+	}, func() error {
+		var err error
+		// 每次请求都从磁盘重新读取分片，不在内存中缓存
+		uploadResult, err = client.UploadSlice(ctx, server, api.SliceUploadRequest{
+			UpToken:  upToken,
+			Filename: fileName,
+			Index:    sliceIndex,
+			Data:     io.NewSectionReader(file, offset, sliceSize),
+			Size:     sliceSize,
+			Progress: func(sent int64) {
+				resumeTracker.progress(sliceIndex, sent)
+			},
+		})
 		return err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	})
 	if err != nil {
+		debugPrint(config, "分片上传请求失败: %v", err)
 		return err
 	}
 
-	// 调试信息
-	fmt.Fprintf(os.Stderr, "分片prepare响应: %s\n", string(body))
-
-	var prepareResp struct {
-		Status int `json:"status"`
-		Data   struct {
-			Next int `json:"next"`
-		} `json:"data"`
-	}
-
-	if err := json.Unmarshal(body, &prepareResp); err != nil {
-		return fmt.Errorf("解析prepare响应失败: %w", err)
-	}
+	debugPrint(config, "解析结果 - 状态码: %d", uploadResult.Status)
 
-	// 检查是否需要上传此分片
-	if prepareResp.Status == 1 {
-		return nil // 上传完成
+	// 根据JavaScript代码，状态5表示分片上传完成
+	if !uploadResult.Accepted() {
+		debugPrint(config, "分片上传失败，状态码: %d", uploadResult.Status)
+		// 为分片上传失败提供更详细的错误信息
+		uploadErr := &UploadError{Kind: ErrKindServer, Code: uploadResult.Status}
+		switch uploadResult.Status {
+		case 7:
+			uploadErr.Kind, uploadErr.Message = ErrKindAuth, "认证错误或权限不足，请检查Token是否有效"
+		case 4:
+			uploadErr.Kind, uploadErr.Message = ErrKindFile, "分片大小超出限制"
+		case 6:
+			uploadErr.Kind, uploadErr.Message = ErrKindDestination, "没有权限上传到指定位置"
+		default:
+			uploadErr.Message = fmt.Sprintf("分片上传失败，状态码: %d", uploadResult.Status)
+		}
+		return uploadErr
 	}
 
-	if prepareResp.Status != 3 || prepareResp.Data.Next != chunkIndex {
-		return fmt.Errorf("分片prepare失败，状态码: %d, 期待分片: %d, 实际: %d", prepareResp.Status, chunkIndex, prepareResp.Data.Next)
-	}
+	debugPrint(config, "分片 #%d 上传成功", sliceIndex)
 
-	// 第二步：上传分片数据
-	var buf bytes.Buffer
-	writer := multipart.NewWriter(&buf)
+	// 更新进度（支持续传和乱序完成）：基于已确认分片的实际字节数计算
+	totalUploadedBytes := resumeTracker.ack(sliceIndex, sliceSize)
+	debugPrint(config, "进度更新: 分片#%d完成, 总进度: %d/%d bytes",
+		sliceIndex, totalUploadedBytes, resumeTracker.fileSize)
 
-	// 添加文件数据
-	fileWriter, err := writer.CreateFormFile("filedata", "slice")
-	if err != nil {
-		return err
-	}
-	fileWriter.Write(chunkData)
+	return nil
+}
 
-	// 添加表单字段
-	writer.WriteField("uptoken", tokens.UpToken)
-	writer.WriteField("filename", fileName)
-	writer.WriteField("index", fmt.Sprintf("%d", chunkIndex))
-	writer.WriteField("action", "upload_slice")
-	writer.Close()
+// UploadInfo 上传信息
+type UploadInfo struct {
+	UToken string
+	Server string
+}
 
-	uploadReq, err := http.NewRequestWithContext(ctx, "POST", tokens.UploadServer+"/app/upload_slice", &buf)
-	if err != nil {
-		return err
+// newAPIClient 根据上传配置创建API客户端，调试模式下输出请求日志
+func newAPIClient(config *Config) *api.Client {
+	client := api.NewClient(config.Token)
+	client.BaseURL = config.Server
+	if config.LinkBase != "" {
+		client.LinkBase = config.LinkBase
 	}
-	uploadReq.Header.Set("Content-Type", writer.FormDataContentType())
-
-	uploadResp, err := client.Do(uploadReq)
-	if err != nil {
-		return err
+	client.UserAgent = fmt.Sprintf("tmplink-cli/%s", Version)
+	client.Logf = func(format string, args ...interface{}) {
+		debugPrint(config, format, args...)
 	}
-	defer uploadResp.Body.Close()
+	return client
+}
 
-	if uploadResp.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP请求失败: %d", uploadResp.StatusCode)
-	}
+// getUTokenOnly 仅获取UToken（GUI模式使用）
+func getUTokenOnly(ctx context.Context, config *Config, sha1Hash, fileName string, fileSize int64) (*UploadInfo, error) {
+	debugPrint(config, "========== 获取UToken API ==========")
 
-	uploadBody, err := io.ReadAll(uploadResp.Body)
+	selectResp, err := config.API.UploadRequestSelect(ctx, api.UploadSelectRequest{
+		SHA1:     sha1Hash,
+		Filename: fileName,
+		Filesize: fileSize,
+		Model:    config.Model,
+	})
 	if err != nil {
-		return err
-	}
-
-	var uploadResult struct {
-		Status int `json:"status"`
+		return nil, err
 	}
 
-	if err := json.Unmarshal(uploadBody, &uploadResult); err != nil {
-		return fmt.Errorf("解析上传响应失败: %w", err)
-	}
+	debugPrint(config, "解析结果 - 状态码: %d, UToken: %s", selectResp.Status, credential.Redact(selectResp.UToken))
 
-	if uploadResult.Status != 1 && uploadResult.Status != 2 && uploadResult.Status != 3 && uploadResult.Status != 5 {
-		return fmt.Errorf("分片上传失败，状态码: %d", uploadResult.Status)
+	if selectResp.Status != 1 {
+		debugPrint(config, "API返回错误状态: %d", selectResp.Status)
+		uploadErr := &UploadError{Kind: ErrKindServer, Code: selectResp.Status}
+		switch selectResp.Status {
+		case 2:
+			uploadErr.Kind, uploadErr.Message = ErrKindAuth, "获取UToken失败: Token无效或已过期"
+		case 3:
+			uploadErr.Kind, uploadErr.Message = ErrKindAuth, "获取UToken失败: 用户权限不足"
+		case 0:
+			uploadErr.Message = "获取UToken失败: 请求参数错误"
+		default:
+			uploadErr.Message = fmt.Sprintf("获取UToken失败，状态码: %d", selectResp.Status)
+		}
+		return nil, uploadErr
 	}
 
-	return nil
+	return &UploadInfo{
+		UToken: selectResp.UToken,
+		Server: "", // 在调用方设置
+	}, nil
 }
 
-// getFinalResult 获取最终上传结果
-func getFinalResult(ctx context.Context, client *http.Client, config *Config, sha1Hash, fileName string, fileSize int64, tokens *UploadTokens) (string, error) {
-	// 调用prepare检查最终状态
-	prepareData := fmt.Sprintf("token=%s&uptoken=%s&action=prepare&sha1=%s&filename=%s&filesize=%d&slice_size=%d&utoken=%s&mr_id=%s&model=%d",
-		config.Token, tokens.UpToken, sha1Hash, fileName, fileSize, config.ChunkSize, tokens.UToken, config.MrID, config.Model)
+// getUploadServers 获取上传服务器列表
+func getUploadServers(ctx context.Context, config *Config, sha1Hash, fileName string, fileSize int64) (*UploadInfo, error) {
+	debugPrint(config, "========== 获取上传服务器API ==========")
 
-	req, err := http.NewRequestWithContext(ctx, "POST", tokens.UploadServer+"/app/upload_slice", strings.NewReader(prepareData))
+	selectResp, err := config.API.UploadRequestSelect(ctx, api.UploadSelectRequest{
+		SHA1:     sha1Hash,
+		Filename: fileName,
+		Filesize: fileSize,
+		Model:    config.Model,
+	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
+	debugPrint(config, "解析结果 - 状态码: %d, UToken: %s, Servers: %v", selectResp.Status, credential.Redact(selectResp.UToken), selectResp.Servers)
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
+	if selectResp.Status != 1 {
+		debugPrint(config, "API返回错误状态: %d", selectResp.Status)
+		uploadErr := &UploadError{Kind: ErrKindServer, Code: selectResp.Status}
+		switch selectResp.Status {
+		case 2:
+			uploadErr.Kind, uploadErr.Message = ErrKindAuth, "获取上传服务器失败: Token无效或已过期"
+		case 3:
+			uploadErr.Kind, uploadErr.Message = ErrKindAuth, "获取上传服务器失败: 用户权限不足"
+		case 0:
+			uploadErr.Message = "获取上传服务器失败: 请求参数错误"
+		default:
+			uploadErr.Message = fmt.Sprintf("获取上传服务器失败，状态码: %d", selectResp.Status)
+		}
+		return nil, uploadErr
 	}
 
-	// 调试信息
-	fmt.Fprintf(os.Stderr, "最终状态检查响应: %s\n", string(body))
-
-	var finalResp struct {
-		Status int         `json:"status"`
-		Data   interface{} `json:"data"`
+	// 使用第一个可用的上传服务器
+	if len(selectResp.Servers) == 0 {
+		return nil, fmt.Errorf("无法获取上传服务器地址")
 	}
+	uploadServer := selectResp.Servers[0].URL
 
-	if err := json.Unmarshal(body, &finalResp); err != nil {
-		return "", fmt.Errorf("解析最终响应失败: %w", err)
+	// 检查是否用户强制指定了上传服务器
+	if config.UploadServer != "" {
+		debugPrint(config, "使用用户指定的上传服务器: %s", config.UploadServer)
+		uploadServer = config.UploadServer
 	}
 
-	switch finalResp.Status {
-	case 1, 6, 8, 9:
-		// 上传完成，从data中获取ukey
-		if dataMap, ok := finalResp.Data.(map[string]interface{}); ok {
-			if ukey, exists := dataMap["ukey"].(string); exists {
-				return fmt.Sprintf("https://tmp.link/f/%s", ukey), nil
-			}
-		}
-		// 如果data是字符串（某些情况下）
-		if ukey, ok := finalResp.Data.(string); ok && ukey != "" {
-			return fmt.Sprintf("https://tmp.link/f/%s", ukey), nil
-		}
-		return "", fmt.Errorf("无法从响应中获取下载链接")
-	default:
-		return "", fmt.Errorf("上传未完成，状态码: %d", finalResp.Status)
-	}
+	return &UploadInfo{
+		UToken: selectResp.UToken,
+		Server: uploadServer,
+	}, nil
 }
 
-// getDownloadURL 获取下载链接（已弃用）
-func getDownloadURL(ctx context.Context, client *http.Client, config *Config, sha1Hash, fileName string, fileSize int64) (string, error) {
-	// 构造完成请求
-	formData := fmt.Sprintf("action=upload_complete&sha1=%s&filename=%s&filesize=%d&token=%s",
-		sha1Hash, fileName, fileSize, config.Token)
+// checkQuickUpload 检查是否可以秒传
+func checkQuickUpload(ctx context.Context, config *Config, sha1Hash, fileName string, fileSize int64) (string, bool, error) {
+	debugPrint(config, "========== 检查秒传API ==========")
 
-	req, err := http.NewRequestWithContext(ctx, "POST", config.Server+"/file", strings.NewReader(formData))
+	prepareResp, err := config.API.PrepareV4(ctx, api.PrepareV4Request{
+		SHA1:       sha1Hash,
+		Filename:   fileName,
+		Filesize:   fileSize,
+		Model:      config.Model,
+		SkipUpload: config.SkipUpload,
+	})
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
 
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	debugPrint(config, "解析结果 - 状态码: %d, 数据: %v", prepareResp.Status, prepareResp.Data)
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
+	switch prepareResp.Status {
+	case 6, 8:
+		// 秒传成功
+		if ukey := prepareResp.UKey(); ukey != "" {
+			return config.API.DownloadURL(ukey), false, nil
+		}
+		return "", false, fmt.Errorf("秒传响应格式错误")
+	case 1:
+		// 需要分片上传
+		return "", true, nil
+	case 0:
+		// 需要分片上传
+		return "", true, nil
+	default:
+		return "", false, &UploadError{
+			Kind:    ErrKindServer,
+			Code:    prepareResp.Status,
+			Message: fmt.Sprintf("准备上传失败，状态码: %d", prepareResp.Status),
+		}
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP请求失败: %d", resp.StatusCode)
-	}
+// validateTokenAndGetUID 验证token并获取用户UID
+func validateTokenAndGetUID(ctx context.Context, token, server string) (string, error) {
+	client := api.NewClient(token)
+	client.BaseURL = server
+	client.UserAgent = fmt.Sprintf("tmplink-cli/%s", Version)
 
-	body, err := io.ReadAll(resp.Body)
+	// 调用/user API验证token并获取用户信息
+	detailResp, err := client.GetDetail(ctx)
 	if err != nil {
 		return "", err
 	}
 
-	// 调试信息
-	fmt.Fprintf(os.Stderr, "upload_complete 响应: %s\n", string(body))
+	if detailResp.Status != 1 {
+		// Token无效时，data可能是字符串错误信息
+		uploadErr := &UploadError{Kind: ErrKindServer, Code: detailResp.Status}
+		switch detailResp.Status {
+		case 2:
+			uploadErr.Kind, uploadErr.Message = ErrKindAuth, "Token无效或已过期，请重新获取API Token"
+		case 3:
+			uploadErr.Kind, uploadErr.Message = ErrKindAuth, "用户账号被禁用"
+		case 0:
+			uploadErr.Message = "请求参数错误"
+		default:
+			uploadErr.Message = fmt.Sprintf("验证失败，状态码: %d", detailResp.Status)
+		}
 
-	var completeResp struct {
-		Status int         `json:"status"`
-		Data   interface{} `json:"data"`
-	}
+		// 如果data是字符串，追加详细错误信息
+		if detailResp.ErrorText != "" {
+			uploadErr.Message += fmt.Sprintf(" (%s)", detailResp.ErrorText)
+		}
 
-	if err := json.Unmarshal(body, &completeResp); err != nil {
-		return "", fmt.Errorf("解析完成响应失败: %w", err)
+		return "", uploadErr
 	}
 
-	if completeResp.Status == 1 {
-		if dataMap, ok := completeResp.Data.(map[string]interface{}); ok {
-			if ukey, exists := dataMap["ukey"].(string); exists {
-				return fmt.Sprintf("https://tmp.link/f/%s", ukey), nil
-			}
-		}
+	if detailResp.Detail.UID > 0 {
+		return fmt.Sprintf("%d", detailResp.Detail.UID), nil
 	}
 
-	return "", fmt.Errorf("获取下载链接失败，状态码: %d", completeResp.Status)
+	return "", fmt.Errorf("无法获取用户UID")
 }
 
 // clearProgressBar 清除进度条残留和开始信息
 func clearProgressBar() {
 	// 清除我们输出的内容：进度条 + 文件大小行 + 开始上传行（共3行）
-	fmt.Print("\r\033[K")      // 清除当前行（进度条）
-	fmt.Print("\033[1A\033[K") // 向上移动一行并清除（文件大小行）
-	fmt.Print("\033[1A\033[K") // 向上移动一行并清除（开始上传行）
+	fmt.Fprint(humanOut, "\r\033[K")      // 清除当前行（进度条）
+	fmt.Fprint(humanOut, "\033[1A\033[K") // 向上移动一行并清除（文件大小行）
+	fmt.Fprint(humanOut, "\033[1A\033[K") // 向上移动一行并清除（开始上传行）
 	// 现在光标在开始上传行的位置，准备输出完成信息
 }
 
+// statusSaveInterval 上传过程中写入状态文件的最短间隔
+const statusSaveInterval = 250 * time.Millisecond
+
+// createHashProgressCallback 创建SHA1计算进度回调函数，计算完成后任务进入上传阶段
+func createHashProgressCallback(cliMode bool, shouldSaveStatus bool, task *TaskStatus, statusFile string, out *reporter) func(int64, int64) {
+	var bar *progressbar.ProgressBar
+	var lastSave time.Time
+	done := false
+
+	return func(hashed, total int64) {
+		if done {
+			return
+		}
+		// total < 0 表示流式输入，读取完成前总大小未知
+		done = total >= 0 && hashed >= total
+
+		switch {
+		case total > 0:
+			task.HashProgress = float64(hashed) / float64(total) * 100
+		case total == 0:
+			task.HashProgress = 100
+		}
+		task.UpdatedAt = time.Now()
+		if done {
+			task.Status = "uploading"
+			task.FileSize = total
+		}
+
+		// CLI模式：大文件计算时间较长，显示计算进度，完成后清除该行供上传进度条使用
+		if cliMode {
+			if bar == nil && !done {
+				bar = progressbar.NewOptions64(
+					total,
+					progressbar.OptionSetDescription("🔐 计算SHA1"),
+					progressbar.OptionSetWidth(getProgressBarWidth()),
+					progressbar.OptionSetWriter(humanOut),
+					progressbar.OptionShowBytes(true),
+					progressbar.OptionSetTheme(progressbar.Theme{
+						Saucer:        "█",
+						SaucerHead:    "█",
+						SaucerPadding: "░",
+						BarStart:      "[",
+						BarEnd:        "]",
+					}),
+					progressbar.OptionShowDescriptionAtLineEnd(),
+				)
+			}
+			if bar != nil {
+				bar.Set64(hashed)
+				if done {
+					fmt.Fprint(humanOut, "\r\033[K")
+				}
+			}
+		}
+
+		if done || time.Since(lastSave) >= statusSaveInterval {
+			lastSave = time.Now()
+			out.hashing(task, hashed, total)
+			if shouldSaveStatus {
+				if err := saveTaskStatus(statusFile, task); err != nil {
+					fmt.Fprintf(os.Stderr, "警告: 保存进度失败: %v\n", err)
+				}
+			}
+		}
+	}
+}
+
 // createProgressCallback 创建进度回调函数
-func createProgressCallback(cliMode bool, shouldSaveStatus bool, fileSize int64, speedCalc *SpeedCalculator, task *TaskStatus, statusFile string) func(int64, int64) {
+func createProgressCallback(cliMode bool, shouldSaveStatus bool, fileSize int64, speedCalc *SpeedCalculator, task *TaskStatus, statusFile string, out *reporter) func(int64, int64) {
 	var bar *progressbar.ProgressBar
+	var lastSave, lastEmit time.Time
+	lastEmitted := int64(-1)
 
 	// 如果是CLI模式，只显示开始信息，不立即创建进度条
 	if cliMode {
-		fmt.Printf("🚀 开始上传文件: %s\n", task.FileName)
-		fmt.Printf("📊 文件大小: %s\n", formatBytes(fileSize))
+		fmt.Fprintf(humanOut, "🚀 开始上传文件: %s\n", task.FileName)
+		if fileSize < 0 {
+			fmt.Fprintf(humanOut, "📊 文件大小: 未知（流式输入，读取完成后确定）\n")
+		} else {
+			fmt.Fprintf(humanOut, "📊 文件大小: %s\n", formatBytes(fileSize))
+		}
 	}
 
 	return func(uploaded, total int64) {
@@ -1927,6 +2626,7 @@ func createProgressCallback(cliMode bool, shouldSaveStatus bool, fileSize int64,
 					total,
 					progressbar.OptionSetDescription("📤 上传中"),
 					progressbar.OptionSetWidth(getProgressBarWidth()),
+					progressbar.OptionSetWriter(humanOut),
 					progressbar.OptionShowBytes(true),
 					progressbar.OptionSetTheme(progressbar.Theme{
 						Saucer:        "█",
@@ -1944,8 +2644,15 @@ func createProgressCallback(cliMode bool, shouldSaveStatus bool, fileSize int64,
 			bar.Set64(uploaded)
 		}
 
-		// 保存进度状态到文件
-		if shouldSaveStatus {
+		// ndjson模式：输出进度事件（限制输出频率）
+		if uploaded != lastEmitted && (uploaded >= total || time.Since(lastEmit) >= statusSaveInterval) {
+			lastEmit, lastEmitted = time.Now(), uploaded
+			out.progress(task, uploaded, total)
+		}
+
+		// 保存进度状态到文件（进度按字节回调，限制写文件频率）
+		if shouldSaveStatus && (uploaded >= total || time.Since(lastSave) >= statusSaveInterval) {
+			lastSave = time.Now()
 			if err := saveTaskStatus(statusFile, task); err != nil {
 				fmt.Fprintf(os.Stderr, "警告: 保存进度失败: %v\n", err)
 			}
@@ -1955,40 +2662,24 @@ func createProgressCallback(cliMode bool, shouldSaveStatus bool, fileSize int64,
 
 // showConfigStatus 显示当前配置状态和token有效性
 func showConfigStatus() {
+	// 加载配置
+	settings := loadSettings()
+	saved := settings.User
+	profile := settings.Resolve(config.SettingProfile, config.DefaultProfileName)
+	resolved, err := resolveUploadSettings(settings)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		os.Exit(exitFailure)
+	}
+
 	fmt.Println("=== 钛盘上传工具配置状态 ===")
 	fmt.Println()
+	configPath := config.Path()
 
-	// 加载配置
-	config := loadSharedConfig()
-	configPath := getSharedConfigPath()
-
-	// 获取已解析的命令行参数值
-	chunkSizeFlag := flag.Lookup("chunk-size")
-	modelFlag := flag.Lookup("model")
-	mrIDFlag := flag.Lookup("mr-id")
+	// 未参与分层配置的运行参数
 	skipUploadFlag := flag.Lookup("skip-upload")
 	debugFlag := flag.Lookup("debug")
 
-	// 确定最终使用的值（命令行参数优先级高于配置文件）
-	var finalChunkSize int = 3
-	if chunkSizeFlag != nil {
-		if val, err := strconv.Atoi(chunkSizeFlag.Value.String()); err == nil {
-			finalChunkSize = val
-		}
-	}
-
-	var finalModel int = config.Model
-	if modelFlag != nil && isFlagSet(modelFlag) {
-		if val, err := strconv.Atoi(modelFlag.Value.String()); err == nil {
-			finalModel = val
-		}
-	}
-
-	var finalMrID string = config.MrID
-	if mrIDFlag != nil && isFlagSet(mrIDFlag) {
-		finalMrID = mrIDFlag.Value.String()
-	}
-
 	var finalSkipUpload int = 1
 	if skipUploadFlag != nil {
 		if val, err := strconv.Atoi(skipUploadFlag.Value.String()); err == nil {
@@ -2010,22 +2701,32 @@ func showConfigStatus() {
 	} else {
 		fmt.Printf("✅ 配置文件状态: 存在\n")
 	}
+	if settings.Project != nil {
+		fmt.Printf("📄 项目配置: %s\n", settings.Project.Path)
+	} else {
+		fmt.Printf("📄 项目配置: 未找到 (从当前目录向上查找 %s)\n", config.ProjectFileName)
+	}
+	fmt.Printf("👤 账号配置: %s", config.ProfileName(saved.ActiveProfile()))
+	if len(saved.Profiles) > 0 {
+		fmt.Printf(" (共 %d 个，使用 -list-profiles 查看)", len(saved.Profiles)+1)
+	}
+	fmt.Printf(" ← %s\n", profile.Origin)
 	fmt.Println()
 
 	// 显示Token信息
 	fmt.Println("🔑 Token配置:")
-	if config.Token == "" {
+	fmt.Printf("   存储: %s\n", credentialStore.Describe())
+	if resolved.token == "" {
 		fmt.Printf("   状态: ❌ 未设置\n")
 		fmt.Printf("   建议: 使用 -set-token 命令设置API Token\n")
 	} else {
-		fmt.Printf("   状态: ✅ 已设置\n")
-		fmt.Printf("   长度: %d 字符\n", len(config.Token))
-		fmt.Printf("   前缀: %s...\n", config.Token[:min(8, len(config.Token))])
+		fmt.Printf("   状态: ✅ 已设置 ← %s\n", resolved.values[config.SettingToken].Origin)
+		fmt.Printf("   长度: %d 字符\n", len(resolved.token))
+		fmt.Printf("   前缀: %s\n", credential.Redact(resolved.token))
 
 		// 验证Token有效性
 		fmt.Printf("   验证: ")
-		server := "https://tmplink-sec.vxtrans.com/api_v2"
-		if uid, err := validateTokenAndGetUID(config.Token, server); err != nil {
+		if uid, err := validateTokenAndGetUID(context.Background(), resolved.token, resolved.apiServer); err != nil {
 			fmt.Printf("❌ 无效 (%v)\n", err)
 		} else {
 			fmt.Printf("✅ 有效 (UID: %s)\n", uid)
@@ -2033,16 +2734,35 @@ func showConfigStatus() {
 	}
 	fmt.Println()
 
-	// 显示其他配置
-	fmt.Println("⚙️ 其他配置:")
+	// 显示生效的设置及其来源
+	fmt.Println("⚙️ 生效的设置 (命令行参数 > 环境变量 > 项目配置 > 用户配置 > 默认值):")
 	modelDesc := map[int]string{0: "24小时", 1: "3天", 2: "7天", 99: "无限期"}
-	fmt.Printf("   文件有效期: %s (%d)\n", modelDesc[finalModel], finalModel)
-	fmt.Printf("   目录ID: %s\n", finalMrID)
+	printSetting := func(label, value string, setting config.Setting) {
+		fmt.Printf("   %s: %s ← %s\n", label, value, resolved.values[setting].Origin)
+	}
+	printSetting("文件有效期", fmt.Sprintf("%s (%d)", modelDesc[resolved.model], resolved.model), config.SettingModel)
+	if resolved.folder != "" {
+		printSetting("目标文件夹", resolved.folder, config.SettingFolder)
+	} else {
+		printSetting("目录ID", resolved.mrID, config.SettingMrID)
+	}
+	if resolved.uploadServer != "" {
+		server := resolved.uploadServer
+		if resolved.serverName != "" {
+			server = fmt.Sprintf("%s (%s)", resolved.serverName, resolved.uploadServer)
+		}
+		printSetting("上传服务器", server, config.SettingUploadServer)
+	} else {
+		printSetting("上传服务器", "自动选择", config.SettingUploadServer)
+	}
+	printSetting("分块大小", fmt.Sprintf("%dMB", resolved.chunkSizeMB), config.SettingChunkSize)
+	printSetting("并发分片数", fmt.Sprintf("%d", resolved.concurrency), config.SettingConcurrency)
+	printSetting("API服务器", resolved.apiServer, config.SettingAPIServer)
+	printSetting("下载链接前缀", resolved.linkBase, config.SettingLinkBase)
 	fmt.Println()
 
 	// 显示当前运行参数
 	fmt.Println("🔧 当前运行参数:")
-	fmt.Printf("   分块大小: %dMB\n", finalChunkSize)
 	fmt.Printf("   跳过上传: %d (%s)\n", finalSkipUpload, map[int]string{0: "禁用秒传检查", 1: "启用秒传检查"}[finalSkipUpload])
 
 	debugStatus := "关闭"
@@ -2053,7 +2773,7 @@ func showConfigStatus() {
 	fmt.Println()
 
 	// 显示使用建议
-	if config.Token == "" {
+	if resolved.token == "" {
 		fmt.Println("💡 下一步建议:")
 		fmt.Println("   1. 访问 https://tmp.link/ 并登录")
 		fmt.Println("   2. 在上传界面点击'重新设定' -> '命令行上传'复制Token")
@@ -2087,6 +2807,94 @@ func formatBytes(bytes int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
+// ErrorKind 错误类别，决定任务状态中的 error_type 和CLI退出码
+type ErrorKind string
+
+const (
+	ErrKindAuth        ErrorKind = "auth"        // Token无效、过期或账号权限不足
+	ErrKindQuota       ErrorKind = "quota"       // 超出单日上传量或私有存储空间
+	ErrKindDestination ErrorKind = "destination" // 目标文件夹不存在或没有上传权限
+	ErrKindFile        ErrorKind = "file"        // 空文件、文件过大或文件名不合法
+	ErrKindNetwork     ErrorKind = "network"     // 网络错误或HTTP错误（已按重试策略重试）
+	ErrKindServer      ErrorKind = "server"      // 服务器返回了无法识别的状态
+)
+
+// exitCode 返回错误类别对应的退出码
+func (k ErrorKind) exitCode() int {
+	switch k {
+	case ErrKindAuth:
+		return exitAuth
+	case ErrKindQuota:
+		return exitQuota
+	case ErrKindDestination:
+		return exitDestination
+	case ErrKindFile:
+		return exitFile
+	case ErrKindNetwork:
+		return exitNetwork
+	case ErrKindServer:
+		return exitServer
+	default:
+		return exitFailure
+	}
+}
+
+// UploadError 服务器拒绝请求时返回的错误，Code 为服务器返回的错误代码或状态码
+type UploadError struct {
+	Kind    ErrorKind
+	Code    int
+	Message string
+}
+
+func (e *UploadError) Error() string {
+	return e.Message
+}
+
+// newUploadError 根据prepare状态7返回的data创建上传错误
+func newUploadError(data interface{}) error {
+	uploadErr := &UploadError{Kind: ErrKindServer, Message: getUploadErrorMessage(data)}
+	switch v := data.(type) {
+	case float64:
+		uploadErr.Code = int(v)
+	case int:
+		uploadErr.Code = v
+	default:
+		return uploadErr
+	}
+	uploadErr.Kind = uploadErrorKind(uploadErr.Code)
+	return uploadErr
+}
+
+// uploadErrorKind 返回prepare错误代码所属的错误类别，错误代码含义见 getUploadErrorMessage
+func uploadErrorKind(code int) ErrorKind {
+	switch code {
+	case 2:
+		return ErrKindAuth
+	case 3, 4, 10:
+		return ErrKindFile
+	case 5, 7:
+		return ErrKindQuota
+	case 6, 8:
+		return ErrKindDestination
+	case 9:
+		return ErrKindNetwork
+	default:
+		return ErrKindServer
+	}
+}
+
+// classifyError 返回错误的类别和服务器错误代码，无法归类的错误（如本地文件读取失败）返回空类别
+func classifyError(err error) (ErrorKind, int) {
+	var uploadErr *UploadError
+	if errors.As(err, &uploadErr) {
+		return uploadErr.Kind, uploadErr.Code
+	}
+	if api.IsNetworkError(err) {
+		return ErrKindNetwork, 0
+	}
+	return "", 0
+}
+
 // getUploadErrorMessage 根据错误代码返回具体的错误信息
 func getUploadErrorMessage(data interface{}) string {
 	// 尝试将data转换为具体的错误代码
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestEntry 上传清单中的单个文件，未设置的字段使用命令行参数或已保存的默认值
+type ManifestEntry struct {
+	File  string   `json:"file" yaml:"file"`                       // 文件路径，相对路径以清单所在目录为基准
+	Name  string   `json:"name,omitempty" yaml:"name,omitempty"`   // 上传后的文件名
+	Model *int     `json:"model,omitempty" yaml:"model,omitempty"` // 文件有效期
+	MrID  string   `json:"mr_id,omitempty" yaml:"mr_id,omitempty"` // 目录ID
+	Tags  []string `json:"tags,omitempty" yaml:"tags,omitempty"`   // 标签，不发送到服务器，原样写入结果清单
+}
+
+// ManifestResult 结果清单中的单个文件，上传结果字段与 -output json 相同
+type ManifestResult struct {
+	FileReport
+	Model      int        `json:"model"`
+	MrID       string     `json:"mr_id"`
+	Tags       []string   `json:"tags,omitempty"`
+	ModTime    time.Time  `json:"mod_time"`              // 上传时文件的修改时间，文件变化后重新上传
+	FinishedAt *time.Time `json:"finished_at,omitempty"` // 上传结束时间
+}
+
+// key 条目的唯一标识：同一文件可以用不同的文件名、有效期或目录各上传一次
+func (r ManifestResult) key() string {
+	return fmt.Sprintf("%s|%s|%d|%s", r.FilePath, r.FileName, r.Model, r.MrID)
+}
+
+// manifestResults 结果清单文件，每个文件结束后立即写入，重新运行时跳过已完成的条目
+type manifestResults struct {
+	mu   sync.Mutex
+	path string
+
+	SchemaVersion int              `json:"schema_version"`
+	Manifest      string           `json:"manifest"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+	Results       []ManifestResult `json:"results"`
+}
+
+// defaultResultsPath 默认结果清单路径：与清单同目录，如 nightly.yaml -> nightly.results.json
+func defaultResultsPath(manifestPath string) string {
+	return strings.TrimSuffix(manifestPath, filepath.Ext(manifestPath)) + ".results.json"
+}
+
+// loadManifest 按扩展名读取JSON、YAML或CSV格式的上传清单
+func loadManifest(path string) ([]ManifestEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取清单失败: %w", err)
+	}
+
+	var entries []ManifestEntry
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		entries, err = parseManifestJSON(data)
+	case ".yaml", ".yml":
+		entries, err = parseManifestYAML(data)
+	case ".csv":
+		entries, err = parseManifestCSV(data)
+	default:
+		return nil, fmt.Errorf("不支持的清单格式: %s (可选: .json, .yaml, .yml, .csv)", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析清单失败: %w", err)
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("清单中没有文件")
+	}
+	for i, entry := range entries {
+		if entry.File == "" {
+			return nil, fmt.Errorf("清单第%d项: 缺少 file 字段", i+1)
+		}
+		if entry.File == stdinPath {
+			return nil, fmt.Errorf("清单第%d项: 清单中不支持标准输入", i+1)
+		}
+		if entry.Model != nil && !validModel(*entry.Model) {
+			return nil, fmt.Errorf("清单第%d项: 无效的文件有效期 %d，支持的值: 0, 1, 2, 99", i+1, *entry.Model)
+		}
+	}
+	return entries, nil
+}
+
+// parseManifestJSON 解析JSON清单：条目数组，或包含 files 数组的对象
+func parseManifestJSON(data []byte) ([]ManifestEntry, error) {
+	var entries []ManifestEntry
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		err := json.Unmarshal(data, &entries)
+		return entries, err
+	}
+
+	var manifest struct {
+		Files []ManifestEntry `json:"files"`
+	}
+	err := json.Unmarshal(data, &manifest)
+	return manifest.Files, err
+}
+
+// parseManifestYAML 解析YAML清单：条目列表，或包含 files 列表的映射
+func parseManifestYAML(data []byte) ([]ManifestEntry, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	if len(node.Content) == 0 {
+		return nil, nil
+	}
+
+	var entries []ManifestEntry
+	if node.Content[0].Kind == yaml.SequenceNode {
+		err := node.Decode(&entries)
+		return entries, err
+	}
+
+	var manifest struct {
+		Files []ManifestEntry `yaml:"files"`
+	}
+	err := node.Decode(&manifest)
+	return manifest.Files, err
+}
+
+// parseManifestCSV 解析CSV清单：首行为列名（file, name, model, mr_id, tags），多个标签用分号分隔
+func parseManifestCSV(data []byte) ([]ManifestEntry, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "file", "name", "model", "mr_id", "tags":
+			columns[name] = i
+		default:
+			return nil, fmt.Errorf("未知的列: %s (可选: file, name, model, mr_id, tags)", name)
+		}
+	}
+	if _, ok := columns["file"]; !ok {
+		return nil, fmt.Errorf("缺少 file 列")
+	}
+
+	var entries []ManifestEntry
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		entry := ManifestEntry{
+			File: field("file"),
+			Name: field("name"),
+			MrID: field("mr_id"),
+		}
+		if model := field("model"); model != "" {
+			value, err := strconv.Atoi(model)
+			if err != nil {
+				return nil, fmt.Errorf("第%d行: 无效的文件有效期: %s", line, model)
+			}
+			entry.Model = &value
+		}
+		for _, tag := range strings.Split(field("tags"), ";") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				entry.Tags = append(entry.Tags, tag)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// validModel 文件有效期是否为支持的值
+func validModel(model int) bool {
+	return model == 0 || model == 1 || model == 2 || model == 99
+}
+
+// loadManifestJobs 根据清单和已有的结果清单生成待上传任务，结果清单中已完成且文件未变化的条目被跳过
+// model 和 mrID 为条目未设置时使用的默认值
+func loadManifestJobs(manifestPath, resultsPath string, model int, mrID, taskID, statusFile string) ([]uploadJob, *manifestResults, int, error) {
+	// 条目路径按清单所在目录的绝对路径解析，结果清单中的记录与运行时的当前目录无关
+	manifestPath, err := filepath.Abs(manifestPath)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("解析清单路径失败: %w", err)
+	}
+	entries, err := loadManifest(manifestPath)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	// 读取上次运行的结果（不存在时视为首次运行）
+	previous := make(map[string]ManifestResult)
+	if data, err := os.ReadFile(resultsPath); err == nil {
+		var last manifestResults
+		if err := json.Unmarshal(data, &last); err != nil {
+			return nil, nil, 0, fmt.Errorf("解析结果清单失败: %w", err)
+		}
+		for _, result := range last.Results {
+			previous[result.key()] = result
+		}
+	}
+
+	results := &manifestResults{
+		path:          resultsPath,
+		SchemaVersion: outputSchemaVersion,
+		Manifest:      manifestPath,
+		Results:       make([]ManifestResult, len(entries)),
+	}
+
+	baseDir := filepath.Dir(manifestPath)
+	var jobs []uploadJob
+	skipped := 0
+	for i, entry := range entries {
+		path := entry.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+
+		job := uploadJob{
+			FilePath:      path,
+			Name:          entry.Name,
+			MrID:          mrID,
+			TaskID:        fmt.Sprintf("%s_%d", taskID, i+1),
+			StatusFile:    indexedStatusFile(statusFile, i+1),
+			manifestIndex: i,
+		}
+		job.Model = &model
+		if entry.Model != nil {
+			job.Model = entry.Model
+		}
+		if entry.MrID != "" {
+			job.MrID = entry.MrID
+		}
+
+		// 无法上传的条目不中断整个清单，上传时直接记为失败并写入结果清单
+		info, err := os.Stat(path)
+		switch {
+		case err != nil:
+			job.err = fmt.Errorf("获取文件信息失败: %w", err)
+		case !info.Mode().IsRegular():
+			job.err = fmt.Errorf("%s 不是普通文件", path)
+		case info.Size() > maxFileSize:
+			job.err = &UploadError{Kind: ErrKindFile, Message: "文件大小超出限制，最大支持50GB"}
+		}
+
+		result := ManifestResult{
+			FileReport: FileReport{
+				FilePath: path,
+				FileName: job.remoteName(),
+				Status:   "pending",
+			},
+			Model: *job.Model,
+			MrID:  job.MrID,
+			Tags:  entry.Tags,
+		}
+		if info != nil {
+			job.FileSize = info.Size()
+			result.FileSize = info.Size()
+			result.ModTime = info.ModTime()
+		}
+
+		// 已完成且文件未变化：沿用上次的结果
+		if last, ok := previous[result.key()]; ok && job.err == nil && last.Status == "completed" &&
+			last.FileSize == info.Size() && last.ModTime.Equal(info.ModTime()) {
+			last.Tags = entry.Tags
+			results.Results[i] = last
+			skipped++
+			continue
+		}
+
+		results.Results[i] = result
+		jobs = append(jobs, job)
+	}
+
+	return jobs, results, skipped, nil
+}
+
+// record 记录任务结果并立即写入结果清单
+func (r *manifestResults) record(job uploadJob, report FileReport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := &r.Results[job.manifestIndex]
+	report.Type = ""
+	result.FileReport = report
+	now := time.Now()
+	result.FinishedAt = &now
+	return r.saveLocked()
+}
+
+// save 写入结果清单
+func (r *manifestResults) save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.saveLocked()
+}
+
+// saveLocked 写入结果清单（先写临时文件再重命名，中断时不会留下不完整的文件），调用方需持有锁
+func (r *manifestResults) saveLocked() error {
+	r.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(r.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	tmpPath := r.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, r.path)
+}
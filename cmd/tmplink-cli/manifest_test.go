@@ -0,0 +1,324 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestParseManifestCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []ManifestEntry
+		wantErr string
+	}{
+		{
+			name: "列名不区分大小写和顺序",
+			data: " Tags , FILE,name\nnightly; linux ;,build/app.tar.gz,app.tar.gz\n",
+			want: []ManifestEntry{{File: "build/app.tar.gz", Name: "app.tar.gz", Tags: []string{"nightly", "linux"}}},
+		},
+		{
+			name: "有效期和目录ID",
+			data: "file,model,mr_id\na.bin,2,123\nb.bin,,\n",
+			want: []ManifestEntry{
+				{File: "a.bin", Model: intPtr(2), MrID: "123"},
+				{File: "b.bin"},
+			},
+		},
+		{
+			name: "只有列名",
+			data: "file,tags\n",
+		},
+		{
+			name: "空文件",
+			data: "",
+		},
+		{
+			name:    "无效的有效期",
+			data:    "file,model\na.bin,1\nb.bin,week\n",
+			wantErr: "第3行: 无效的文件有效期: week",
+		},
+		{
+			name:    "未知的列",
+			data:    "file,size\na.bin,1\n",
+			wantErr: "未知的列: size",
+		},
+		{
+			name:    "缺少file列",
+			data:    "name,tags\na.bin,x\n",
+			wantErr: "缺少 file 列",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseManifestCSV([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseManifestCSV: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseManifestYAML(t *testing.T) {
+	want := []ManifestEntry{
+		{File: "a.bin", Name: "renamed.bin", Tags: []string{"nightly"}},
+		{File: "b.bin", Model: intPtr(99), MrID: "42"},
+	}
+	tests := []struct {
+		name string
+		data string
+		want []ManifestEntry
+	}{
+		{
+			name: "条目列表",
+			data: `
+- file: a.bin
+  name: renamed.bin
+  tags: [nightly]
+- file: b.bin
+  model: 99
+  mr_id: "42"
+`,
+			want: want,
+		},
+		{
+			name: "files映射",
+			data: `
+files:
+  - file: a.bin
+    name: renamed.bin
+    tags:
+      - nightly
+  - file: b.bin
+    model: 99
+    mr_id: "42"
+`,
+			want: want,
+		},
+		{
+			name: "空文件",
+			data: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseManifestYAML([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("parseManifestYAML: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseManifestYAMLError(t *testing.T) {
+	if _, err := parseManifestYAML([]byte("files: [a.bin")); err == nil {
+		t.Error("parseManifestYAML accepted malformed YAML")
+	}
+}
+
+// writeTestFile 在目录中写入文件并返回路径
+func writeTestFile(t *testing.T, dir, name, data string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// completeJobs 将任务记录为上传完成并写入结果清单，模拟一次成功的运行
+func completeJobs(t *testing.T, jobs []uploadJob, results *manifestResults) {
+	t.Helper()
+	for _, job := range jobs {
+		report := FileReport{
+			FilePath:    job.FilePath,
+			FileName:    job.remoteName(),
+			FileSize:    job.FileSize,
+			Status:      "completed",
+			DownloadURL: "https://tmp.link/f/" + job.remoteName(),
+		}
+		if err := results.record(job, report); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadManifestJobsSkipsCompleted(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTestFile(t, dir, "a.bin", "aaaa")
+	b := writeTestFile(t, dir, "b.bin", "bbbb")
+	manifest := writeTestFile(t, dir, "nightly.csv", "file,tags\na.bin,x\nb.bin,y\n")
+	resultsPath := defaultResultsPath(manifest)
+
+	load := func() ([]uploadJob, *manifestResults, int) {
+		t.Helper()
+		jobs, results, skipped, err := loadManifestJobs(manifest, resultsPath, 0, "0", "task", "")
+		if err != nil {
+			t.Fatalf("loadManifestJobs: %v", err)
+		}
+		return jobs, results, skipped
+	}
+	paths := func(jobs []uploadJob) []string {
+		var paths []string
+		for _, job := range jobs {
+			paths = append(paths, job.FilePath)
+		}
+		return paths
+	}
+
+	jobs, results, skipped := load()
+	if len(jobs) != 2 || skipped != 0 {
+		t.Fatalf("first run: %d jobs, %d skipped; want 2, 0", len(jobs), skipped)
+	}
+	completeJobs(t, jobs, results)
+
+	// 文件未变化：全部跳过，沿用上次的结果并更新标签
+	if err := os.WriteFile(manifest, []byte("file,tags\na.bin,x2\nb.bin,y\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	jobs, results, skipped = load()
+	if len(jobs) != 0 || skipped != 2 {
+		t.Fatalf("unchanged: %d jobs, %d skipped; want 0, 2", len(jobs), skipped)
+	}
+	if r := results.Results[0]; r.Status != "completed" || r.DownloadURL == "" || !reflect.DeepEqual(r.Tags, []string{"x2"}) {
+		t.Errorf("skipped result = %+v, want previous result with new tags", r)
+	}
+
+	// 修改时间变化
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(a, later, later); err != nil {
+		t.Fatal(err)
+	}
+	jobs, _, skipped = load()
+	if got := paths(jobs); !reflect.DeepEqual(got, []string{a}) || skipped != 1 {
+		t.Errorf("mtime changed: jobs %v, %d skipped; want [%s], 1", got, skipped, a)
+	}
+
+	// 大小变化（修改时间保持不变）
+	info, err := os.Stat(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("bbbbbbbb"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(b, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	jobs, _, skipped = load()
+	if got := paths(jobs); !reflect.DeepEqual(got, []string{a, b}) || skipped != 0 {
+		t.Errorf("size changed: jobs %v, %d skipped; want [%s %s], 0", got, skipped, a, b)
+	}
+}
+
+func TestLoadManifestJobsRerunsFailed(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "a.bin", "aaaa")
+	manifest := writeTestFile(t, dir, "m.yaml", "- file: a.bin\n")
+	resultsPath := defaultResultsPath(manifest)
+
+	jobs, results, _, err := loadManifestJobs(manifest, resultsPath, 0, "0", "task", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := results.record(jobs[0], FileReport{FilePath: jobs[0].FilePath, FileName: "a.bin", FileSize: 4, Status: "failed"}); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, _, skipped, err := loadManifestJobs(manifest, resultsPath, 0, "0", "task", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || skipped != 0 {
+		t.Errorf("failed entry: %d jobs, %d skipped; want 1, 0", len(jobs), skipped)
+	}
+}
+
+func TestLoadManifestJobsKeysByNameAndDestination(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "a.bin", "aaaa")
+	manifest := writeTestFile(t, dir, "m.csv", "file,name,model\na.bin,,\n")
+	resultsPath := defaultResultsPath(manifest)
+
+	jobs, results, _, err := loadManifestJobs(manifest, resultsPath, 0, "0", "task", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	completeJobs(t, jobs, results)
+
+	// 同一文件以不同的文件名或有效期出现时视为新的条目
+	if err := os.WriteFile(manifest, []byte("file,name,model\na.bin,,\na.bin,copy.bin,\na.bin,,2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	jobs, _, skipped, err := loadManifestJobs(manifest, resultsPath, 0, "0", "task", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 || skipped != 1 {
+		t.Errorf("%d jobs, %d skipped; want 2, 1", len(jobs), skipped)
+	}
+}
+
+func TestLoadManifestJobsInvalidEntries(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "ok.bin", "data")
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := writeTestFile(t, dir, "m.csv", "file\nmissing.bin\nsub\nok.bin\n")
+
+	jobs, results, _, err := loadManifestJobs(manifest, defaultResultsPath(manifest), 0, "0", "task", "")
+	if err != nil {
+		t.Fatalf("loadManifestJobs: %v", err)
+	}
+	if len(jobs) != 3 || len(results.Results) != 3 {
+		t.Fatalf("%d jobs, %d results; want 3, 3", len(jobs), len(results.Results))
+	}
+	for i, wantErr := range []bool{true, true, false} {
+		if got := jobs[i].err != nil; got != wantErr {
+			t.Errorf("job %d (%s) err = %v, want error: %v", i, jobs[i].FilePath, jobs[i].err, wantErr)
+		}
+	}
+}
+
+func TestLoadManifestJobsRelativeToManifest(t *testing.T) {
+	dir := t.TempDir()
+	ok := writeTestFile(t, dir, "ok.bin", "data")
+	manifest := writeTestFile(t, dir, "m.csv", "file\nok.bin\n")
+
+	// 从其他目录以相对路径指定清单
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	if err := os.Chdir(filepath.Dir(dir)); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, results, _, err := loadManifestJobs(filepath.Join(filepath.Base(dir), "m.csv"), defaultResultsPath(manifest), 0, "0", "task", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jobs[0].FilePath != ok || results.Manifest != manifest {
+		t.Errorf("file %s, manifest %s; want %s, %s", jobs[0].FilePath, results.Manifest, ok, manifest)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"tmplink_uploader/internal/config"
+	"tmplink_uploader/internal/credential"
+)
+
+// addProfile 添加账号配置，Token等设置通过 -profile NAME -set-token 等命令保存
+func addProfile(name string) error {
+	if err := config.ValidateProfileName(name); err != nil {
+		return err
+	}
+	saved := readSharedConfigFile()
+	if _, exists := saved.Profiles[name]; exists {
+		return fmt.Errorf("账号配置已存在: %s", name)
+	}
+	saved.SetProfile(name, config.NewProfile())
+	return saved.Save()
+}
+
+// switchProfile 设置之后默认使用的账号配置
+func switchProfile(name string) error {
+	saved := readSharedConfigFile()
+	if name == config.DefaultProfileName {
+		name = ""
+	}
+	if _, exists := saved.Profiles[name]; name != "" && !exists {
+		return fmt.Errorf("账号配置不存在: %s (使用 -list-profiles 查看)", name)
+	}
+	saved.Profile = name
+	return saved.Save()
+}
+
+// removeProfile 删除账号配置和该账号保存的Token，删除当前使用的账号时切换回默认账号
+func removeProfile(name string) error {
+	if name == config.DefaultProfileName {
+		return fmt.Errorf("不能删除默认账号")
+	}
+	saved := readSharedConfigFile()
+	if _, exists := saved.Profiles[name]; !exists {
+		return fmt.Errorf("账号配置不存在: %s", name)
+	}
+	if err := credentialStore.Save(name, ""); err != nil {
+		return fmt.Errorf("删除Token失败: %w", err)
+	}
+	saved.RemoveProfile(name)
+	return saved.Save()
+}
+
+// listProfiles 列出所有账号配置，* 标记本次运行使用的账号
+func listProfiles() error {
+	saved := readSharedConfigFile()
+	settings, err := config.NewResolver(cliFlags, saved)
+	if err != nil {
+		return err
+	}
+	selected := settings.Resolve(config.SettingProfile, "")
+	active := selected.Value
+	if active == config.DefaultProfileName {
+		active = ""
+	}
+	if _, exists := saved.Profiles[active]; active != "" && !exists {
+		fmt.Fprintf(os.Stderr, "警告: 账号配置不存在: %s\n", active)
+	}
+
+	modelDesc := map[int]string{0: "24小时", 1: "3天", 2: "7天", 99: "无限期"}
+	fmt.Println("账号配置 (* 为当前使用):")
+	for _, name := range saved.ProfileNames() {
+		profile := saved.CurrentProfile()
+		if name != "" {
+			profile = saved.Profiles[name]
+		}
+
+		token, err := credentialStore.Load(name)
+		tokenText := credential.Redact(token)
+		switch {
+		case err != nil:
+			tokenText = "读取失败"
+		case token == "" && name == "" && saved.LegacyToken != "":
+			tokenText = credential.Redact(saved.LegacyToken) // 尚未迁移的旧版本Token
+		case token == "":
+			tokenText = "未设置"
+		}
+
+		marker := " "
+		if name == active {
+			marker = "*"
+		}
+		fmt.Printf("%s %-16s Token: %-10s 文件有效期: %-6s 目录ID: %-10s 分块: %dMB",
+			marker, config.ProfileName(name), tokenText, modelDesc[profile.Model], profile.MrID, profile.ChunkSize)
+		if profile.SelectedServerName != "" {
+			fmt.Printf(" 上传服务器: %s", profile.SelectedServerName)
+		} else if profile.UploadServer != "" {
+			fmt.Printf(" 上传服务器: %s", profile.UploadServer)
+		}
+		fmt.Println()
+	}
+	if selected.Source == config.SourceEnv || selected.Source == config.SourceProject {
+		fmt.Printf("\n%s 指定的账号 %s 优先于保存的当前账号\n", selected.Origin, selected.Value)
+	}
+	return nil
+}
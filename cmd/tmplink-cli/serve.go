@@ -0,0 +1,682 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"tmplink_uploader/internal/api"
+	"tmplink_uploader/internal/config"
+	"tmplink_uploader/internal/daemon"
+)
+
+const (
+	serveQueueSize     = 1000             // 上传队列容量
+	serveEventBuffer   = 64               // 每个事件订阅者的缓冲事件数，消费过慢时断开连接（客户端重连后重新获取全部任务）
+	serveHeartbeat     = 15 * time.Second // 事件流心跳间隔
+	serveShutdownGrace = 5 * time.Second  // 停止时等待请求结束的时间
+)
+
+// runServe 运行 tmplink-cli serve 子命令，在本机提供上传队列的HTTP接口，返回退出码
+func runServe(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	var (
+		listen        = flags.String("listen", daemon.DefaultAddr, "监听地址: 本机 host:port，或 unix:/path/to.sock")
+		parallel      = flags.Int("parallel", 2, "同时上传的文件数 (1-10)")
+		chunkSizeMB   = flags.Int("chunk-size", 3, "默认分块大小(MB, 1-99，默认使用已保存的值)")
+		skipUpload    = flags.Int("skip-upload", 1, "跳过上传标志 (1=检查秒传)")
+		uploadServer  = flags.String("upload-server", "", "强制指定上传服务器地址 (可选，默认使用已保存的值，均未设置时自动选择)")
+		retries       = flags.Int("retries", api.DefaultRetries, "单个请求失败后的最大重试次数 (网络错误、HTTP 5xx/429，0=不重试)")
+		retryMaxWait  = flags.Duration("retry-max-wait", api.DefaultRetryMaxWait, "两次重试之间的最长等待时间，0表示立即重试")
+		retryDeadline = flags.Duration("retry-deadline", api.DefaultRetryDeadline, "单个请求（含全部重试）的最长耗时，0=不限制")
+		debugMode     = flags.Bool("debug", false, "调试模式，输出详细运行信息")
+	)
+	// 以下参数按 命令行参数 > TMPLINK_* 环境变量 > 项目配置 > 用户配置 > 默认值 确定，见 resolveUploadSettings
+	flags.String("token", "", "TmpLink API token (可选，优先使用 TMPLINK_TOKEN 环境变量或已保存的token，请求中可按 profile 使用其他账号)")
+	flags.String("profile", "", "使用的账号配置 (默认: TMPLINK_PROFILE 环境变量、项目配置或 -use-profile 保存的账号)")
+	flags.Int("model", 0, "默认文件有效期 (0=24小时, 1=3天, 2=7天, 99=无限期，默认使用已保存的值)")
+	flags.String("mr-id", "0", "默认目录ID (默认使用已保存的值)")
+	flags.String("folder", "", "按路径指定默认目标文件夹，如 /Projects/2026 (不能与 -mr-id 同时使用，需要token)")
+	flags.Int("concurrency", 5, "每个文件的并发上传分片数 (1-20，默认使用已保存的max_concurrent)")
+	flags.String("api-server", "", "API服务器地址 (可选，也可通过TMPLINK_API环境变量或项目配置设置)")
+	flags.String("link-base", "", "下载链接前缀 (可选，也可通过TMPLINK_LINK_BASE环境变量或项目配置设置)")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "用法: tmplink-cli serve [参数]\n\n在本机提供上传队列的HTTP接口，供TUI和脚本使用。\n\n参数:\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "错误: 多余的参数: %v\n", flags.Args())
+		return exitFailure
+	}
+
+	// 参数优先级: 命令行参数 > TMPLINK_* 环境变量 > 项目配置 .tmplink.json > 用户配置 > 默认值
+	cliFlags = config.ExplicitFlags(flags)
+	resolved, err := resolveUploadSettings(loadSettings())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		return exitFailure
+	}
+	finalToken := resolved.token
+	finalModel := resolved.model
+	finalMrID := resolved.mrID
+	finalConcurrency := resolved.concurrency
+	*chunkSizeMB = resolved.chunkSizeMB
+	*uploadServer = resolved.uploadServer
+
+	if !validModel(finalModel) {
+		fmt.Fprintf(os.Stderr, "错误: 无效的文件有效期值，支持的值: 0, 1, 2, 99\n")
+		return exitFailure
+	}
+	if *parallel < 1 || *parallel > 10 {
+		fmt.Fprintf(os.Stderr, "错误: 同时上传的文件数必须在1-10之间，当前值: %d\n", *parallel)
+		return exitFailure
+	}
+	if *chunkSizeMB < 1 || *chunkSizeMB > 99 {
+		fmt.Fprintf(os.Stderr, "错误: 分块大小必须在1-99MB之间，当前值: %dMB\n", *chunkSizeMB)
+		return exitFailure
+	}
+	if finalConcurrency < 1 || finalConcurrency > 20 {
+		fmt.Fprintf(os.Stderr, "错误: 并发数必须在1-20之间，当前值: %d\n", finalConcurrency)
+		return exitFailure
+	}
+	if *retries < 0 || *retryMaxWait < 0 || *retryDeadline < 0 {
+		fmt.Fprintf(os.Stderr, "错误: 重试次数和等待时间不能为负数\n")
+		return exitFailure
+	}
+	if resolved.folder != "" && finalToken == "" {
+		fmt.Fprintf(os.Stderr, "错误: 按路径指定目标文件夹需要token\n")
+		return exitFailure
+	}
+
+	network, address, err := daemon.ParseAddr(*listen)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		return exitFailure
+	}
+
+	config := &Config{
+		Token:        finalToken,
+		Server:       resolved.apiServer,
+		LinkBase:     resolved.linkBase,
+		UploadServer: *uploadServer,
+		ChunkSize:    *chunkSizeMB * 1024 * 1024,
+		Model:        finalModel,
+		MrID:         finalMrID,
+		SkipUpload:   *skipUpload,
+		Concurrency:  finalConcurrency,
+		Retry: api.RetryPolicy{
+			MaxRetries: *retries,
+			MaxWait:    *retryMaxWait,
+			Deadline:   *retryDeadline,
+		},
+		Debug: *debugMode,
+	}
+	config.API = newAPIClient(config)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 已保存的token无效时直接退出；没有token时仍然启动，由请求提供token
+	if finalToken != "" {
+		if _, err := validateTokenAndGetUID(ctx, finalToken, config.Server); err != nil {
+			if ctx.Err() != nil {
+				return exitCancel
+			}
+			fmt.Fprintf(humanOut, "❌ Token验证失败!\n")
+			fmt.Fprintf(humanOut, "❗ 错误信息: %v\n", err)
+			fmt.Fprintln(humanOut, "💡 请使用 -set-token 命令重新设置有效的API Token")
+			kind, _ := classifyError(err)
+			return kind.exitCode()
+		}
+	}
+	if resolved.folder != "" {
+		folderID, err := resolveFolder(ctx, config, resolved.folder, false)
+		if err != nil {
+			if ctx.Err() != nil {
+				return exitCancel
+			}
+			fmt.Fprintf(os.Stderr, "错误: 查找目标文件夹失败: %v\n", err)
+			kind, _ := classifyError(err)
+			return kind.exitCode()
+		}
+		config.MrID = folderID
+	}
+	pruneResumeJournal(resumeJournalMaxAge)
+
+	secret, err := daemon.LoadOrCreateSecret(daemon.SecretPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		return exitFailure
+	}
+
+	listener, err := listenDaemon(network, address)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "错误: 监听 %s 失败: %v\n", *listen, err)
+		return exitFailure
+	}
+
+	s := newUploadServer(ctx, config, *parallel)
+	httpServer := &http.Server{Handler: s.handler(secret)}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownGrace)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(humanOut, "🛰️  上传服务已启动: %s\n", *listen)
+	fmt.Fprintf(humanOut, "   同时上传: %d 个文件, 队列容量: %d\n", *parallel, serveQueueSize)
+	if finalToken == "" {
+		fmt.Fprintln(humanOut, "   未设置token，上传请求需要用 profile 字段指定已保存Token的账号")
+	}
+	fmt.Fprintf(humanOut, "   访问密钥: %s\n", daemon.SecretPath())
+	fmt.Fprintln(humanOut, "   按 Ctrl+C 停止")
+
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		return exitFailure
+	}
+	s.wait()
+	logf("⏹️  上传服务已停止")
+	return exitOK
+}
+
+// listenDaemon 开始监听，Unix socket 文件已存在但无人监听时先删除，并限制只有当前用户可以连接
+func listenDaemon(network, address string) (net.Listener, error) {
+	if network != "unix" {
+		return net.Listen(network, address)
+	}
+
+	if _, err := os.Stat(address); err == nil {
+		if conn, err := net.Dial("unix", address); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("已有服务在运行")
+		}
+		os.Remove(address)
+	}
+	if err := os.MkdirAll(filepath.Dir(address), 0700); err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", address)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(address, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// serveTask serve 模式下的单个上传任务
+type serveTask struct {
+	job        uploadJob
+	config     *Config
+	serverName string     // 上传服务器名称，仅用于显示
+	status     TaskStatus // 最新状态，由 uploadServer.mu 保护
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+// serveEvent 推送给订阅者的事件
+type serveEvent struct {
+	name string
+	data []byte
+}
+
+// uploadServer 上传队列及其HTTP接口
+type uploadServer struct {
+	ctx    context.Context // 服务停止时取消全部任务
+	config *Config         // 默认上传配置
+	out    *reporter
+	queue  chan *serveTask
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	tasks  map[string]*serveTask
+	order  []string // 任务ID，按创建顺序
+	subs   map[chan serveEvent]bool
+	active int
+}
+
+// newUploadServer 创建上传服务并启动parallel个上传goroutine
+func newUploadServer(ctx context.Context, config *Config, parallel int) *uploadServer {
+	s := &uploadServer{
+		ctx:    ctx,
+		config: config,
+		queue:  make(chan *serveTask, serveQueueSize),
+		tasks:  make(map[string]*serveTask),
+		subs:   make(map[chan serveEvent]bool),
+	}
+	s.out = newReporter(outputText)
+	s.out.observe = s.update
+
+	for i := 0; i < parallel; i++ {
+		s.wg.Add(1)
+		go s.worker()
+	}
+	return s
+}
+
+// wait 停止接收任务并等待正在进行的上传结束（上下文已取消，上传会尽快结束）
+func (s *uploadServer) wait() {
+	s.mu.Lock()
+	close(s.queue)
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// worker 依次上传队列中的任务
+func (s *uploadServer) worker() {
+	defer s.wg.Done()
+	for t := range s.queue {
+		if t.ctx.Err() != nil {
+			// 排队期间已取消
+			continue
+		}
+
+		s.mu.Lock()
+		s.active++
+		s.mu.Unlock()
+
+		logf("⬆️  开始上传: %s [%s]", t.job.FilePath, t.job.TaskID)
+		// 命令行模式但不显示进度条：由服务日志输出每个任务的结果
+		opts := runOptions{cliMode: true, serverName: t.serverName}
+		task, _ := runUploadJob(t.ctx, t.config, t.job, opts, s.out)
+		s.update(task)
+
+		switch task.Status {
+		case "completed":
+			logf("✅ %s  %s", t.job.FilePath, task.DownloadURL)
+		case "cancelled":
+			logf("⏹️  %s  %s", t.job.FilePath, task.ErrorMsg)
+		default:
+			logf("❌ %s  %s", t.job.FilePath, task.ErrorMsg)
+		}
+
+		s.mu.Lock()
+		s.active--
+		s.mu.Unlock()
+		t.cancel()
+	}
+}
+
+// update 记录任务的最新状态并推送给订阅者，已移除的任务忽略
+func (s *uploadServer) update(task *TaskStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tasks[task.ID]
+	if !ok {
+		return
+	}
+	// 排队期间取消的任务不会再被上传，保留取消状态
+	if t.status.Status == "cancelled" && isTaskActive(task.Status) {
+		return
+	}
+	t.status = *task
+	s.broadcastLocked(daemon.EventTask, t.status)
+}
+
+// isTaskActive 任务是否仍在进行（未结束）
+func isTaskActive(status string) bool {
+	switch status {
+	case "starting", "pending", "hashing", "uploading":
+		return true
+	}
+	return false
+}
+
+// broadcastLocked 推送事件，调用方需持有锁
+func (s *uploadServer) broadcastLocked(name string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	event := serveEvent{name: name, data: data}
+	for ch := range s.subs {
+		select {
+		case ch <- event:
+		default:
+			// 订阅者消费过慢：断开，客户端重连后重新获取全部任务
+			delete(s.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// handler 返回HTTP接口，请求需要携带访问密钥 secret
+func (s *uploadServer) handler(secret string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+daemon.PathHealth, s.handleHealth)
+	mux.HandleFunc("GET "+daemon.PathTasks, s.handleList)
+	mux.HandleFunc("POST "+daemon.PathTasks, s.handleEnqueue)
+	mux.HandleFunc("GET "+daemon.PathTasks+"/{id}", s.handleGet)
+	mux.HandleFunc("POST "+daemon.PathTasks+"/{id}/cancel", s.handleCancel)
+	mux.HandleFunc("DELETE "+daemon.PathTasks+"/{id}", s.handleRemove)
+	mux.HandleFunc("GET "+daemon.PathEvents, s.handleEvents)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 拒绝浏览器发起的请求，避免网页通过本机地址操作上传队列
+		if r.Header.Get("Origin") != "" {
+			writeError(w, http.StatusForbidden, "不接受浏览器跨域请求")
+			return
+		}
+		// 本机的其他用户和进程也能连接TCP端口，只有能读取访问密钥文件的用户可以使用上传队列
+		if !daemon.Authorized(r.Header.Get("Authorization"), secret) {
+			writeError(w, http.StatusUnauthorized, fmt.Sprintf("缺少或错误的访问密钥，请在 Authorization 头中使用 Bearer 和 %s 中的密钥", daemon.SecretPath()))
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// writeJSON 输出JSON响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError 输出错误响应
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, daemon.ErrorResponse{Error: message})
+}
+
+// handleHealth 服务状态
+func (s *uploadServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	health := daemon.Health{
+		Version: Version,
+		PID:     os.Getpid(),
+		Queued:  len(s.queue),
+		Active:  s.active,
+		Tasks:   len(s.tasks),
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, health)
+}
+
+// snapshotLocked 按创建顺序返回全部任务，调用方需持有锁
+func (s *uploadServer) snapshotLocked() []TaskStatus {
+	tasks := make([]TaskStatus, 0, len(s.order))
+	for _, id := range s.order {
+		tasks = append(tasks, s.tasks[id].status)
+	}
+	return tasks
+}
+
+// handleList 全部任务
+func (s *uploadServer) handleList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	tasks := s.snapshotLocked()
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+// handleGet 单个任务
+func (s *uploadServer) handleGet(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	t, ok := s.tasks[r.PathValue("id")]
+	var status TaskStatus
+	if ok {
+		status = t.status
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "任务不存在")
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleEnqueue 验证请求并加入上传队列
+func (s *uploadServer) handleEnqueue(w http.ResponseWriter, r *http.Request) {
+	var req daemon.EnqueueRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("无效的请求: %v", err))
+		return
+	}
+
+	job, config, err := s.newJob(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	t := &serveTask{
+		job:        job,
+		config:     config,
+		serverName: req.ServerName,
+		status:     *newTaskStatus(job, req.ServerName),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+	t.status.Model, t.status.MrID = config.Model, config.MrID
+
+	// 先登记再入队，保证上传goroutine开始后的状态更新不会被忽略
+	s.mu.Lock()
+	if s.ctx.Err() != nil {
+		s.mu.Unlock()
+		cancel()
+		writeError(w, http.StatusServiceUnavailable, "上传服务正在停止")
+		return
+	}
+	select {
+	case s.queue <- t:
+	default:
+		s.mu.Unlock()
+		cancel()
+		writeError(w, http.StatusServiceUnavailable, "上传队列已满")
+		return
+	}
+	s.tasks[job.TaskID] = t
+	s.order = append(s.order, job.TaskID)
+	status := t.status
+	s.broadcastLocked(daemon.EventTask, status)
+	s.mu.Unlock()
+
+	logf("📥 已加入队列: %s [%s]", job.FilePath, job.TaskID)
+	writeJSON(w, http.StatusAccepted, status)
+}
+
+// newJob 根据请求生成上传任务和该任务使用的上传配置
+func (s *uploadServer) newJob(req daemon.EnqueueRequest) (uploadJob, *Config, error) {
+	if req.FilePath == "" {
+		return uploadJob{}, nil, fmt.Errorf("缺少 file_path")
+	}
+	path, err := filepath.Abs(req.FilePath)
+	if err != nil {
+		return uploadJob{}, nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return uploadJob{}, nil, fmt.Errorf("获取文件信息失败: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return uploadJob{}, nil, fmt.Errorf("%s 不是普通文件", path)
+	}
+	if info.Size() > maxFileSize {
+		return uploadJob{}, nil, fmt.Errorf("文件大小超出限制，最大支持50GB: %s", path)
+	}
+
+	config := *s.config
+	if req.Model != nil {
+		if !validModel(*req.Model) {
+			return uploadJob{}, nil, fmt.Errorf("无效的文件有效期 %d，支持的值: 0, 1, 2, 99", *req.Model)
+		}
+		config.Model = *req.Model
+	}
+	if req.MrID != "" {
+		config.MrID = req.MrID
+	}
+	if req.ChunkSize != 0 {
+		if req.ChunkSize < 1 || req.ChunkSize > 99 {
+			return uploadJob{}, nil, fmt.Errorf("分块大小必须在1-99MB之间，当前值: %dMB", req.ChunkSize)
+		}
+		config.ChunkSize = req.ChunkSize * 1024 * 1024
+	}
+	if req.SkipUpload != nil {
+		config.SkipUpload = *req.SkipUpload
+	}
+	if req.UploadServer != "" {
+		config.UploadServer = req.UploadServer
+	}
+	if req.Profile != "" {
+		token, err := profileToken(req.Profile)
+		if err != nil {
+			return uploadJob{}, nil, err
+		}
+		if token != config.Token {
+			config.Token = token
+			config.API = newAPIClient(&config)
+		}
+	}
+	if config.Token == "" {
+		return uploadJob{}, nil, fmt.Errorf("未找到token，请在请求中用 profile 指定账号或先用 -set-token 保存token")
+	}
+
+	job := uploadJob{
+		FilePath: path,
+		FileSize: info.Size(),
+		Name:     req.Name,
+		TaskID:   fmt.Sprintf("serve_%d", time.Now().UnixNano()),
+	}
+	return job, &config, nil
+}
+
+// profileToken 读取已保存的账号配置的Token，name 为 default 时为默认账号
+func profileToken(name string) (string, error) {
+	saved := readSharedConfigFile()
+	if name == config.DefaultProfileName {
+		name = ""
+	}
+	if err := saved.UseProfile(name); err != nil {
+		return "", err
+	}
+	if err := saved.LoadToken(); err != nil {
+		return "", fmt.Errorf("读取账号配置 %s 的Token失败: %w", config.ProfileName(name), err)
+	}
+	if saved.Token == "" {
+		return "", fmt.Errorf("账号配置 %s 未保存Token", config.ProfileName(name))
+	}
+	return saved.Token, nil
+}
+
+// cancelTask 取消任务：排队中的任务立即记录为已取消，上传中的任务由上传goroutine记录
+func (s *uploadServer) cancelTask(id string) (TaskStatus, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tasks[id]
+	if !ok {
+		return TaskStatus{}, false
+	}
+	if t.status.Status == "pending" {
+		t.status.Status = "cancelled"
+		t.status.ErrorMsg = "上传已取消"
+		t.status.UpdatedAt = time.Now()
+		s.broadcastLocked(daemon.EventTask, t.status)
+	}
+	t.cancel()
+	return t.status, true
+}
+
+// handleCancel 取消任务
+func (s *uploadServer) handleCancel(w http.ResponseWriter, r *http.Request) {
+	status, ok := s.cancelTask(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "任务不存在")
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleRemove 取消任务并从列表中移除
+func (s *uploadServer) handleRemove(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.cancelTask(id); !ok {
+		writeError(w, http.StatusNotFound, "任务不存在")
+		return
+	}
+
+	s.mu.Lock()
+	delete(s.tasks, id)
+	for i, taskID := range s.order {
+		if taskID == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	s.broadcastLocked(daemon.EventRemoved, daemon.RemovedEvent{ID: id})
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleEvents Server-Sent Events：先发送全部任务，之后推送每次变化
+func (s *uploadServer) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "不支持事件流")
+		return
+	}
+
+	ch := make(chan serveEvent, serveEventBuffer)
+	s.mu.Lock()
+	tasks := s.snapshotLocked()
+	s.subs[ch] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		if s.subs[ch] {
+			delete(s.subs, ch)
+			close(ch)
+		}
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	for _, task := range tasks {
+		data, _ := json.Marshal(task)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", daemon.EventTask, data)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(serveHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.ctx.Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case event, ok := <-ch:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.name, event.data)
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"tmplink_uploader/internal/api"
+	"tmplink_uploader/internal/config"
+)
+
+// cliFlags 命令行中显式指定的参数，在读取配置前设置
+var cliFlags = map[string]string{}
+
+// loadSettings 读取用户配置和项目配置 .tmplink.json，切换到解析出的账号配置并读取Token
+// 项目配置文件无效或账号配置不存在时报错退出
+func loadSettings() *config.Resolver {
+	settings, err := config.NewResolver(cliFlags, readSharedConfigFile())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		os.Exit(exitFailure)
+	}
+	if _, err := settings.UseProfile(); err != nil {
+		fmt.Fprintf(os.Stderr, "错误: %v (使用 -list-profiles 查看)\n", err)
+		os.Exit(exitFailure)
+	}
+	if err := settings.User.LoadToken(); err != nil {
+		fmt.Fprintf(os.Stderr, "警告: 读取保存的Token失败: %v\n", err)
+	}
+	return settings
+}
+
+// uploadSettings 上传使用的设置，按 命令行参数 > TMPLINK_* 环境变量 > 项目配置 > 用户配置 > 默认值 确定
+type uploadSettings struct {
+	token        string
+	apiServer    string
+	linkBase     string
+	uploadServer string // 空为自动选择
+	serverName   string
+	chunkSizeMB  int
+	concurrency  int
+	model        int
+	mrID         string
+	folder       string // 按路径指定的目标文件夹，非空时代替 mrID
+
+	values map[config.Setting]config.Value // 各项设置的值和来源，用于显示配置状态
+}
+
+// resolveUploadSettings 确定上传使用的设置，只检查格式，取值范围由调用方检查
+func resolveUploadSettings(settings *config.Resolver) (uploadSettings, error) {
+	defaults := config.Default()
+	s := uploadSettings{values: make(map[config.Setting]config.Value)}
+	resolve := func(setting config.Setting, def string) config.Value {
+		value := settings.Resolve(setting, def)
+		s.values[setting] = value
+		return value
+	}
+	var err error
+	resolveInt := func(setting config.Setting, def int) int {
+		n, intErr := resolve(setting, fmt.Sprintf("%d", def)).Int()
+		if intErr != nil && err == nil {
+			err = intErr
+		}
+		return n
+	}
+
+	s.token = resolve(config.SettingToken, "").Value
+	s.apiServer = resolve(config.SettingAPIServer, api.DefaultBaseURL).Value
+	s.linkBase = resolve(config.SettingLinkBase, api.DefaultLinkBase).Value
+	s.chunkSizeMB = resolveInt(config.SettingChunkSize, defaults.ChunkSize)
+	s.concurrency = resolveInt(config.SettingConcurrency, defaults.MaxConcurrent)
+	s.model = resolveInt(config.SettingModel, defaults.Model)
+	if err != nil {
+		return s, err
+	}
+
+	// 服务器名称只用于显示，来自比上传服务器优先级低的层时与上传服务器不对应
+	server := resolve(config.SettingUploadServer, "")
+	name := resolve(config.SettingServerName, "")
+	s.uploadServer = server.Value
+	if server.Value != "" && name.Source >= server.Source {
+		s.serverName = name.Value
+	}
+
+	// 目录ID和文件夹路径使用优先级高的一个，在同一层同时设置时报错
+	mrID := resolve(config.SettingMrID, defaults.MrID)
+	folder := resolve(config.SettingFolder, "")
+	s.mrID = mrID.Value
+	if folder.Value != "" && folder.Source >= mrID.Source {
+		if folder.Source == mrID.Source {
+			return s, folderConflictError(folder)
+		}
+		s.folder = folder.Value
+	}
+	return s, nil
+}
+
+// folderConflictError 同一层同时设置了目录ID和文件夹路径
+func folderConflictError(folder config.Value) error {
+	switch folder.Source {
+	case config.SourceFlag:
+		return fmt.Errorf("-%s 不能与 -%s 同时使用", config.SettingFolder.Flag, config.SettingMrID.Flag)
+	case config.SourceEnv:
+		return fmt.Errorf("环境变量 %s 不能与 %s 同时设置", config.SettingFolder.Env, config.SettingMrID.Env)
+	default:
+		return fmt.Errorf("%s 中 %s 不能与 %s 同时设置", folder.Origin, config.SettingFolder.Key, config.SettingMrID.Key)
+	}
+}
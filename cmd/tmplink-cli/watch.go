@@ -0,0 +1,793 @@
+package main
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"tmplink_uploader/internal/api"
+	"tmplink_uploader/internal/config"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// 上传成功后对源文件的处理方式
+const (
+	watchAfterKeep   = "keep"   // 保留源文件（默认）
+	watchAfterMove   = "move"   // 移动到 -move-to 指定的目录
+	watchAfterDelete = "delete" // 删除源文件
+)
+
+const (
+	watchMaxAttempts = 3           // 同一文件（大小和修改时间不变）最多上传失败的次数，超过后不再重试
+	watchRetryDelay  = time.Minute // 上传失败后的重试间隔，按失败次数递增
+	watchTick        = time.Second // 检查文件是否稳定的间隔
+)
+
+// watchOptions 监视目录的参数
+type watchOptions struct {
+	dir          string        // 监视的目录（绝对路径）
+	recursive    bool          // 同时监视子目录
+	stable       time.Duration // 文件大小和修改时间保持不变多久后才上传
+	poll         bool          // 使用轮询代替文件系统通知
+	pollInterval time.Duration // 轮询间隔
+	after        string        // 上传成功后对源文件的处理方式
+	moveTo       string        // after=move 时的目标目录（绝对路径）
+	linkLog      string        // 上传成功后追加下载链接的日志文件，空时不记录
+	statePath    string        // 状态文件路径
+}
+
+// runWatch 运行 tmplink-cli watch 子命令，监视目录并自动上传新文件，返回退出码
+func runWatch(args []string) int {
+	flags := flag.NewFlagSet("watch", flag.ExitOnError)
+	var (
+		chunkSizeMB   = flags.Int("chunk-size", 3, "分块大小(MB, 1-99，默认使用已保存的值)")
+		skipUpload    = flags.Int("skip-upload", 1, "跳过上传标志 (1=检查秒传)")
+		uploadServer  = flags.String("upload-server", "", "强制指定上传服务器地址 (可选，默认使用已保存的值，均未设置时自动选择)")
+		retries       = flags.Int("retries", api.DefaultRetries, "单个请求失败后的最大重试次数 (网络错误、HTTP 5xx/429，0=不重试)")
+		retryMaxWait  = flags.Duration("retry-max-wait", api.DefaultRetryMaxWait, "两次重试之间的最长等待时间，0表示立即重试")
+		retryDeadline = flags.Duration("retry-deadline", api.DefaultRetryDeadline, "单个请求（含全部重试）的最长耗时，0=不限制")
+		debugMode     = flags.Bool("debug", false, "调试模式，输出详细运行信息")
+		recursive     = flags.Bool("recursive", false, "同时监视子目录")
+		stable        = flags.Duration("stable", 10*time.Second, "文件大小和修改时间保持不变多久后才上传")
+		poll          = flags.Bool("poll", false, "使用轮询代替文件系统通知 (用于网络文件系统等不支持通知的场景)")
+		pollInterval  = flags.Duration("poll-interval", 5*time.Second, "轮询间隔")
+		after         = flags.String("after", watchAfterKeep, "上传成功后对源文件的处理: keep (保留), move (移动到 -move-to 目录), delete (删除)")
+		moveTo        = flags.String("move-to", "", "上传成功后移动到的目录 (-after move 时必需)")
+		linkLog       = flags.String("link-log", "", "上传成功后追加文件路径和下载链接的日志文件 (可选)")
+		statePath     = flags.String("state", "", "状态文件路径 (默认: ~/.tmplink/watch/ 下按目录生成)")
+	)
+	// 以下参数按 命令行参数 > TMPLINK_* 环境变量 > 项目配置 > 用户配置 > 默认值 确定，见 resolveUploadSettings
+	flags.String("token", "", "TmpLink API token (可选，优先使用 TMPLINK_TOKEN 环境变量或已保存的token)")
+	flags.String("profile", "", "使用的账号配置 (默认: TMPLINK_PROFILE 环境变量、项目配置或 -use-profile 保存的账号)")
+	flags.Int("model", 0, "文件有效期 (0=24小时, 1=3天, 2=7天, 99=无限期，默认使用已保存的值)")
+	flags.String("mr-id", "0", "目录ID (默认使用已保存的值)")
+	flags.String("folder", "", "按路径指定目标文件夹，如 /Projects/2026 (不能与 -mr-id 同时使用)")
+	flags.Int("concurrency", 5, "并发上传分片数 (1-20，默认使用已保存的max_concurrent)")
+	flags.String("api-server", "", "API服务器地址 (可选，也可通过TMPLINK_API环境变量或项目配置设置)")
+	flags.String("link-base", "", "下载链接前缀 (可选，也可通过TMPLINK_LINK_BASE环境变量或项目配置设置)")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "用法: tmplink-cli watch [参数] <目录>\n\n监视目录，文件写入完成后自动上传。\n\n参数:\n")
+		flags.PrintDefaults()
+	}
+
+	// 目录前后都可以出现参数
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "错误: 缺少要监视的目录\n")
+		flags.Usage()
+		return exitFailure
+	}
+	dir := flags.Arg(0)
+	flags.Parse(flags.Args()[1:])
+	if flags.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "错误: 只能监视一个目录，多余的参数: %s\n", strings.Join(flags.Args(), " "))
+		return exitFailure
+	}
+
+	// 参数优先级: 命令行参数 > TMPLINK_* 环境变量 > 项目配置 .tmplink.json > 用户配置 > 默认值
+	cliFlags = config.ExplicitFlags(flags)
+	resolved, err := resolveUploadSettings(loadSettings())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		return exitFailure
+	}
+	finalToken := resolved.token
+	finalModel := resolved.model
+	finalMrID := resolved.mrID
+	finalConcurrency := resolved.concurrency
+	*chunkSizeMB = resolved.chunkSizeMB
+	*uploadServer = resolved.uploadServer
+
+	if finalToken == "" {
+		fmt.Fprintf(os.Stderr, "错误: 未找到token，请使用 -token 参数或先用 -set-token 保存token\n")
+		return exitFailure
+	}
+	if !validModel(finalModel) {
+		fmt.Fprintf(os.Stderr, "错误: 无效的文件有效期值，支持的值: 0, 1, 2, 99\n")
+		return exitFailure
+	}
+	if *chunkSizeMB < 1 || *chunkSizeMB > 99 {
+		fmt.Fprintf(os.Stderr, "错误: 分块大小必须在1-99MB之间，当前值: %dMB\n", *chunkSizeMB)
+		return exitFailure
+	}
+	if finalConcurrency < 1 || finalConcurrency > 20 {
+		fmt.Fprintf(os.Stderr, "错误: 并发数必须在1-20之间，当前值: %d\n", finalConcurrency)
+		return exitFailure
+	}
+	if *retries < 0 || *retryMaxWait < 0 || *retryDeadline < 0 {
+		fmt.Fprintf(os.Stderr, "错误: 重试次数和等待时间不能为负数\n")
+		return exitFailure
+	}
+	if *stable < 0 || *pollInterval <= 0 {
+		fmt.Fprintf(os.Stderr, "错误: -stable 不能为负数，-poll-interval 必须大于0\n")
+		return exitFailure
+	}
+
+	opts := watchOptions{
+		recursive:    *recursive,
+		stable:       *stable,
+		poll:         *poll,
+		pollInterval: *pollInterval,
+		after:        *after,
+		linkLog:      *linkLog,
+		statePath:    *statePath,
+	}
+
+	if opts.dir, err = filepath.Abs(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		return exitFailure
+	}
+	if info, err := os.Stat(opts.dir); err != nil || !info.IsDir() {
+		fmt.Fprintf(os.Stderr, "错误: %s 不是目录\n", dir)
+		return exitFailure
+	}
+
+	switch opts.after {
+	case watchAfterKeep, watchAfterDelete:
+	case watchAfterMove:
+		if *moveTo == "" {
+			fmt.Fprintf(os.Stderr, "错误: -after move 需要使用 -move-to 指定目标目录\n")
+			return exitFailure
+		}
+		if opts.moveTo, err = filepath.Abs(*moveTo); err != nil {
+			fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+			return exitFailure
+		}
+		if err := os.MkdirAll(opts.moveTo, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "错误: 创建目标目录失败: %v\n", err)
+			return exitFailure
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "错误: 不支持的 -after 值: %s (可选: keep, move, delete)\n", opts.after)
+		return exitFailure
+	}
+	if opts.linkLog != "" {
+		if opts.linkLog, err = filepath.Abs(opts.linkLog); err != nil {
+			fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+			return exitFailure
+		}
+	}
+	if opts.statePath == "" {
+		opts.statePath = defaultWatchStatePath(opts.dir)
+	}
+	if opts.statePath, err = filepath.Abs(opts.statePath); err != nil {
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		return exitFailure
+	}
+
+	state, err := openWatchState(opts.statePath, opts.dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		return exitFailure
+	}
+	state.prune(resumeJournalMaxAge)
+	pruneResumeJournal(resumeJournalMaxAge)
+
+	config := &Config{
+		Token:        finalToken,
+		Server:       resolved.apiServer,
+		LinkBase:     resolved.linkBase,
+		UploadServer: *uploadServer,
+		ChunkSize:    *chunkSizeMB * 1024 * 1024,
+		Model:        finalModel,
+		MrID:         finalMrID,
+		SkipUpload:   *skipUpload,
+		Concurrency:  finalConcurrency,
+		Retry: api.RetryPolicy{
+			MaxRetries: *retries,
+			MaxWait:    *retryMaxWait,
+			Deadline:   *retryDeadline,
+		},
+		Debug: *debugMode,
+	}
+	config.API = newAPIClient(config)
+
+	// 收到 SIGINT/SIGTERM 时停止监视，正在上传的文件随上下文一起取消（下次启动时续传）
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if _, err := validateTokenAndGetUID(ctx, finalToken, config.Server); err != nil {
+		if ctx.Err() != nil {
+			return exitCancel
+		}
+		fmt.Fprintf(humanOut, "❌ Token验证失败!\n")
+		fmt.Fprintf(humanOut, "❗ 错误信息: %v\n", err)
+		fmt.Fprintln(humanOut, "💡 请使用 -set-token 命令重新设置有效的API Token")
+		kind, _ := classifyError(err)
+		return kind.exitCode()
+	}
+	if resolved.folder != "" {
+		folderID, err := resolveFolder(ctx, config, resolved.folder, false)
+		if err != nil {
+			if ctx.Err() != nil {
+				return exitCancel
+			}
+			fmt.Fprintf(os.Stderr, "错误: 查找目标文件夹失败: %v\n", err)
+			kind, _ := classifyError(err)
+			return kind.exitCode()
+		}
+		config.MrID = folderID
+	}
+
+	w := &folderWatcher{
+		config:  config,
+		opts:    opts,
+		state:   state,
+		out:     newReporter(outputText),
+		pending: make(map[string]*watchCandidate),
+	}
+	if err := w.run(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// watchCandidate 等待稳定后上传的文件
+type watchCandidate struct {
+	size      int64
+	modTime   time.Time
+	since     time.Time // 最近一次观察到变化的时间
+	notBefore time.Time // 上传失败后，下次重试的最早时间
+	queued    bool      // 已加入上传队列或正在上传
+}
+
+// watchUploadResult 单个文件的上传结果
+type watchUploadResult struct {
+	path    string
+	size    int64
+	modTime time.Time
+	task    *TaskStatus
+	report  FileReport
+}
+
+// folderWatcher 监视目录并逐个上传稳定的文件，pending 只在 run 所在的goroutine中访问
+type folderWatcher struct {
+	config  *Config
+	opts    watchOptions
+	state   *watchState
+	out     *reporter
+	pending map[string]*watchCandidate
+	ready   []string // 已稳定、等待上传的文件
+	notify  *fsnotify.Watcher
+}
+
+// run 监视目录直到上下文结束
+func (w *folderWatcher) run(ctx context.Context) error {
+	// 优先使用文件系统通知，不可用时改为轮询
+	if !w.opts.poll {
+		notify, err := fsnotify.NewWatcher()
+		if err == nil {
+			w.notify = notify
+			defer notify.Close()
+			err = w.addWatchTree(w.opts.dir)
+		}
+		if err != nil {
+			logf("⚠️  文件系统通知不可用 (%v)，改为每 %v 轮询一次", err, w.opts.pollInterval)
+			w.opts.poll = true
+		}
+	}
+	w.printBanner()
+
+	var (
+		events   chan fsnotify.Event
+		errs     chan error
+		pollTick <-chan time.Time
+	)
+	if w.opts.poll {
+		ticker := time.NewTicker(w.opts.pollInterval)
+		defer ticker.Stop()
+		pollTick = ticker.C
+	} else {
+		events, errs = w.notify.Events, w.notify.Errors
+	}
+	stableTick := time.NewTicker(watchTick)
+	defer stableTick.Stop()
+
+	// 启动时扫描一次，处理停止期间新增的文件
+	w.scan(w.opts.dir)
+
+	results := make(chan watchUploadResult, 1)
+	busy := false
+	for {
+		// 同时只上传一个文件，上传期间继续处理文件变化
+		if !busy && len(w.ready) > 0 {
+			path := w.ready[0]
+			w.ready = w.ready[1:]
+			c := w.pending[path]
+			busy = true
+			go func() {
+				results <- w.upload(ctx, path, c.size, c.modTime)
+			}()
+		}
+
+		select {
+		case <-ctx.Done():
+			if busy {
+				w.handleResult(<-results)
+			}
+			logf("⏹️  已停止监视")
+			return nil
+		case result := <-results:
+			busy = false
+			w.handleResult(result)
+		case event := <-events:
+			w.handleEvent(event)
+		case err := <-errs:
+			// 事件队列溢出等情况下可能漏掉文件，重新扫描一次
+			logf("⚠️  文件系统通知错误: %v，重新扫描目录", err)
+			w.scan(w.opts.dir)
+		case <-pollTick:
+			w.scan(w.opts.dir)
+		case now := <-stableTick.C:
+			w.checkStable(now)
+		}
+	}
+}
+
+// printBanner 显示监视参数
+func (w *folderWatcher) printBanner() {
+	mode := "文件系统通知"
+	if w.opts.poll {
+		mode = fmt.Sprintf("轮询 (每 %v)", w.opts.pollInterval)
+	}
+	afterDesc := map[string]string{
+		watchAfterKeep:   "保留源文件",
+		watchAfterMove:   "移动到 " + w.opts.moveTo,
+		watchAfterDelete: "删除源文件",
+	}
+	modelDesc := map[int]string{0: "24小时", 1: "3天", 2: "7天", 99: "无限期"}
+
+	dir := w.opts.dir
+	if w.opts.recursive {
+		dir += " (包括子目录)"
+	}
+	fmt.Fprintf(humanOut, "👀 正在监视: %s\n", dir)
+	fmt.Fprintf(humanOut, "   检测方式: %s, 稳定时间: %v\n", mode, w.opts.stable)
+	fmt.Fprintf(humanOut, "   文件有效期: %s, 目录ID: %s\n", modelDesc[w.config.Model], w.config.MrID)
+	fmt.Fprintf(humanOut, "   上传成功后: %s\n", afterDesc[w.opts.after])
+	if w.opts.linkLog != "" {
+		fmt.Fprintf(humanOut, "   链接日志: %s\n", w.opts.linkLog)
+	}
+	fmt.Fprintf(humanOut, "   状态文件: %s\n", w.opts.statePath)
+	fmt.Fprintln(humanOut, "   按 Ctrl+C 停止")
+}
+
+// addWatchTree 为目录添加通知，递归模式下包括全部子目录
+func (w *folderWatcher) addWatchTree(root string) error {
+	if !w.opts.recursive {
+		return w.notify.Add(root)
+	}
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if w.ignored(path) {
+			return filepath.SkipDir
+		}
+		return w.notify.Add(path)
+	})
+}
+
+// ignored 不上传的路径：隐藏文件、移动目标目录、状态文件和链接日志
+func (w *folderWatcher) ignored(path string) bool {
+	if path != w.opts.dir && strings.HasPrefix(filepath.Base(path), ".") {
+		return true
+	}
+	if w.opts.moveTo != "" && (path == w.opts.moveTo || strings.HasPrefix(path, w.opts.moveTo+string(filepath.Separator))) {
+		return true
+	}
+	return path == w.opts.statePath || path == w.opts.linkLog
+}
+
+// scan 扫描目录，将尚未处理的文件加入等待列表
+func (w *folderWatcher) scan(root string) {
+	if !w.opts.recursive {
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			logf("⚠️  读取目录失败: %v", err)
+			return
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				w.consider(filepath.Join(root, entry.Name()))
+			}
+		}
+		return
+	}
+
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if w.ignored(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			w.consider(path)
+		}
+		return nil
+	})
+}
+
+// consider 发现文件：不在等待列表中且未处理过时加入（已在列表中的文件由 checkStable 检查变化）
+func (w *folderWatcher) consider(path string) {
+	if w.ignored(path) {
+		return
+	}
+	if _, ok := w.pending[path]; ok {
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || w.state.handled(path, info) {
+		return
+	}
+	w.pending[path] = &watchCandidate{
+		size:    info.Size(),
+		modTime: info.ModTime(),
+		since:   time.Now(),
+	}
+}
+
+// handleEvent 处理文件系统通知
+func (w *folderWatcher) handleEvent(event fsnotify.Event) {
+	path := event.Name
+	switch {
+	case event.Has(fsnotify.Create) || event.Has(fsnotify.Write):
+		info, err := os.Stat(path)
+		if err != nil {
+			return
+		}
+		if info.IsDir() {
+			// 新建的子目录：添加通知并处理其中已有的文件
+			if w.opts.recursive && !w.ignored(path) {
+				if err := w.addWatchTree(path); err != nil {
+					logf("⚠️  监视子目录失败: %v", err)
+				}
+				w.scan(path)
+			}
+			return
+		}
+		// 文件仍在写入：重新开始计算稳定时间
+		if c, ok := w.pending[path]; ok && !c.queued {
+			c.since = time.Now()
+			return
+		}
+		w.consider(path)
+	case event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename):
+		if c, ok := w.pending[path]; ok && !c.queued {
+			delete(w.pending, path)
+		}
+	}
+}
+
+// checkStable 检查等待中的文件，大小和修改时间在稳定时间内没有变化的加入上传队列
+func (w *folderWatcher) checkStable(now time.Time) {
+	for path, c := range w.pending {
+		if c.queued {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			delete(w.pending, path)
+			continue
+		}
+		if info.Size() != c.size || !info.ModTime().Equal(c.modTime) {
+			c.size, c.modTime, c.since = info.Size(), info.ModTime(), now
+			continue
+		}
+		if now.Sub(c.since) < w.opts.stable || now.Before(c.notBefore) {
+			continue
+		}
+		if w.state.handled(path, info) {
+			delete(w.pending, path)
+			continue
+		}
+		c.queued = true
+		w.ready = append(w.ready, path)
+	}
+}
+
+// upload 上传单个文件
+func (w *folderWatcher) upload(ctx context.Context, path string, size int64, modTime time.Time) watchUploadResult {
+	logf("⬆️  开始上传: %s (%s)", path, formatBytes(size))
+	job := uploadJob{
+		FilePath: path,
+		FileSize: size,
+		TaskID:   fmt.Sprintf("watch_%d", time.Now().UnixNano()),
+	}
+	if size > maxFileSize {
+		task := newTaskStatus(job, "")
+		task.Status = "failed"
+		setTaskError(task, &UploadError{Kind: ErrKindFile, Message: "文件大小超出限制，最大支持50GB"})
+		return watchUploadResult{path: path, size: size, modTime: modTime, task: task, report: newFileReport(task, nil, 0)}
+	}
+
+	// 命令行模式但不显示进度条：由监视日志输出每个文件的结果
+	opts := runOptions{cliMode: true}
+	task, report := runUploadJob(ctx, w.config, job, opts, w.out)
+	return watchUploadResult{path: path, size: size, modTime: modTime, task: task, report: report}
+}
+
+// handleResult 记录上传结果，成功时追加链接日志并按 -after 处理源文件
+func (w *folderWatcher) handleResult(result watchUploadResult) {
+	c := w.pending[result.path]
+	task := result.task
+
+	switch task.Status {
+	case "cancelled":
+		// 停止监视导致的取消不记录，下次启动时继续上传
+		logf("⏹️  上传已取消: %s", result.path)
+		return
+	case "completed":
+		delete(w.pending, result.path)
+		logf("✅ %s  %s", result.path, task.DownloadURL)
+		record := w.state.record(result, 0)
+		if w.opts.linkLog != "" {
+			if err := appendLinkLog(w.opts.linkLog, result.path, task); err != nil {
+				logf("⚠️  写入链接日志失败: %v", err)
+			}
+		}
+		w.finishSource(result, record)
+	default:
+		attempts := w.state.attempts(result.path, result.size, result.modTime) + 1
+		w.state.record(result, attempts)
+		if attempts >= watchMaxAttempts {
+			delete(w.pending, result.path)
+			logf("❌ %s  %s (已失败%d次，文件变化前不再重试)", result.path, task.ErrorMsg, attempts)
+			w.consider(result.path) // 上传期间已被修改的文件按新版本重新等待
+		} else {
+			delay := watchRetryDelay * time.Duration(attempts)
+			c.queued = false
+			c.notBefore = time.Now().Add(delay)
+			logf("❌ %s  %s (%v 后重试)", result.path, task.ErrorMsg, delay)
+		}
+	}
+	if err := w.state.save(); err != nil {
+		logf("⚠️  保存状态文件失败: %v", err)
+	}
+}
+
+// finishSource 上传成功后移动或删除源文件，文件在上传期间被修改时保留源文件并在稳定后重新上传
+// 上传期间的修改通知因文件已在队列中被忽略，无论 -after 为何值都需要在这里重新检查
+func (w *folderWatcher) finishSource(result watchUploadResult, record *watchRecord) {
+	info, err := os.Stat(result.path)
+	if err != nil {
+		return
+	}
+	if info.Size() != result.size || !info.ModTime().Equal(result.modTime) {
+		if w.opts.after == watchAfterKeep {
+			logf("⚠️  %s 在上传期间被修改，稳定后重新上传", result.path)
+		} else {
+			logf("⚠️  %s 在上传期间被修改，保留源文件并在稳定后重新上传", result.path)
+		}
+		w.consider(result.path)
+		return
+	}
+
+	switch w.opts.after {
+	case watchAfterMove:
+		target, err := moveFile(result.path, w.opts.moveTo)
+		if err != nil {
+			logf("⚠️  移动文件失败: %v", err)
+			return
+		}
+		record.MovedTo = target
+		logf("📦 已移动到: %s", target)
+	case watchAfterDelete:
+		if err := os.Remove(result.path); err != nil {
+			logf("⚠️  删除文件失败: %v", err)
+			return
+		}
+		record.Deleted = true
+		logf("🗑️  已删除: %s", result.path)
+	}
+}
+
+// moveFile 将文件移动到目标目录，重名时追加序号；跨文件系统时先复制再删除
+func moveFile(src, dir string) (string, error) {
+	base := filepath.Base(src)
+	ext := filepath.Ext(base)
+	target := filepath.Join(dir, base)
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(target); os.IsNotExist(err) {
+			break
+		}
+		target = filepath.Join(dir, fmt.Sprintf("%s_%d%s", strings.TrimSuffix(base, ext), i, ext))
+	}
+
+	if err := os.Rename(src, target); err == nil {
+		return target, nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+	out, err := os.Create(target)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(target)
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(target)
+		return "", err
+	}
+	return target, os.Remove(src)
+}
+
+// appendLinkLog 追加一行 "时间<TAB>文件路径<TAB>下载链接" 到链接日志
+func appendLinkLog(path, filePath string, task *TaskStatus) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "%s\t%s\t%s\n", time.Now().Format(time.RFC3339), filePath, task.DownloadURL)
+	return err
+}
+
+// watchRecord 状态文件中单个文件的处理记录，文件路径、大小和修改时间共同决定是否已处理
+type watchRecord struct {
+	FilePath    string    `json:"file_path"`
+	FileSize    int64     `json:"file_size"`
+	ModTime     time.Time `json:"mod_time"`
+	Status      string    `json:"status"` // completed 或 failed
+	SHA1        string    `json:"sha1,omitempty"`
+	DownloadURL string    `json:"download_url,omitempty"`
+	ErrorMsg    string    `json:"error_msg,omitempty"`
+	ErrorType   string    `json:"error_type,omitempty"`
+	Attempts    int       `json:"attempts,omitempty"` // 连续失败次数
+	MovedTo     string    `json:"moved_to,omitempty"`
+	Deleted     bool      `json:"deleted,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// watchState 监视目录的状态文件，记录已上传和多次失败的文件，重启后不会重复上传
+type watchState struct {
+	path string
+
+	Dir   string                  `json:"dir"`
+	Files map[string]*watchRecord `json:"files"`
+}
+
+// defaultWatchStatePath 默认状态文件路径：~/.tmplink/watch/ 下以监视目录生成文件名
+func defaultWatchStatePath(dir string) string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "."
+	}
+	sum := sha1.Sum([]byte(dir))
+	name := fmt.Sprintf("%s_%s.json", filepath.Base(dir), hex.EncodeToString(sum[:])[:12])
+	return filepath.Join(homeDir, ".tmplink", "watch", name)
+}
+
+// openWatchState 读取状态文件，不存在时创建空状态
+func openWatchState(path, dir string) (*watchState, error) {
+	s := &watchState{path: path, Dir: dir, Files: make(map[string]*watchRecord)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取状态文件失败: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("解析状态文件失败: %w", err)
+	}
+	if s.Files == nil {
+		s.Files = make(map[string]*watchRecord)
+	}
+	return s, nil
+}
+
+// lookup 返回与当前文件版本（大小和修改时间）一致的记录
+func (s *watchState) lookup(path string, size int64, modTime time.Time) *watchRecord {
+	record, ok := s.Files[path]
+	if !ok || record.FileSize != size || !record.ModTime.Equal(modTime) {
+		return nil
+	}
+	return record
+}
+
+// handled 文件是否已上传，或失败次数已达上限
+func (s *watchState) handled(path string, info os.FileInfo) bool {
+	record := s.lookup(path, info.Size(), info.ModTime())
+	return record != nil && (record.Status == "completed" || record.Attempts >= watchMaxAttempts)
+}
+
+// attempts 当前文件版本已失败的次数
+func (s *watchState) attempts(path string, size int64, modTime time.Time) int {
+	if record := s.lookup(path, size, modTime); record != nil && record.Status == "failed" {
+		return record.Attempts
+	}
+	return 0
+}
+
+// record 记录上传结果（调用方负责保存），attempts 为失败次数
+func (s *watchState) record(result watchUploadResult, attempts int) *watchRecord {
+	record := &watchRecord{
+		FilePath:    result.path,
+		FileSize:    result.size,
+		ModTime:     result.modTime,
+		Status:      result.task.Status,
+		SHA1:        result.report.SHA1,
+		DownloadURL: result.task.DownloadURL,
+		ErrorMsg:    result.task.ErrorMsg,
+		ErrorType:   result.task.ErrorType,
+		Attempts:    attempts,
+		UpdatedAt:   time.Now(),
+	}
+	s.Files[result.path] = record
+	return record
+}
+
+// prune 删除源文件已不存在且超过保留时间的记录
+func (s *watchState) prune(maxAge time.Duration) {
+	for path, record := range s.Files {
+		if _, err := os.Stat(path); err != nil && time.Since(record.UpdatedAt) > maxAge {
+			delete(s.Files, path)
+		}
+	}
+}
+
+// save 写入状态文件（先写临时文件再重命名，避免中断时留下不完整的文件）
+func (s *watchState) save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.path)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -9,6 +10,7 @@ import (
 	"path/filepath"
 
 	"github.com/charmbracelet/bubbletea"
+	"tmplink_uploader/internal/daemon"
 	"tmplink_uploader/internal/gui/tui"
 	"tmplink_uploader/internal/updater"
 )
@@ -25,6 +27,9 @@ func main() {
 		checkUpdate  = flag.Bool("check-update", false, "检查是否有新版本可用")
 		autoUpdate   = flag.Bool("auto-update", false, "自动检查并下载更新")
 		showVersion  = flag.Bool("version", false, "显示当前版本号")
+		apiServer    = flag.String("api-server", "", "API服务器地址 (可选，也可通过TMPLINK_API环境变量设置)")
+		linkBase     = flag.String("link-base", "", "下载链接前缀 (可选，也可通过TMPLINK_LINK_BASE环境变量设置)")
+		daemonAddr   = flag.String("daemon", "", "通过 tmplink-cli serve 上传，如 127.0.0.1:7478 或 unix:/path/to.sock (可选)")
 	)
 
 	flag.Parse()
@@ -63,19 +68,39 @@ func main() {
 		return
 	}
 
+	// 服务模式：上传由已运行的 tmplink-cli serve 完成，不需要CLI程序
+	var daemonClient *daemon.Client
+	if *daemonAddr != "" {
+		client, err := daemon.NewClient(*daemonAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "无效的服务地址: %v\n", err)
+			os.Exit(1)
+		}
+		if _, err := client.Health(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "无法连接上传服务: %v\n请先运行: tmplink-cli serve -listen %s\n", err, *daemonAddr)
+			os.Exit(1)
+		}
+		daemonClient = client
+	}
+
 	// 获取CLI程序路径
 	cliPath := getCLIPath()
 	
 	// 验证CLI程序存在
-	if err := validateCLIPath(cliPath); err != nil {
-		log.Fatalf("CLI程序验证失败: %v\n请确保tmplink-cli程序位于: %s", err, cliPath)
+	if daemonClient == nil {
+		if err := validateCLIPath(cliPath); err != nil {
+			log.Fatalf("CLI程序验证失败: %v\n请确保tmplink-cli程序位于: %s", err, cliPath)
+		}
 	}
 
 	// 启动时检查更新（后台进行，不阻塞用户操作）
 	updater.CheckUpdateOnStartup("gui", Version, os.Args)
 
 	// 创建TUI模型
-	model := tui.NewModel(cliPath)
+	model := tui.NewModel(cliPath, *apiServer, *linkBase)
+	if daemonClient != nil {
+		model = model.WithDaemon(daemonClient)
+	}
 
 	// 启动TUI程序
 	p := tea.NewProgram(model, tea.WithAltScreen())
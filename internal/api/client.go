@@ -0,0 +1,608 @@
+// Package api 封装钛盘 (TmpLink) HTTP API，供 CLI、TUI 以及其他 Go 工具复用
+package api
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"tmplink_uploader/internal/credential"
+)
+
+const (
+	// DefaultBaseURL 钛盘API服务器默认地址
+	DefaultBaseURL = "https://tmplink-sec.vxtrans.com/api_v2"
+	// DefaultLinkBase 默认下载链接前缀，与ukey拼接得到文件下载地址
+	DefaultLinkBase = "https://tmp.link/f/"
+	// DefaultUserAgent 默认User-Agent
+	DefaultUserAgent = "tmplink-uploader"
+
+	// EnvAPIServer 覆盖API服务器地址的环境变量，用于预发布镜像或本地模拟服务器
+	EnvAPIServer = "TMPLINK_API"
+	// EnvLinkBase 覆盖下载链接前缀的环境变量
+	EnvLinkBase = "TMPLINK_LINK_BASE"
+)
+
+// ResolveAPIServer 确定API服务器地址，优先级: 环境变量 TMPLINK_API > 配置值 > 默认值
+func ResolveAPIServer(configured string) string {
+	return resolveEndpoint(os.Getenv(EnvAPIServer), configured, DefaultBaseURL)
+}
+
+// ResolveLinkBase 确定下载链接前缀，优先级: 环境变量 TMPLINK_LINK_BASE > 配置值 > 默认值
+func ResolveLinkBase(configured string) string {
+	return resolveEndpoint(os.Getenv(EnvLinkBase), configured, DefaultLinkBase)
+}
+
+// resolveEndpoint 返回第一个非空的地址
+func resolveEndpoint(candidates ...string) string {
+	for _, candidate := range candidates {
+		if candidate = strings.TrimSpace(candidate); candidate != "" {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// Client 钛盘API客户端
+type Client struct {
+	BaseURL    string       // API服务器地址，如 https://tmplink-sec.vxtrans.com/api_v2
+	HTTPClient *http.Client // 底层HTTP客户端
+	UserAgent  string       // 请求使用的User-Agent
+	Token      string       // 用户API Token
+	LinkBase   string       // 下载链接前缀，如 https://tmp.link/f/
+
+	// Logf 可选的调试日志输出，为nil时不输出
+	Logf func(format string, args ...interface{})
+}
+
+// NewClient 使用默认配置创建API客户端
+func NewClient(token string) *Client {
+	return &Client{
+		BaseURL:    DefaultBaseURL,
+		HTTPClient: &http.Client{},
+		UserAgent:  DefaultUserAgent,
+		Token:      token,
+		LinkBase:   DefaultLinkBase,
+	}
+}
+
+// DownloadURL 根据ukey生成文件下载链接
+func (c *Client) DownloadURL(ukey string) string {
+	base := c.LinkBase
+	if base == "" {
+		base = DefaultLinkBase
+	}
+	return strings.TrimRight(base, "/") + "/" + ukey
+}
+
+// HTTPError 服务器返回非200状态码
+type HTTPError struct {
+	StatusCode int
+	RetryAfter time.Duration // 服务器通过Retry-After头要求的等待时间（如果有）
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP请求失败，状态码: %d", e.StatusCode)
+}
+
+// requestError 请求未能得到HTTP响应（连接失败、连接中断等网络错误）
+type requestError struct {
+	err error
+}
+
+func (e *requestError) Error() string { return e.err.Error() }
+func (e *requestError) Unwrap() error { return e.err }
+
+// IsNetworkError 判断错误是否由网络故障或HTTP错误状态引起（而非服务器业务状态码）
+func IsNetworkError(err error) bool {
+	var httpErr *HTTPError
+	var netErr *requestError
+	return errors.As(err, &httpErr) || errors.As(err, &netErr)
+}
+
+// UpToken 根据文件特征生成分片上传使用的uptoken: SHA1(sha1 + filename + filesize + slice_size)
+func UpToken(sha1Hash, fileName string, fileSize int64, sliceSize int) string {
+	data := fmt.Sprintf("%s%s%d%d", sha1Hash, fileName, fileSize, sliceSize)
+	sum := sha1.Sum([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// ========== /user ==========
+
+// UserDetail get_detail 返回的用户详情
+type UserDetail struct {
+	UID         int64 `json:"uid"`
+	Storage     int64 `json:"storage"`
+	StorageUsed int64 `json:"storage_used"`
+	Sponsor     bool  `json:"sponsor"`
+}
+
+// UserDetailResponse get_detail 响应
+type UserDetailResponse struct {
+	Status    int
+	Msg       string
+	Detail    UserDetail // 仅在 Status == 1 时有效
+	ErrorText string     // 失败时data中携带的错误描述（如果有）
+}
+
+// GetDetail 调用 action=get_detail 获取用户详情，也用于验证Token有效性
+func (c *Client) GetDetail(ctx context.Context) (*UserDetailResponse, error) {
+	form := url.Values{}
+	form.Set("action", "get_detail")
+	form.Set("token", c.Token)
+
+	var raw rawResponse
+	if err := c.postForm(ctx, c.endpoint("/user"), form, &raw); err != nil {
+		return nil, err
+	}
+
+	resp := &UserDetailResponse{Status: raw.Status, Msg: raw.Msg}
+	if raw.Status == 1 {
+		if err := json.Unmarshal(raw.Data, &resp.Detail); err != nil {
+			return nil, fmt.Errorf("解析用户详情失败: %w", err)
+		}
+	} else {
+		// Token无效时，data可能是字符串错误信息
+		json.Unmarshal(raw.Data, &resp.ErrorText)
+	}
+	return resp, nil
+}
+
+// UserProfile pf_userinfo_get 返回的用户资料
+type UserProfile struct {
+	Nickname string `json:"nickname"`
+}
+
+// UserProfileResponse pf_userinfo_get 响应
+type UserProfileResponse struct {
+	Status  int
+	Msg     string
+	Profile UserProfile // 仅在 Status == 1 时有效
+}
+
+// GetUserProfile 调用 action=pf_userinfo_get 获取用户昵称等资料
+func (c *Client) GetUserProfile(ctx context.Context) (*UserProfileResponse, error) {
+	form := url.Values{}
+	form.Set("action", "pf_userinfo_get")
+	form.Set("token", c.Token)
+
+	var raw rawResponse
+	if err := c.postForm(ctx, c.endpoint("/user"), form, &raw); err != nil {
+		return nil, err
+	}
+
+	resp := &UserProfileResponse{Status: raw.Status, Msg: raw.Msg}
+	if raw.Status == 1 {
+		if err := json.Unmarshal(raw.Data, &resp.Profile); err != nil {
+			return nil, fmt.Errorf("解析用户信息失败: %w", err)
+		}
+	}
+	return resp, nil
+}
+
+// ========== /file ==========
+
+// UploadServer 上传服务器节点
+type UploadServer struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// UploadSelectRequest upload_request_select2 请求参数
+type UploadSelectRequest struct {
+	SHA1     string
+	Filename string
+	Filesize int64
+	Model    int
+	MrID     string // 可选，为空时不发送
+}
+
+// UploadSelectResponse upload_request_select2 响应
+type UploadSelectResponse struct {
+	Status  int
+	UToken  string
+	Servers []UploadServer
+}
+
+// UploadRequestSelect 调用 action=upload_request_select2 获取UToken和可用上传服务器
+func (c *Client) UploadRequestSelect(ctx context.Context, req UploadSelectRequest) (*UploadSelectResponse, error) {
+	form := url.Values{}
+	form.Set("action", "upload_request_select2")
+	form.Set("sha1", req.SHA1)
+	form.Set("filename", req.Filename)
+	form.Set("filesize", strconv.FormatInt(req.Filesize, 10))
+	form.Set("model", strconv.Itoa(req.Model))
+	form.Set("token", c.Token)
+	if req.MrID != "" {
+		form.Set("mr_id", req.MrID)
+	}
+
+	var raw rawResponse
+	if err := c.postForm(ctx, c.endpoint("/file"), form, &raw); err != nil {
+		return nil, err
+	}
+
+	resp := &UploadSelectResponse{Status: raw.Status}
+	if raw.Status != 1 {
+		return resp, nil
+	}
+
+	var data struct {
+		UToken  string          `json:"utoken"`
+		Servers json.RawMessage `json:"servers"`
+	}
+	if err := json.Unmarshal(raw.Data, &data); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	resp.UToken = data.UToken
+
+	// servers 是对象数组，每个对象有 title 和 url 字段；格式不符时忽略
+	var servers []UploadServer
+	if err := json.Unmarshal(data.Servers, &servers); err == nil {
+		for _, server := range servers {
+			if server.URL != "" {
+				resp.Servers = append(resp.Servers, server)
+			}
+		}
+	}
+
+	return resp, nil
+}
+
+// PrepareV4Request prepare_v4 请求参数
+type PrepareV4Request struct {
+	SHA1       string
+	Filename   string
+	Filesize   int64
+	Model      int
+	MrID       string // 可选，为空时不发送
+	SkipUpload int    // 1=检查秒传
+}
+
+// PrepareV4Response prepare_v4 响应
+type PrepareV4Response struct {
+	Status int
+	Data   interface{}
+}
+
+// UKey 返回秒传成功时data中的ukey
+func (r *PrepareV4Response) UKey() string {
+	if dataMap, ok := r.Data.(map[string]interface{}); ok {
+		if ukey, ok := dataMap["ukey"].(string); ok {
+			return ukey
+		}
+	}
+	return ""
+}
+
+// PrepareV4 调用 action=prepare_v4 检查文件是否可以秒传
+func (c *Client) PrepareV4(ctx context.Context, req PrepareV4Request) (*PrepareV4Response, error) {
+	form := url.Values{}
+	form.Set("action", "prepare_v4")
+	form.Set("sha1", req.SHA1)
+	form.Set("filename", req.Filename)
+	form.Set("filesize", strconv.FormatInt(req.Filesize, 10))
+	form.Set("model", strconv.Itoa(req.Model))
+	form.Set("skip_upload", strconv.Itoa(req.SkipUpload))
+	form.Set("token", c.Token)
+	if req.MrID != "" {
+		form.Set("mr_id", req.MrID)
+	}
+
+	var resp genericResponse
+	if err := c.postForm(ctx, c.endpoint("/file"), form, &resp); err != nil {
+		return nil, err
+	}
+	return &PrepareV4Response{Status: resp.Status, Data: resp.Data}, nil
+}
+
+// ========== 上传服务器 /app/upload_slice ==========
+
+// SlicePrepareRequest 分片状态查询 (action=prepare) 请求参数
+type SlicePrepareRequest struct {
+	UpToken   string
+	UToken    string
+	SHA1      string
+	Filename  string
+	Filesize  int64
+	SliceSize int
+	MrID      string
+	Model     int
+}
+
+// SliceInfo 状态3时返回的分片信息
+type SliceInfo struct {
+	Total int // 总分片数
+	Wait  int // 待上传分片数
+	Next  int // 下一个需要上传的分片编号，-1表示未返回
+}
+
+// SlicePrepareResponse 分片状态查询响应
+//
+// 状态说明: 1=上传完成 2=暂无可上传分片 3=返回待上传分片 6=文件已存在
+// 7=失败(data为错误代码) 8=合并完成 9=合并进行中
+type SlicePrepareResponse struct {
+	Status int
+	Data   interface{}
+	Debug  interface{}
+}
+
+// SliceInfo 解析状态3返回的分片信息
+func (r *SlicePrepareResponse) SliceInfo() (SliceInfo, bool) {
+	info := SliceInfo{Next: -1}
+	dataMap, ok := r.Data.(map[string]interface{})
+	if !ok {
+		return info, false
+	}
+	if total, ok := dataMap["total"].(float64); ok {
+		info.Total = int(total)
+	}
+	if wait, ok := dataMap["wait"].(float64); ok {
+		info.Wait = int(wait)
+	}
+	if next, ok := dataMap["next"].(float64); ok {
+		info.Next = int(next)
+	}
+	return info, true
+}
+
+// PrepareSlice 向上传服务器查询分片上传状态
+func (c *Client) PrepareSlice(ctx context.Context, uploadServer string, req SlicePrepareRequest) (*SlicePrepareResponse, error) {
+	form := url.Values{}
+	form.Set("token", c.Token)
+	form.Set("uptoken", req.UpToken)
+	form.Set("action", "prepare")
+	form.Set("sha1", req.SHA1)
+	form.Set("filename", req.Filename)
+	form.Set("filesize", strconv.FormatInt(req.Filesize, 10))
+	form.Set("slice_size", strconv.Itoa(req.SliceSize))
+	form.Set("utoken", req.UToken)
+	form.Set("mr_id", req.MrID)
+	form.Set("model", strconv.Itoa(req.Model))
+
+	var resp genericResponse
+	if err := c.postForm(ctx, uploadServer+"/app/upload_slice", form, &resp); err != nil {
+		return nil, err
+	}
+	return &SlicePrepareResponse{Status: resp.Status, Data: resp.Data, Debug: resp.Debug}, nil
+}
+
+// SliceUploadRequest 分片上传 (action=upload_slice) 请求参数
+type SliceUploadRequest struct {
+	UpToken  string
+	Filename string
+	Index    int
+	Data     io.Reader // 分片内容，以流的方式写入请求体
+	Size     int64     // 分片字节数，用于计算Content-Length；小于0表示未知（使用分块传输）
+
+	// Progress 可选，分片数据写入请求体时回调本次请求已发送的分片字节数
+	Progress func(sent int64)
+}
+
+// SliceUploadResponse 分片上传响应，状态1/2/3/5均表示分片已被接收
+type SliceUploadResponse struct {
+	Status int
+}
+
+// Accepted 分片是否已被服务器接收
+func (r *SliceUploadResponse) Accepted() bool {
+	switch r.Status {
+	case 1, 2, 3, 5:
+		return true
+	}
+	return false
+}
+
+// UploadSlice 向上传服务器上传一个分片
+//
+// 请求体通过 io.Pipe 边读边发，不在内存中缓存整个分片；Size 已知时设置准确的 Content-Length
+func (c *Client) UploadSlice(ctx context.Context, uploadServer string, req SliceUploadRequest) (*SliceUploadResponse, error) {
+	// 随机生成本次请求使用的boundary
+	form := multipart.NewWriter(io.Discard)
+	boundary := form.Boundary()
+	contentType := form.FormDataContentType()
+
+	// 先用空数据生成一次表单以计算multipart自身的开销
+	contentLength := int64(-1)
+	if req.Size >= 0 {
+		counter := &countingWriter{}
+		if _, err := writeSliceForm(counter, boundary, req, strings.NewReader("")); err != nil {
+			return nil, err
+		}
+		contentLength = counter.n + req.Size
+	}
+
+	pr, pw := io.Pipe()
+	writeErr := make(chan error, 1)
+	go func() {
+		data := req.Data
+		if req.Progress != nil {
+			data = &progressReader{r: data, progress: req.Progress}
+		}
+		_, err := writeSliceForm(pw, boundary, req, data)
+		pw.CloseWithError(err)
+		writeErr <- err
+	}()
+
+	endpoint := uploadServer + "/app/upload_slice"
+	c.logf("请求URL: %s", endpoint)
+	c.logf("请求方法: POST")
+	c.logf("Content-Type: %s", contentType)
+	c.logf("Content-Length: %d", contentLength)
+	c.logf("分片索引: %d", req.Index)
+	c.logf("uptoken: %s", req.UpToken)
+	c.logf("filename: %s", req.Filename)
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", endpoint, pr)
+	if err != nil {
+		pr.Close()
+		<-writeErr
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", contentType)
+	httpReq.ContentLength = contentLength
+
+	var resp genericResponse
+	err = c.do(httpReq, &resp)
+
+	// 确保写入协程退出；读取本地分片数据失败时返回该错误而不是网络错误，避免被当作临时错误重试
+	pr.Close()
+	if werr := <-writeErr; err != nil && werr != nil && !errors.Is(werr, io.ErrClosedPipe) {
+		return nil, fmt.Errorf("读取分片数据失败: %w", werr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &SliceUploadResponse{Status: resp.Status}, nil
+}
+
+// writeSliceForm 按服务器要求的字段顺序写出分片上传的multipart表单，返回写入的分片字节数
+func writeSliceForm(w io.Writer, boundary string, req SliceUploadRequest, data io.Reader) (int64, error) {
+	writer := multipart.NewWriter(w)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return 0, err
+	}
+
+	// 添加文件数据
+	fileWriter, err := writer.CreateFormFile("filedata", "slice")
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(fileWriter, data)
+	if err != nil {
+		return n, err
+	}
+
+	// 添加表单字段
+	writer.WriteField("uptoken", req.UpToken)
+	writer.WriteField("filename", req.Filename)
+	writer.WriteField("index", strconv.Itoa(req.Index))
+	writer.WriteField("action", "upload_slice")
+	return n, writer.Close()
+}
+
+// countingWriter 只统计写入字节数
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// progressReader 统计已读取（即已写入请求体）的字节数并回调
+type progressReader struct {
+	r        io.Reader
+	sent     int64
+	progress func(sent int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.progress(p.sent)
+	}
+	return n, err
+}
+
+// ========== 内部实现 ==========
+
+// genericResponse data结构不固定的响应
+type genericResponse struct {
+	Status int         `json:"status"`
+	Data   interface{} `json:"data"`
+	Debug  interface{} `json:"debug,omitempty"`
+}
+
+// rawResponse 延迟解析data的响应
+type rawResponse struct {
+	Status int             `json:"status"`
+	Data   json.RawMessage `json:"data"`
+	Msg    string          `json:"msg"`
+}
+
+// postForm 发送 application/x-www-form-urlencoded 请求并解析JSON响应
+func (c *Client) postForm(ctx context.Context, endpoint string, form url.Values, out interface{}) error {
+	encoded := form.Encode()
+
+	c.logf("请求URL: %s", endpoint)
+	c.logf("请求方法: POST")
+	c.logf("Content-Type: application/x-www-form-urlencoded")
+	c.logf("请求参数: %s", credential.RedactFields(encoded))
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(encoded))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return c.do(req, out)
+}
+
+// do 发送请求，检查HTTP状态码并解析JSON响应
+func (c *Client) do(req *http.Request, out interface{}) error {
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		c.logf("发送请求失败: %v", err)
+		return &requestError{err: err}
+	}
+	defer resp.Body.Close()
+
+	c.logf("HTTP状态码: %d", resp.StatusCode)
+
+	if resp.StatusCode != http.StatusOK {
+		httpErr := &HTTPError{StatusCode: resp.StatusCode}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			httpErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return httpErr
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.logf("读取响应失败: %v", err)
+		return fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	c.logf("响应内容: %s", credential.RedactFields(string(body)))
+
+	if err := json.Unmarshal(body, out); err != nil {
+		c.logf("JSON解析失败: %v", err)
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	return nil
+}
+
+// endpoint 拼接API服务器地址和路径
+func (c *Client) endpoint(path string) string {
+	return strings.TrimRight(c.BaseURL, "/") + path
+}
+
+// logf 输出调试日志
+func (c *Client) logf(format string, args ...interface{}) {
+	if c.Logf != nil {
+		c.Logf(format, args...)
+	}
+}
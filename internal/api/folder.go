@@ -0,0 +1,194 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ========== /meetingroom ==========
+
+// RootFolderID 根目录的目录ID
+const RootFolderID = "0"
+
+// Folder 钛盘文件夹（API中称为 room），MrID 即上传时使用的 mr_id
+type Folder struct {
+	MrID   string
+	Name   string
+	Parent string // 上级文件夹的目录ID
+}
+
+// rawFolder 文件夹的原始JSON，mr_id 和 parent 可能是字符串或数字
+type rawFolder struct {
+	MrID   json.RawMessage `json:"mr_id"`
+	Name   string          `json:"name"`
+	Parent json.RawMessage `json:"parent"`
+}
+
+func (r rawFolder) folder() Folder {
+	return Folder{MrID: jsonID(r.MrID), Name: r.Name, Parent: jsonID(r.Parent)}
+}
+
+// jsonID 将字符串或数字形式的ID统一转为字符串
+func jsonID(raw json.RawMessage) string {
+	raw = bytes.TrimSpace(raw)
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var n json.Number
+	if json.Unmarshal(raw, &n) == nil {
+		return n.String()
+	}
+	return ""
+}
+
+// FolderListResponse subroom_list 响应
+type FolderListResponse struct {
+	Status  int
+	Msg     string
+	Folders []Folder // 仅在 Status == 1 时有效
+}
+
+// ListFolders 调用 action=subroom_list 获取文件夹下的子文件夹，parentID 为 "0" 时列出根目录
+func (c *Client) ListFolders(ctx context.Context, parentID string) (*FolderListResponse, error) {
+	form := url.Values{}
+	form.Set("action", "subroom_list")
+	form.Set("mr_id", parentID)
+	form.Set("token", c.Token)
+
+	var raw rawResponse
+	if err := c.postForm(ctx, c.endpoint("/meetingroom"), form, &raw); err != nil {
+		return nil, err
+	}
+
+	resp := &FolderListResponse{Status: raw.Status, Msg: raw.Msg}
+	if raw.Status != 1 {
+		return resp, nil
+	}
+
+	// 没有子文件夹时data可能为空或非数组
+	var folders []rawFolder
+	if len(bytes.TrimSpace(raw.Data)) > 0 && json.Unmarshal(raw.Data, &folders) != nil {
+		return nil, fmt.Errorf("解析文件夹列表失败: %s", string(raw.Data))
+	}
+	for _, folder := range folders {
+		if f := folder.folder(); f.MrID != "" {
+			resp.Folders = append(resp.Folders, f)
+		}
+	}
+	return resp, nil
+}
+
+// CreateFolderResponse create 响应
+type CreateFolderResponse struct {
+	Status int
+	Msg    string
+	Folder Folder // 仅在 Status == 1 时有效
+}
+
+// CreateFolder 调用 action=create 在 parentID 下创建文件夹
+func (c *Client) CreateFolder(ctx context.Context, parentID, name string) (*CreateFolderResponse, error) {
+	form := url.Values{}
+	form.Set("action", "create")
+	form.Set("parent", parentID)
+	form.Set("name", name)
+	form.Set("token", c.Token)
+
+	var raw rawResponse
+	if err := c.postForm(ctx, c.endpoint("/meetingroom"), form, &raw); err != nil {
+		return nil, err
+	}
+
+	resp := &CreateFolderResponse{Status: raw.Status, Msg: raw.Msg}
+	if raw.Status != 1 {
+		return resp, nil
+	}
+
+	// data 可能是完整的文件夹信息，也可能只有新文件夹的目录ID
+	var folder rawFolder
+	if json.Unmarshal(raw.Data, &folder) == nil && jsonID(folder.MrID) != "" {
+		resp.Folder = folder.folder()
+	} else {
+		resp.Folder.MrID = jsonID(raw.Data)
+	}
+	if resp.Folder.MrID == "" {
+		return nil, fmt.Errorf("创建文件夹的响应中缺少目录ID: %s", string(raw.Data))
+	}
+	if resp.Folder.Name == "" {
+		resp.Folder.Name = name
+	}
+	if resp.Folder.Parent == "" {
+		resp.Folder.Parent = parentID
+	}
+	return resp, nil
+}
+
+// FolderNotFoundError 路径中的文件夹不存在
+type FolderNotFoundError struct {
+	Path string // 不存在的那一级文件夹的完整路径
+}
+
+func (e *FolderNotFoundError) Error() string {
+	return fmt.Sprintf("文件夹不存在: %s", e.Path)
+}
+
+// SplitFolderPath 将 "/Projects/2026" 形式的路径拆分为各级文件夹名，忽略多余的斜杠
+func SplitFolderPath(path string) []string {
+	var names []string
+	for _, name := range strings.Split(path, "/") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// JoinFolderPath 将各级文件夹名拼接为 "/Projects/2026" 形式的路径，根目录为 "/"
+func JoinFolderPath(names []string) string {
+	return "/" + strings.Join(names, "/")
+}
+
+// ResolveFolderPath 从根目录逐级查找路径对应的文件夹，返回最后一级的目录ID，根路径返回 "0"
+// create 为 true 时依次创建不存在的文件夹，否则返回 *FolderNotFoundError
+func (c *Client) ResolveFolderPath(ctx context.Context, path string, create bool) (string, error) {
+	names := SplitFolderPath(path)
+	current := RootFolderID
+	for i, name := range names {
+		listResp, err := c.ListFolders(ctx, current)
+		if err != nil {
+			return "", err
+		}
+		if listResp.Status != 1 {
+			return "", fmt.Errorf("获取文件夹列表失败 (状态码: %d): %s", listResp.Status, listResp.Msg)
+		}
+
+		next := ""
+		for _, folder := range listResp.Folders {
+			if folder.Name == name {
+				next = folder.MrID
+				break
+			}
+		}
+
+		if next == "" {
+			if !create {
+				return "", &FolderNotFoundError{Path: JoinFolderPath(names[:i+1])}
+			}
+			c.logf("创建文件夹: %s", JoinFolderPath(names[:i+1]))
+			createResp, err := c.CreateFolder(ctx, current, name)
+			if err != nil {
+				return "", err
+			}
+			if createResp.Status != 1 {
+				return "", fmt.Errorf("创建文件夹 %s 失败 (状态码: %d): %s", JoinFolderPath(names[:i+1]), createResp.Status, createResp.Msg)
+			}
+			next = createResp.Folder.MrID
+		}
+		current = next
+	}
+	return current, nil
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultRetries 默认单个请求的最大重试次数
+	DefaultRetries = 5
+	// DefaultRetryMaxWait 默认两次重试之间的最长等待时间
+	DefaultRetryMaxWait = 30 * time.Second
+	// DefaultRetryDeadline 默认单个请求（含全部重试）的最长耗时
+	DefaultRetryDeadline = 10 * time.Minute
+
+	// retryBaseWait 首次重试前的基础等待时间，之后按指数增长
+	retryBaseWait = time.Second
+)
+
+// RetryPolicy 请求重试策略：对网络错误、HTTP 5xx 和 429 使用带抖动的指数退避重试
+type RetryPolicy struct {
+	MaxRetries int           // 最大重试次数，0表示不重试
+	MaxWait    time.Duration // 两次重试之间的最长等待时间，0表示立即重试
+	Deadline   time.Duration // 从首次请求开始计算的总时限，0表示不限制
+}
+
+// DefaultRetryPolicy 返回默认重试策略
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: DefaultRetries,
+		MaxWait:    DefaultRetryMaxWait,
+		Deadline:   DefaultRetryDeadline,
+	}
+}
+
+// IsRetryable 判断错误是否为可重试的临时错误
+// 上下文取消、响应解析失败等永久性错误不重试
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 500 || httpErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	// 请求未得到HTTP响应（连接失败、连接被重置等）
+	var netErr *requestError
+	return errors.As(err, &netErr)
+}
+
+// Do 执行op，遇到可重试错误时按策略等待后重试，logf 用于输出每次重试的调试信息（可为nil）
+func (p RetryPolicy) Do(ctx context.Context, logf func(format string, args ...interface{}), op func() error) error {
+	start := time.Now()
+
+	for attempt := 0; ; attempt++ {
+		err := op()
+		if err == nil || !IsRetryable(err) || ctx.Err() != nil {
+			return err
+		}
+		if attempt >= p.MaxRetries {
+			if attempt == 0 {
+				return err
+			}
+			return fmt.Errorf("重试%d次后仍然失败: %w", attempt, err)
+		}
+
+		wait := p.backoff(attempt, err)
+		if p.Deadline > 0 && time.Since(start)+wait > p.Deadline {
+			return fmt.Errorf("超过重试时限%v: %w", p.Deadline, err)
+		}
+
+		if logf != nil {
+			logf("请求失败，%v后进行第%d/%d次重试: %v", wait.Round(time.Millisecond), attempt+1, p.MaxRetries, err)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff 计算第attempt次重试前的等待时间（等待上限内随机抖动，避免多个线程同时重试）
+// 等待上限为0时立即重试，Retry-After 同样不超过该上限
+func (p RetryPolicy) backoff(attempt int, err error) time.Duration {
+	maxWait := p.MaxWait
+	if maxWait <= 0 {
+		return 0
+	}
+
+	// 服务器通过Retry-After指定了等待时间时优先使用
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.RetryAfter > 0 {
+		if httpErr.RetryAfter > maxWait {
+			return maxWait
+		}
+		return httpErr.RetryAfter
+	}
+
+	wait := retryBaseWait << uint(attempt)
+	if wait <= 0 || wait > maxWait {
+		wait = maxWait
+	}
+
+	// 在 [wait/2, wait] 区间内随机取值
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(wait-half)+1))
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoffJitterBounds(t *testing.T) {
+	p := RetryPolicy{MaxRetries: 10, MaxWait: 10 * time.Second}
+	tests := []struct {
+		attempt int
+		wait    time.Duration // 抖动前的等待时间
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{3, 8 * time.Second},
+		{4, 10 * time.Second},  // 超过上限
+		{70, 10 * time.Second}, // 移位溢出
+	}
+	err := &HTTPError{StatusCode: http.StatusServiceUnavailable}
+	for _, tt := range tests {
+		for i := 0; i < 200; i++ {
+			got := p.backoff(tt.attempt, err)
+			if got < tt.wait/2 || got > tt.wait {
+				t.Fatalf("backoff(%d) = %v, want in [%v, %v]", tt.attempt, got, tt.wait/2, tt.wait)
+			}
+		}
+	}
+}
+
+func TestBackoffZeroMaxWait(t *testing.T) {
+	p := RetryPolicy{MaxRetries: 3}
+	errs := []error{
+		&HTTPError{StatusCode: http.StatusBadGateway},
+		&HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: 5 * time.Second},
+	}
+	for _, err := range errs {
+		if got := p.backoff(2, err); got != 0 {
+			t.Errorf("backoff with MaxWait 0 = %v, want 0", got)
+		}
+	}
+}
+
+func TestBackoffRetryAfter(t *testing.T) {
+	p := RetryPolicy{MaxRetries: 3, MaxWait: 10 * time.Second}
+	tests := []struct {
+		name string
+		err  error
+		want time.Duration
+	}{
+		{"优先于指数退避", &HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: 3 * time.Second}, 3 * time.Second},
+		{"不超过等待上限", &HTTPError{StatusCode: http.StatusServiceUnavailable, RetryAfter: time.Minute}, 10 * time.Second},
+		{"包装后的错误", fmt.Errorf("分片 1: %w", &HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: 2 * time.Second}), 2 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 第5次重试的指数退避为16s，结果等于 Retry-After 说明没有使用退避时间
+			if got := p.backoff(4, tt.err); got != tt.want {
+				t.Errorf("backoff = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"网络错误", &requestError{err: errors.New("connection reset")}, true},
+		{"HTTP 500", &HTTPError{StatusCode: http.StatusInternalServerError}, true},
+		{"HTTP 503", &HTTPError{StatusCode: http.StatusServiceUnavailable}, true},
+		{"HTTP 429", &HTTPError{StatusCode: http.StatusTooManyRequests}, true},
+		{"HTTP 401", &HTTPError{StatusCode: http.StatusUnauthorized}, false},
+		{"HTTP 403", &HTTPError{StatusCode: http.StatusForbidden}, false},
+		{"HTTP 404", &HTTPError{StatusCode: http.StatusNotFound}, false},
+		{"包装后的HTTP 502", fmt.Errorf("上传失败: %w", &HTTPError{StatusCode: http.StatusBadGateway}), true},
+		{"取消", context.Canceled, false},
+		{"超时", context.DeadlineExceeded, false},
+		{"响应解析失败", errors.New("解析响应失败"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// prepareServer 模拟上传服务器，依次返回 responses 中的HTTP状态码和响应内容，之后重复最后一个
+func prepareServer(t *testing.T, responses ...func(w http.ResponseWriter)) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1))
+		if n > len(responses) {
+			n = len(responses)
+		}
+		responses[n-1](w)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func status(code int, body string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.WriteHeader(code)
+		w.Write([]byte(body))
+	}
+}
+
+func TestDoFailsFast(t *testing.T) {
+	tests := []struct {
+		name     string
+		response func(w http.ResponseWriter)
+		status   int // 期望的业务状态码，0表示期望返回错误
+	}{
+		{"状态7认证错误", status(http.StatusOK, `{"status":7,"data":10}`), 7},
+		{"HTTP 401", status(http.StatusUnauthorized, ``), 0},
+		{"HTTP 403", status(http.StatusForbidden, ``), 0},
+		{"响应格式错误", status(http.StatusOK, `<html>`), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := prepareServer(t, tt.response)
+			client := NewClient("token")
+			policy := RetryPolicy{MaxRetries: 5}
+
+			var resp *SlicePrepareResponse
+			err := policy.Do(context.Background(), nil, func() error {
+				var err error
+				resp, err = client.PrepareSlice(context.Background(), server.URL, SlicePrepareRequest{})
+				return err
+			})
+			if got := atomic.LoadInt32(calls); got != 1 {
+				t.Errorf("requests = %d, want 1 (no retry)", got)
+			}
+			if tt.status == 0 {
+				if err == nil {
+					t.Fatalf("Do returned nil error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Do: %v", err)
+			}
+			if resp.Status != tt.status {
+				t.Errorf("status = %d, want %d", resp.Status, tt.status)
+			}
+		})
+	}
+}
+
+func TestDoRetriesTransientErrors(t *testing.T) {
+	server, calls := prepareServer(t,
+		status(http.StatusServiceUnavailable, ``),
+		status(http.StatusTooManyRequests, ``),
+		status(http.StatusOK, `{"status":1,"data":"ukey"}`),
+	)
+	client := NewClient("token")
+	policy := RetryPolicy{MaxRetries: 5}
+
+	var retries int
+	var resp *SlicePrepareResponse
+	err := policy.Do(context.Background(), func(string, ...interface{}) { retries++ }, func() error {
+		var err error
+		resp, err = client.PrepareSlice(context.Background(), server.URL, SlicePrepareRequest{})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if resp.Status != 1 || atomic.LoadInt32(calls) != 3 || retries != 2 {
+		t.Errorf("status = %d, requests = %d, retries = %d; want 1, 3, 2", resp.Status, atomic.LoadInt32(calls), retries)
+	}
+}
+
+func TestDoGivesUpAfterMaxRetries(t *testing.T) {
+	server, calls := prepareServer(t, status(http.StatusBadGateway, ``))
+	client := NewClient("token")
+	policy := RetryPolicy{MaxRetries: 2}
+
+	err := policy.Do(context.Background(), nil, func() error {
+		_, err := client.PrepareSlice(context.Background(), server.URL, SlicePrepareRequest{})
+		return err
+	})
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("Do error = %v, want wrapped HTTP 502", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+}
+
+func TestDoDeadline(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 5, MaxWait: time.Minute, Deadline: time.Second}
+	calls := 0
+	err := policy.Do(context.Background(), nil, func() error {
+		calls++
+		return &HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: 30 * time.Second}
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("Do = %v after %d calls, want deadline error after 1 call", err, calls)
+	}
+}
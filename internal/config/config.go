@@ -0,0 +1,336 @@
+// Package config 读写 CLI、GUI 和更新检查共用的配置文件 ~/.tmplink_config.json
+//
+// 配置文件记录版本号，读取时把旧版本的配置依次迁移到当前版本。保存时在文件锁内重新读取配置文件，
+// 只写入本进程修改过的字段，同时运行的 CLI、GUI 和更新检查不会互相覆盖对方的修改
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"tmplink_uploader/internal/api"
+	"tmplink_uploader/internal/credential"
+	"tmplink_uploader/internal/filelock"
+)
+
+// FileName 配置文件名，保存在用户主目录下
+const FileName = ".tmplink_config.json"
+
+// CurrentVersion 当前的配置文件版本，等于 migrations 的数量
+const CurrentVersion = 2
+
+// profilesKey 账号配置在配置文件中的字段名，保存时按账号分别合并
+const profilesKey = "profiles"
+
+// Config 配置文件的内容
+type Config struct {
+	Version            int       `json:"version"`              // 配置文件版本，未记录版本号的为旧版本程序写入的配置
+	LegacyToken        string    `json:"token,omitempty"`      // 旧版本保存的明文Token，迁移到凭据存储后删除
+	UploadServer       string    `json:"upload_server"`        // 上传服务器，留空自动选择
+	SelectedServerName string    `json:"selected_server_name"` // 选中的服务器名称
+	ChunkSize          int       `json:"chunk_size"`           // 分块大小（MB）
+	MaxConcurrent      int       `json:"max_concurrent"`       // 每个文件的并发上传分片数
+	ParallelUploads    int       `json:"parallel_uploads"`     // GUI同时上传的文件数
+	QuickUpload        bool      `json:"quick_upload"`
+	SkipUpload         bool      `json:"skip_upload"`
+	LastUpdateCheck    time.Time `json:"last_update_check"`    // 最后一次更新检查时间
+	Language           string    `json:"language"`             // 界面语言，空表示尚未选择
+	APIServer          string    `json:"api_server,omitempty"` // API服务器地址，留空使用默认值
+	LinkBase           string    `json:"link_base,omitempty"`  // 下载链接前缀，留空使用默认值
+	Model              int       `json:"model"`                // 默认文件有效期
+	MrID               string    `json:"mr_id"`                // 默认目录ID
+	// 账号配置：顶层的上传服务器、分块大小、文件有效期和目录ID属于默认账号
+	Profile  string             `json:"profile,omitempty"`  // 当前使用的账号配置，空为默认账号
+	Profiles map[string]Profile `json:"profiles,omitempty"` // 其他账号的配置
+
+	Token string `json:"-"` // 当前账号的Token，保存在凭据存储中，不写入配置文件
+
+	activeProfile string                     // 正在使用的账号配置，空为默认账号
+	defaults      Profile                    // 使用其他账号时，默认账号的配置
+	loaded        map[string]json.RawMessage // 读取或上次保存时的字段，保存时只写入与之不同的字段
+	exists        bool                       // 读取自配置文件，为 false 时是默认配置
+}
+
+// Default 配置文件不存在时使用的默认配置
+func Default() Config {
+	return Config{
+		Version:         CurrentVersion,
+		ChunkSize:       3,
+		MaxConcurrent:   5,
+		ParallelUploads: 2,
+		QuickUpload:     true,
+		MrID:            "0",
+	}
+}
+
+// Path 配置文件路径 ~/.tmplink_config.json
+func Path() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return FileName
+	}
+	return filepath.Join(homeDir, FileName)
+}
+
+// credentialStore 保存API Token的凭据存储
+var credentialStore = credential.Default()
+
+// migrations 依次迁移旧版本的配置，migrations[i] 把版本 i 的配置迁移到版本 i+1
+var migrations = []func(*Config) error{
+	cleanLegacyDefaults,   // 0 → 1
+	moveTokenToCredential, // 1 → 2
+}
+
+// cleanLegacyDefaults 清理旧版本GUI保存的默认值：API服务器地址被保存为上传服务器，默认服务器的名称被保存为选中的服务器
+func cleanLegacyDefaults(c *Config) error {
+	if c.UploadServer == api.DefaultBaseURL {
+		c.UploadServer = ""
+	}
+	if c.SelectedServerName == "默认 (自动选择)" || c.SelectedServerName == "默认服务器" {
+		c.SelectedServerName = ""
+	}
+	return nil
+}
+
+// moveTokenToCredential 把旧版本保存的明文Token作为默认账号的Token移入凭据存储
+func moveTokenToCredential(c *Config) error {
+	if c.LegacyToken == "" {
+		return nil
+	}
+	if err := credentialStore.Save("", c.LegacyToken); err != nil {
+		return fmt.Errorf("迁移Token到凭据存储失败: %w", err)
+	}
+	c.LegacyToken = ""
+	return nil
+}
+
+// migrate 把配置迁移到当前版本，某一步失败时停在该版本，之后读取时重试
+func (c *Config) migrate() error {
+	if c.Version < 0 {
+		c.Version = 0
+	}
+	for c.Version < CurrentVersion {
+		if err := migrations[c.Version](c); err != nil {
+			return err
+		}
+		c.Version++
+	}
+	return nil
+}
+
+// normalize 补全缺失或无效的值
+func (c *Config) normalize() {
+	if c.ChunkSize <= 0 {
+		c.ChunkSize = 3
+	}
+	if c.MaxConcurrent <= 0 {
+		c.MaxConcurrent = 5
+	}
+	if c.ParallelUploads <= 0 {
+		c.ParallelUploads = 2
+	}
+	if c.MrID == "" {
+		c.MrID = "0"
+	}
+}
+
+// Load 读取配置文件，旧版本的配置迁移后写回；配置文件不存在时返回默认配置
+// 返回错误时仍返回可以使用的配置（读取失败时为默认配置，迁移失败时为迁移前的配置）
+func Load() (Config, error) {
+	path := Path()
+	c, _, err := read(path)
+	if err == nil && c.Version < CurrentVersion {
+		// 其他进程可能同时在迁移，在文件锁内重新读取
+		err = withLock(path, func() error {
+			var raw map[string]json.RawMessage
+			var err error
+			if c, raw, err = read(path); err != nil || c.Version >= CurrentVersion {
+				return err
+			}
+			migrateErr := c.migrate()
+			// 迁移中途失败时同样写回已完成的步骤
+			fields, err := c.fields()
+			if err != nil {
+				return err
+			}
+			for key, value := range fields {
+				raw[key] = value
+			}
+			if c.LegacyToken == "" {
+				delete(raw, "token")
+			}
+			if err := write(path, raw); err != nil {
+				return err
+			}
+			return migrateErr
+		})
+	}
+	c.normalize()
+	c.loaded, _ = c.fields()
+	return c, err
+}
+
+// read 读取并解析配置文件，同时返回原始的字段用于保留本程序不认识的字段
+// 配置文件不存在时返回默认配置
+func read(path string) (Config, map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Default(), map[string]json.RawMessage{}, nil
+	}
+	if err != nil {
+		return Default(), nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Default(), nil, fmt.Errorf("配置文件格式错误: %w", err)
+	}
+	c := Default()
+	c.Version = 0 // 未记录版本号的为旧版本
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Default(), nil, fmt.Errorf("配置文件格式错误: %w", err)
+	}
+	if raw == nil {
+		raw = map[string]json.RawMessage{}
+	}
+	c.exists = true
+	return c, raw, nil
+}
+
+// Save 保存配置，Token单独保存到凭据存储；使用其他账号时，当前的值写回该账号的配置
+// 在文件锁内重新读取配置文件，只写入读取后修改过的字段，其他进程在此期间的修改和新版本添加的字段保持不变
+func (c *Config) Save() error {
+	fields, err := c.fields()
+	if err != nil {
+		return err
+	}
+	path := Path()
+	err = withLock(path, func() error {
+		if c.Token != "" {
+			if err := credentialStore.Save(c.activeProfile, c.Token); err != nil {
+				return fmt.Errorf("保存Token失败: %w", err)
+			}
+		}
+
+		base := c.loaded
+		_, disk, err := read(path)
+		if err != nil {
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &syntaxErr) && !errors.As(err, &typeErr) {
+				return err
+			}
+			disk = map[string]json.RawMessage{} // 配置文件已损坏，以当前配置覆盖
+		}
+		if len(disk) == 0 {
+			base = nil // 配置文件不存在或已损坏时写入全部字段
+		}
+
+		result := merge(disk, base, fields)
+		profiles := merge(object(disk[profilesKey]), object(base[profilesKey]), object(fields[profilesKey]))
+		delete(result, profilesKey)
+		if len(profiles) > 0 {
+			data, err := json.Marshal(profiles)
+			if err != nil {
+				return err
+			}
+			result[profilesKey] = data
+		}
+		return write(path, result)
+	})
+	if err != nil {
+		return err
+	}
+	c.loaded = fields
+	return nil
+}
+
+// fields 配置文件中的各个字段，使用其他账号时先把当前的值写回该账号
+func (c Config) fields() (map[string]json.RawMessage, error) {
+	c.leaveProfile()
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// merge 在 disk 的基础上写入 mine 中与 base 不同的字段，删除 base 中有而 mine 中没有的字段
+func merge(disk, base, mine map[string]json.RawMessage) map[string]json.RawMessage {
+	result := make(map[string]json.RawMessage, len(disk)+len(mine))
+	for key, value := range disk {
+		result[key] = value
+	}
+	for key, value := range mine {
+		if old, ok := base[key]; ok && bytes.Equal(old, value) {
+			continue
+		}
+		result[key] = value
+	}
+	for key := range base {
+		if _, ok := mine[key]; !ok {
+			delete(result, key)
+		}
+	}
+	return result
+}
+
+// object 解析JSON对象的字段，不是对象时返回 nil
+func object(data json.RawMessage) map[string]json.RawMessage {
+	var fields map[string]json.RawMessage
+	if len(data) > 0 {
+		json.Unmarshal(data, &fields)
+	}
+	return fields
+}
+
+// write 以0600权限写入临时文件后重命名，其他进程不会读到写了一半的配置文件
+func write(path string, fields map[string]json.RawMessage) error {
+	data, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("写入配置文件失败: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("写入配置文件失败: %w", err)
+	}
+	return nil
+}
+
+// withLock 持有配置文件的锁执行 fn，锁文件为配置文件同目录下的 .lock 文件
+func withLock(path string, fn func() error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return filelock.With(path, fn)
+}
+
+// LoadToken 从凭据存储读取当前账号的Token，默认账号尚未迁移的明文Token同样可以使用
+func (c *Config) LoadToken() error {
+	token, err := credentialStore.Load(c.activeProfile)
+	if token == "" && c.activeProfile == "" {
+		token = c.LegacyToken
+	}
+	c.Token = token
+	return err
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"tmplink_uploader/internal/api"
+	"tmplink_uploader/internal/credential"
+)
+
+// setupHome 使用临时目录作为用户主目录和凭据存储，返回配置文件路径
+func setupHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv(credential.PassphraseEnv, "")
+
+	store := credentialStore
+	credentialStore = credential.NewFileStore(filepath.Join(home, ".tmplink"))
+	t.Cleanup(func() { credentialStore = store })
+	return Path()
+}
+
+// writeFile 写入配置文件
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readRaw 读取配置文件的原始字段
+func readRaw(t *testing.T, path string) map[string]json.RawMessage {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestLoadMigratesLegacyToken(t *testing.T) {
+	path := setupHome(t)
+	writeFile(t, path, `{
+  "token": "legacy-token",
+  "upload_server": "`+api.DefaultBaseURL+`",
+  "selected_server_name": "默认服务器",
+  "chunk_size": 5,
+  "mr_id": "abc"
+}`)
+
+	c, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if c.Version != CurrentVersion {
+		t.Errorf("Version = %d, want %d", c.Version, CurrentVersion)
+	}
+	if c.LegacyToken != "" {
+		t.Errorf("LegacyToken = %q, want empty after migration", c.LegacyToken)
+	}
+	if c.UploadServer != "" || c.SelectedServerName != "" {
+		t.Errorf("legacy defaults not cleaned: upload_server=%q selected_server_name=%q", c.UploadServer, c.SelectedServerName)
+	}
+	if c.ChunkSize != 5 || c.MrID != "abc" {
+		t.Errorf("settings changed by migration: chunk_size=%d mr_id=%q", c.ChunkSize, c.MrID)
+	}
+
+	if err := c.LoadToken(); err != nil {
+		t.Fatalf("LoadToken: %v", err)
+	}
+	if c.Token != "legacy-token" {
+		t.Errorf("Token = %q, want legacy-token", c.Token)
+	}
+	if token, err := credentialStore.Load(""); err != nil || token != "legacy-token" {
+		t.Errorf("credential store token = %q, %v", token, err)
+	}
+
+	raw := readRaw(t, path)
+	if _, ok := raw["token"]; ok {
+		t.Errorf("plaintext token still in config file")
+	}
+	if string(raw["version"]) != strconv.Itoa(CurrentVersion) {
+		t.Errorf("version on disk = %s, want %d", raw["version"], CurrentVersion)
+	}
+}
+
+func TestSaveKeepsConcurrentChanges(t *testing.T) {
+	path := setupHome(t)
+	writeFile(t, path, `{"version": 2, "chunk_size": 3, "language": "zh"}`)
+
+	a, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a.ChunkSize = 8
+	a.SetProfile("work", Profile{ChunkSize: 10, MrID: "w"})
+	if err := a.Save(); err != nil {
+		t.Fatalf("a.Save: %v", err)
+	}
+	b.Language = "en"
+	b.SetProfile("team", Profile{ChunkSize: 20, MrID: "t"})
+	if err := b.Save(); err != nil {
+		t.Fatalf("b.Save: %v", err)
+	}
+
+	c, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ChunkSize != 8 {
+		t.Errorf("ChunkSize = %d, want 8 (lost by second save)", c.ChunkSize)
+	}
+	if c.Language != "en" {
+		t.Errorf("Language = %q, want en", c.Language)
+	}
+	if c.Profiles["work"].ChunkSize != 10 || c.Profiles["team"].ChunkSize != 20 {
+		t.Errorf("Profiles = %+v, want both work and team", c.Profiles)
+	}
+}
+
+func TestSaveKeepsUnknownFields(t *testing.T) {
+	path := setupHome(t)
+	writeFile(t, path, `{
+  "version": 2,
+  "chunk_size": 3,
+  "future_setting": {"enabled": true},
+  "profiles": {"work": {"chunk_size": 4, "mr_id": "0"}}
+}`)
+
+	c, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.ChunkSize = 6
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	raw := readRaw(t, path)
+	var future bytes.Buffer
+	if err := json.Compact(&future, raw["future_setting"]); err != nil || future.String() != `{"enabled":true}` {
+		t.Errorf("future_setting = %s, want it kept", raw["future_setting"])
+	}
+	if string(raw["chunk_size"]) != "6" {
+		t.Errorf("chunk_size = %s, want 6", raw["chunk_size"])
+	}
+	if _, ok := object(raw[profilesKey])["work"]; !ok {
+		t.Errorf("profile work lost: %s", raw[profilesKey])
+	}
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// ProfileEnv 选择账号配置的环境变量
+const ProfileEnv = "TMPLINK_PROFILE"
+
+// DefaultProfileName 默认账号的名称，默认账号的配置保存在配置文件的顶层字段中
+const DefaultProfileName = "default"
+
+// profileNamePattern 账号配置名称允许的字符
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,32}$`)
+
+// Profile 账号配置：每个账号单独保存的上传默认值，Token按账号保存在凭据存储中
+type Profile struct {
+	UploadServer       string `json:"upload_server"`
+	SelectedServerName string `json:"selected_server_name"`
+	ChunkSize          int    `json:"chunk_size"`
+	Model              int    `json:"model"`
+	MrID               string `json:"mr_id"`
+}
+
+// NewProfile 新账号配置的默认值
+func NewProfile() Profile {
+	return Profile{ChunkSize: 3, Model: 0, MrID: "0"}
+}
+
+// ProfileName 账号配置的显示名称，默认账号显示为 default
+func ProfileName(name string) string {
+	if name == "" {
+		return DefaultProfileName
+	}
+	return name
+}
+
+// ValidateProfileName 检查新账号配置的名称
+func ValidateProfileName(name string) error {
+	if name == DefaultProfileName {
+		return fmt.Errorf("%s 为默认账号的名称", DefaultProfileName)
+	}
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("账号配置名称只能包含字母、数字、'.'、'_' 和 '-'，最长32个字符: %s", name)
+	}
+	return nil
+}
+
+// ActiveProfile 正在使用的账号配置，空为默认账号
+func (c Config) ActiveProfile() string {
+	return c.activeProfile
+}
+
+// CurrentProfile 当前使用的账号配置的值
+func (c Config) CurrentProfile() Profile {
+	return Profile{
+		UploadServer:       c.UploadServer,
+		SelectedServerName: c.SelectedServerName,
+		ChunkSize:          c.ChunkSize,
+		Model:              c.Model,
+		MrID:               c.MrID,
+	}
+}
+
+// setCurrentProfile 用账号配置的值替换当前的值
+func (c *Config) setCurrentProfile(p Profile) {
+	if p.MrID == "" {
+		p.MrID = "0"
+	}
+	if p.ChunkSize <= 0 {
+		p.ChunkSize = 3
+	}
+	c.UploadServer = p.UploadServer
+	c.SelectedServerName = p.SelectedServerName
+	c.ChunkSize = p.ChunkSize
+	c.Model = p.Model
+	c.MrID = p.MrID
+}
+
+// UseProfile 切换到指定的账号配置，name 为空表示默认账号；保存配置时当前的值写回该账号
+// 不切换 Token，需要时调用 LoadToken 读取该账号的Token
+func (c *Config) UseProfile(name string) error {
+	if name == c.activeProfile {
+		return nil
+	}
+	profile, ok := c.Profiles[name]
+	if name != "" && !ok {
+		return fmt.Errorf("账号配置不存在: %s", name)
+	}
+	c.leaveProfile()
+	if name != "" {
+		c.defaults = c.CurrentProfile()
+		c.setCurrentProfile(profile)
+	}
+	c.activeProfile = name
+	return nil
+}
+
+// leaveProfile 把当前的值写回正在使用的账号，恢复默认账号的值
+func (c *Config) leaveProfile() {
+	if c.activeProfile == "" {
+		return
+	}
+	c.SetProfile(c.activeProfile, c.CurrentProfile())
+	c.setCurrentProfile(c.defaults)
+	c.activeProfile = ""
+}
+
+// SetProfile 添加或替换账号配置，复制 map 后修改，不影响其他 Config 副本
+func (c *Config) SetProfile(name string, profile Profile) {
+	profiles := make(map[string]Profile, len(c.Profiles)+1)
+	for n, p := range c.Profiles {
+		profiles[n] = p
+	}
+	profiles[name] = profile
+	c.Profiles = profiles
+}
+
+// RemoveProfile 删除账号配置，删除当前使用的账号时切换回默认账号
+func (c *Config) RemoveProfile(name string) {
+	profiles := make(map[string]Profile, len(c.Profiles))
+	for n, p := range c.Profiles {
+		if n != name {
+			profiles[n] = p
+		}
+	}
+	c.Profiles = profiles
+	if c.Profile == name {
+		c.Profile = ""
+	}
+}
+
+// ProfileNames 按名称排序的账号配置，默认账号（空名称）排在最前
+func (c Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{""}, names...)
+}
@@ -0,0 +1,223 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"tmplink_uploader/internal/api"
+	"tmplink_uploader/internal/credential"
+)
+
+// ProjectFileName 项目配置文件名，从当前目录向上逐级查找，使用找到的第一个
+const ProjectFileName = ".tmplink.json"
+
+// Source 设置值的来源，值越大优先级越高
+type Source int
+
+const (
+	SourceDefault Source = iota // 程序内置的默认值
+	SourceUser                  // 用户配置文件 ~/.tmplink_config.json（当前账号）或凭据存储
+	SourceProject               // 项目配置文件 .tmplink.json
+	SourceEnv                   // TMPLINK_* 环境变量
+	SourceFlag                  // 命令行参数
+)
+
+// Setting 可以在各层设置的项
+type Setting struct {
+	Key  string // 配置文件中的字段名
+	Flag string // 命令行参数名
+	Env  string // 环境变量名
+}
+
+// 支持分层设置的项
+var (
+	SettingToken        = Setting{Key: "token", Flag: "token", Env: credential.TokenEnv} // 不能写在项目配置文件中
+	SettingProfile      = Setting{Key: "profile", Flag: "profile", Env: ProfileEnv}
+	SettingAPIServer    = Setting{Key: "api_server", Flag: "api-server", Env: api.EnvAPIServer}
+	SettingLinkBase     = Setting{Key: "link_base", Flag: "link-base", Env: api.EnvLinkBase}
+	SettingUploadServer = Setting{Key: "upload_server", Flag: "upload-server", Env: "TMPLINK_UPLOAD_SERVER"}
+	SettingServerName   = Setting{Key: "selected_server_name", Flag: "server-name", Env: "TMPLINK_SERVER_NAME"}
+	SettingChunkSize    = Setting{Key: "chunk_size", Flag: "chunk-size", Env: "TMPLINK_CHUNK_SIZE"}
+	SettingConcurrency  = Setting{Key: "max_concurrent", Flag: "concurrency", Env: "TMPLINK_CONCURRENCY"}
+	SettingModel        = Setting{Key: "model", Flag: "model", Env: "TMPLINK_MODEL"}
+	SettingMrID         = Setting{Key: "mr_id", Flag: "mr-id", Env: "TMPLINK_MR_ID"}
+	SettingFolder       = Setting{Key: "folder", Flag: "folder", Env: "TMPLINK_FOLDER"} // 按路径指定的目标文件夹，用户配置中没有
+)
+
+// Value 解析出的设置值
+type Value struct {
+	Value  string
+	Source Source
+	Origin string // 来源的说明，如 "环境变量 TMPLINK_MODEL"
+}
+
+// Int 按整数解析设置值，出错时指明值的来源
+func (v Value) Int() (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(v.Value))
+	if err != nil {
+		return 0, fmt.Errorf("%s 的值不是有效的整数: %s", v.Origin, v.Value)
+	}
+	return n, nil
+}
+
+// Project 项目配置文件：放在仓库等目录中，为该目录下运行的上传指定默认值
+type Project struct {
+	Path   string
+	values map[string]string
+}
+
+// FindProject 从 dir 开始向上逐级查找项目配置文件，没有找到时返回 nil
+func FindProject(dir string) (*Project, error) {
+	for {
+		path := filepath.Join(dir, ProjectFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return LoadProject(path)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// LoadProject 读取项目配置文件，字段名与用户配置文件相同，另外支持按路径指定目标文件夹的 folder
+// 项目配置文件通常会提交到仓库中，不能包含Token
+func LoadProject(path string) (*Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取项目配置文件失败: %w", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("项目配置文件格式错误 %s: %w", path, err)
+	}
+	if _, ok := fields[SettingToken.Key]; ok {
+		return nil, fmt.Errorf("项目配置文件 %s 不能包含Token，请使用 %s 环境变量或 -set-token", path, credential.TokenEnv)
+	}
+
+	project := &Project{Path: path, values: make(map[string]string, len(fields))}
+	for key, value := range fields {
+		switch value := value.(type) {
+		case string:
+			project.values[key] = value
+		case float64:
+			if value != math.Trunc(value) {
+				return nil, fmt.Errorf("项目配置文件 %s 中 %s 的值不是整数: %v", path, key, value)
+			}
+			project.values[key] = strconv.FormatInt(int64(value), 10)
+		default:
+			return nil, fmt.Errorf("项目配置文件 %s 中 %s 的值无效: %v", path, key, value)
+		}
+	}
+	return project, nil
+}
+
+// userValue 用户配置中当前账号的值，Token为凭据存储中当前账号的Token
+func (c Config) userValue(key string) string {
+	switch key {
+	case SettingToken.Key:
+		return c.Token
+	case SettingProfile.Key:
+		return c.Profile
+	case SettingAPIServer.Key:
+		return c.APIServer
+	case SettingLinkBase.Key:
+		return c.LinkBase
+	case SettingUploadServer.Key:
+		return c.UploadServer
+	case SettingServerName.Key:
+		return c.SelectedServerName
+	case SettingChunkSize.Key:
+		return strconv.Itoa(c.ChunkSize)
+	case SettingConcurrency.Key:
+		return strconv.Itoa(c.MaxConcurrent)
+	case SettingModel.Key:
+		return strconv.Itoa(c.Model)
+	case SettingMrID.Key:
+		return c.MrID
+	}
+	return ""
+}
+
+// Resolver 按 命令行参数 > TMPLINK_* 环境变量 > 项目配置文件 > 用户配置文件 > 默认值 的顺序确定设置
+// 各层中的空字符串视为未设置
+type Resolver struct {
+	Flags   map[string]string // 命令行中显式指定的参数，键为参数名
+	Project *Project          // 项目配置文件，没有时为 nil
+	User    Config            // 用户配置
+}
+
+// ExplicitFlags 命令行中显式指定的参数，未指定的参数不覆盖其他层的设置
+func ExplicitFlags(flags *flag.FlagSet) map[string]string {
+	explicit := make(map[string]string)
+	flags.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = f.Value.String()
+	})
+	return explicit
+}
+
+// NewResolver 从当前目录向上查找项目配置文件，创建设置解析器
+func NewResolver(flags map[string]string, user Config) (*Resolver, error) {
+	resolver := &Resolver{Flags: flags, User: user}
+	if dir, err := os.Getwd(); err == nil {
+		project, err := FindProject(dir)
+		if err != nil {
+			return nil, err
+		}
+		resolver.Project = project
+	}
+	return resolver, nil
+}
+
+// UseProfile 用户配置切换到解析出的账号配置，返回账号配置的设置值
+func (r *Resolver) UseProfile() (Value, error) {
+	value := r.Resolve(SettingProfile, "")
+	name := value.Value
+	if name == DefaultProfileName {
+		name = ""
+	}
+	return value, r.User.UseProfile(name)
+}
+
+// Resolve 确定设置的值，各层都没有设置时使用默认值 def
+func (r *Resolver) Resolve(s Setting, def string) Value {
+	if value := strings.TrimSpace(r.Flags[s.Flag]); value != "" {
+		return Value{Value: value, Source: SourceFlag, Origin: "命令行参数 -" + s.Flag}
+	}
+	if value := strings.TrimSpace(os.Getenv(s.Env)); value != "" {
+		return Value{Value: value, Source: SourceEnv, Origin: "环境变量 " + s.Env}
+	}
+	if r.Project != nil {
+		if value := strings.TrimSpace(r.Project.values[s.Key]); value != "" {
+			return Value{Value: value, Source: SourceProject, Origin: "项目配置 " + r.Project.Path}
+		}
+	}
+	if s == SettingToken || r.User.exists {
+		if value := r.User.userValue(s.Key); value != "" {
+			return Value{Value: value, Source: SourceUser, Origin: r.userOrigin(s)}
+		}
+	}
+	return Value{Value: def, Source: SourceDefault, Origin: "默认值"}
+}
+
+// userOrigin 用户配置层的来源说明，按账号保存的设置注明使用的账号
+func (r *Resolver) userOrigin(s Setting) string {
+	origin := "用户配置 " + Path()
+	if s == SettingToken {
+		origin = "凭据存储"
+	}
+	switch s {
+	case SettingToken, SettingUploadServer, SettingServerName, SettingChunkSize, SettingModel, SettingMrID:
+		if r.User.activeProfile != "" {
+			origin += fmt.Sprintf(" (账号 %s)", r.User.activeProfile)
+		}
+	}
+	return origin
+}
@@ -0,0 +1,61 @@
+// Package credential 保存API Token等凭据，避免以明文写入配置文件或出现在命令行参数和日志中
+package credential
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// TokenEnv 向子进程传递Token的环境变量（命令行参数可以被其他用户通过 ps 看到，环境变量只有进程所有者可读）
+const TokenEnv = "TMPLINK_TOKEN"
+
+// PassphraseEnv 加密凭据文件使用的口令，未设置时使用自动生成的密钥文件
+const PassphraseEnv = "TMPLINK_CREDENTIAL_KEY"
+
+// Store 凭据存储，每个账号配置分别保存Token，profile 为空表示默认账号
+type Store interface {
+	// Load 读取账号保存的Token，没有保存时返回空字符串
+	Load(profile string) (string, error)
+	// Save 保存账号的Token，token 为空时删除该账号保存的Token
+	Save(profile, token string) error
+	// Describe 存储方式和位置的说明，用于显示配置状态
+	Describe() string
+}
+
+// DefaultDir 默认的凭据目录 ~/.tmplink
+func DefaultDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ".tmplink"
+	}
+	return filepath.Join(homeDir, ".tmplink")
+}
+
+// Default 默认的凭据存储：~/.tmplink 中的加密文件，无需桌面环境的密钥服务
+func Default() Store {
+	return NewFileStore(DefaultDir())
+}
+
+// Redact 遮盖凭据，只保留前4个字符用于辨认
+func Redact(secret string) string {
+	if secret == "" || strings.HasSuffix(secret, "***") {
+		return secret // 已经遮盖过
+	}
+	if len(secret) <= 8 {
+		return "***"
+	}
+	return secret[:4] + "***"
+}
+
+// secretFieldPattern 请求参数和JSON响应中的凭据字段
+var secretFieldPattern = regexp.MustCompile(`((?:^|[\s&?"])(?:token|utoken)(?:=|"\s*:\s*"))([^&"\s]+)`)
+
+// RedactFields 遮盖文本中 token=xxx、"utoken":"xxx" 形式的凭据字段，用于调试日志
+func RedactFields(text string) string {
+	return secretFieldPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := secretFieldPattern.FindStringSubmatch(match)
+		return parts[1] + Redact(parts[2])
+	})
+}
@@ -0,0 +1,302 @@
+package credential
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/pbkdf2"
+
+	"tmplink_uploader/internal/filelock"
+)
+
+// 加密文件的格式版本和密钥来源
+const (
+	fileVersion = 1
+
+	keyFromFile       = "file"       // 密钥文件中的随机密钥
+	keyFromPassphrase = "passphrase" // 由 TMPLINK_CREDENTIAL_KEY 口令派生的密钥
+
+	keySize          = 32 // AES-256
+	saltSize         = 16
+	pbkdf2Iterations = 200000
+)
+
+// additionalData 绑定到密文的附加数据，防止其他文件的密文被替换进来
+var additionalData = []byte("tmplink-credential-v1")
+
+// FileStore 使用 AES-256-GCM 加密的凭据文件
+//
+// 默认使用同目录下自动生成的密钥文件（权限0600），凭据文件单独泄露（如备份或分享配置目录）时无法解密；
+// 设置 TMPLINK_CREDENTIAL_KEY 后改为由口令派生密钥，不再依赖密钥文件
+type FileStore struct {
+	Path    string // 加密的凭据文件
+	KeyPath string // 未设置口令时使用的密钥文件
+}
+
+// NewFileStore 创建 dir 目录下的加密凭据文件存储
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{
+		Path:    filepath.Join(dir, "credentials.enc"),
+		KeyPath: filepath.Join(dir, "credentials.key"),
+	}
+}
+
+// fileEnvelope 凭据文件的内容
+type fileEnvelope struct {
+	Version   int    `json:"version"`
+	KeySource string `json:"key_source"`
+	Salt      []byte `json:"salt,omitempty"` // 仅口令派生密钥时使用
+	Nonce     []byte `json:"nonce"`
+	Data      []byte `json:"data"`
+}
+
+// fileSecrets 加密前的凭据
+type fileSecrets struct {
+	Token    string            `json:"token"`              // 默认账号的Token
+	Profiles map[string]string `json:"profiles,omitempty"` // 账号配置名称 -> Token
+}
+
+// get 读取账号的Token
+func (s fileSecrets) get(profile string) string {
+	if profile == "" {
+		return s.Token
+	}
+	return s.Profiles[profile]
+}
+
+// set 设置账号的Token，token 为空时删除
+func (s *fileSecrets) set(profile, token string) {
+	if profile == "" {
+		s.Token = token
+		return
+	}
+	if token == "" {
+		delete(s.Profiles, profile)
+		return
+	}
+	if s.Profiles == nil {
+		s.Profiles = make(map[string]string)
+	}
+	s.Profiles[profile] = token
+}
+
+// empty 是否没有任何保存的Token
+func (s fileSecrets) empty() bool {
+	return s.Token == "" && len(s.Profiles) == 0
+}
+
+// Describe 存储方式和位置的说明
+func (s *FileStore) Describe() string {
+	if os.Getenv(PassphraseEnv) != "" {
+		return fmt.Sprintf("加密文件 %s (口令: $%s)", s.Path, PassphraseEnv)
+	}
+	return fmt.Sprintf("加密文件 %s (密钥: %s)", s.Path, s.KeyPath)
+}
+
+// Load 读取账号保存的Token，凭据文件不存在时返回空字符串
+func (s *FileStore) Load(profile string) (string, error) {
+	secrets, err := s.readSecrets()
+	if err != nil {
+		return "", err
+	}
+	return secrets.get(profile), nil
+}
+
+// Save 加密保存账号的Token，token 为空时删除该账号的Token
+// 所有账号的Token都删除后删除凭据文件（保留密钥文件）
+// 在凭据文件的锁内读取、修改和写入，同时保存不同账号的Token时不会丢失其中一个
+func (s *FileStore) Save(profile, token string) error {
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0700); err != nil {
+		return fmt.Errorf("创建凭据目录失败: %w", err)
+	}
+	return filelock.With(s.Path, func() error {
+		secrets, err := s.readSecrets()
+		if err != nil {
+			// 无法解密时不覆盖，以免丢失其他账号的Token
+			return err
+		}
+		secrets.set(profile, token)
+
+		if secrets.empty() {
+			if err := os.Remove(s.Path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("删除凭据文件失败: %w", err)
+			}
+			return nil
+		}
+		return s.writeSecrets(secrets)
+	})
+}
+
+// readSecrets 读取并解密凭据文件，文件不存在时返回空的凭据
+func (s *FileStore) readSecrets() (fileSecrets, error) {
+	var secrets fileSecrets
+	data, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return secrets, nil
+	}
+	if err != nil {
+		return secrets, fmt.Errorf("读取凭据文件失败: %w", err)
+	}
+
+	var envelope fileEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return secrets, fmt.Errorf("凭据文件格式错误: %w", err)
+	}
+	if envelope.Version != fileVersion {
+		return secrets, fmt.Errorf("不支持的凭据文件版本: %d", envelope.Version)
+	}
+
+	var key []byte
+	switch envelope.KeySource {
+	case keyFromPassphrase:
+		passphrase := os.Getenv(PassphraseEnv)
+		if passphrase == "" {
+			return secrets, fmt.Errorf("凭据文件使用口令加密，请设置环境变量 %s", PassphraseEnv)
+		}
+		key = deriveKey(passphrase, envelope.Salt)
+	case keyFromFile:
+		if key, err = s.readKey(); err != nil {
+			return secrets, err
+		}
+	default:
+		return secrets, fmt.Errorf("未知的密钥来源: %s", envelope.KeySource)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return secrets, err
+	}
+	plaintext, err := gcm.Open(nil, envelope.Nonce, envelope.Data, additionalData)
+	if err != nil {
+		return secrets, errors.New("解密凭据文件失败，口令或密钥文件不正确")
+	}
+
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return secrets, fmt.Errorf("凭据文件内容错误: %w", err)
+	}
+	return secrets, nil
+}
+
+// writeSecrets 加密并写入凭据文件，调用时需持有凭据文件的锁
+func (s *FileStore) writeSecrets(secrets fileSecrets) error {
+	envelope := fileEnvelope{Version: fileVersion}
+	var key []byte
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		envelope.KeySource = keyFromPassphrase
+		envelope.Salt = make([]byte, saltSize)
+		if _, err := rand.Read(envelope.Salt); err != nil {
+			return err
+		}
+		key = deriveKey(passphrase, envelope.Salt)
+	} else {
+		envelope.KeySource = keyFromFile
+		var err error
+		if key, err = s.loadOrCreateKey(); err != nil {
+			return err
+		}
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	envelope.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(envelope.Nonce); err != nil {
+		return err
+	}
+	envelope.Data = gcm.Seal(nil, envelope.Nonce, plaintext, additionalData)
+
+	data, err := json.MarshalIndent(envelope, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.Path, data)
+}
+
+// loadOrCreateKey 读取密钥文件，不存在时生成新的随机密钥
+// 密钥文件以 O_EXCL 创建，已被其他进程创建时读取该密钥，不会覆盖已用于加密的密钥
+func (s *FileStore) loadOrCreateKey() ([]byte, error) {
+	key, err := s.readKey()
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return key, err
+	}
+
+	key = make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(s.KeyPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, fs.ErrExist) {
+		return s.readKey()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("保存密钥文件失败: %w", err)
+	}
+	_, err = f.Write(key)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(s.KeyPath)
+		return nil, fmt.Errorf("保存密钥文件失败: %w", err)
+	}
+	return key, nil
+}
+
+// readKey 读取并检查密钥文件
+func (s *FileStore) readKey() ([]byte, error) {
+	key, err := os.ReadFile(s.KeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取密钥文件失败: %w", err)
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("密钥文件已损坏: %s", s.KeyPath)
+	}
+	return key, nil
+}
+
+// newGCM 创建 AES-GCM 加密器
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// deriveKey 使用 PBKDF2-HMAC-SHA256 由口令派生32字节密钥
+func deriveKey(passphrase string, salt []byte) []byte {
+	return pbkdf2.Key([]byte(passphrase), salt, pbkdf2Iterations, keySize, sha256.New)
+}
+
+// writeFileAtomic 以0600权限写入同目录下的临时文件后重命名，避免写入中断导致文件损坏
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package daemon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client tmplink-cli serve 的客户端
+type Client struct {
+	Addr       string       // 连接地址，格式同 ParseAddr
+	SecretPath string       // 访问密钥文件，每次请求时读取，serve 重新生成密钥后无需重新创建客户端
+	baseURL    string       // 请求使用的URL前缀
+	httpClient *http.Client // 普通请求（带超时）
+	streamHTTP *http.Client // 事件流（不设超时）
+}
+
+// NewClient 创建客户端，地址格式同 ParseAddr，访问密钥从 SecretPath() 读取
+func NewClient(addr string) (*Client, error) {
+	network, address, err := ParseAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, address)
+		},
+	}
+	baseURL := "http://" + address
+	if network == "unix" {
+		baseURL = "http://unix"
+	}
+
+	return &Client{
+		Addr:       addr,
+		SecretPath: SecretPath(),
+		baseURL:    baseURL,
+		httpClient: &http.Client{Transport: transport, Timeout: 10 * time.Second},
+		streamHTTP: &http.Client{Transport: transport},
+	}, nil
+}
+
+// Health 获取服务状态，可用于检测 serve 是否在运行
+func (c *Client) Health(ctx context.Context) (Health, error) {
+	var health Health
+	err := c.do(ctx, http.MethodGet, PathHealth, nil, &health)
+	return health, err
+}
+
+// Tasks 获取全部任务，解码到 tasks（如 *[]TaskStatus）
+func (c *Client) Tasks(ctx context.Context, tasks interface{}) error {
+	return c.do(ctx, http.MethodGet, PathTasks, nil, tasks)
+}
+
+// Enqueue 加入上传队列，新建的任务解码到 task
+func (c *Client) Enqueue(ctx context.Context, req EnqueueRequest, task interface{}) error {
+	return c.do(ctx, http.MethodPost, PathTasks, req, task)
+}
+
+// Cancel 取消任务，任务保留在列表中
+func (c *Client) Cancel(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, PathTasks+"/"+url.PathEscape(id)+"/cancel", nil, nil)
+}
+
+// Remove 取消任务（进行中时）并从列表中移除
+func (c *Client) Remove(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, PathTasks+"/"+url.PathEscape(id), nil, nil)
+}
+
+// do 发送请求并解码JSON响应，非2xx响应返回服务端的错误信息
+func (c *Client) do(ctx context.Context, method, path string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if err := c.authorize(req); err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("连接 tmplink-cli serve (%s) 失败: %w", c.Addr, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var errResp ErrorResponse
+		if json.NewDecoder(resp.Body).Decode(&errResp) == nil && errResp.Error != "" {
+			return fmt.Errorf("%s", errResp.Error)
+		}
+		return fmt.Errorf("请求失败: HTTP %d", resp.StatusCode)
+	}
+	if result == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// authorize 为请求添加访问密钥
+func (c *Client) authorize(req *http.Request) error {
+	secret, err := LoadSecret(c.SecretPath)
+	if err != nil {
+		return fmt.Errorf("%w (请先在本机运行 tmplink-cli serve)", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+secret)
+	return nil
+}
+
+// EventStream 事件流
+type EventStream struct {
+	body   io.ReadCloser
+	reader *bufio.Reader
+}
+
+// Events 订阅事件流，连接后首先收到全部现有任务的 EventTask 事件
+func (c *Client) Events(ctx context.Context) (*EventStream, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+PathEvents, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.streamHTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("连接 tmplink-cli serve (%s) 失败: %w", c.Addr, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("订阅事件失败: HTTP %d", resp.StatusCode)
+	}
+	return &EventStream{body: resp.Body, reader: bufio.NewReader(resp.Body)}, nil
+}
+
+// Next 阻塞读取下一个事件，连接断开时返回错误
+func (s *EventStream) Next() (Event, error) {
+	var (
+		event Event
+		data  []string
+	)
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return Event{}, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "":
+			// 空行表示一个事件结束
+			if len(data) == 0 {
+				continue
+			}
+			event.Data = json.RawMessage(strings.Join(data, "\n"))
+			return event, nil
+		case strings.HasPrefix(line, ":"):
+			// 注释（心跳）
+		case strings.HasPrefix(line, "event:"):
+			event.Type = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+}
+
+// Close 关闭事件流
+func (s *EventStream) Close() error {
+	return s.body.Close()
+}
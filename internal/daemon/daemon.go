@@ -0,0 +1,101 @@
+// Package daemon 定义 tmplink-cli serve 的本地HTTP接口，并提供供 TUI 和其他本地工具使用的客户端
+//
+// 接口（JSON）:
+//
+//	GET    /v1/health            服务状态
+//	GET    /v1/tasks             全部任务
+//	POST   /v1/tasks             加入上传队列（EnqueueRequest），返回任务
+//	GET    /v1/tasks/{id}        单个任务
+//	POST   /v1/tasks/{id}/cancel 取消任务，保留在列表中
+//	DELETE /v1/tasks/{id}        取消（进行中时）并从列表中移除
+//	GET    /v1/events            Server-Sent Events：连接时先发送全部任务，之后推送每次变化
+//
+// 任务的JSON格式与 tmplink-cli 的状态文件相同。
+//
+// 全部请求都需要在 Authorization 头中携带访问密钥 "Bearer <密钥>"，密钥保存在只有当前用户可以读取的
+// ~/.tmplink/serve.secret 中，由 serve 首次启动时生成，其他用户和本机网页无法使用上传队列。
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// DefaultAddr serve 默认监听地址，只接受本机连接，请求需要携带访问密钥
+const DefaultAddr = "127.0.0.1:7478"
+
+// 接口路径
+const (
+	PathHealth = "/v1/health"
+	PathTasks  = "/v1/tasks"
+	PathEvents = "/v1/events"
+)
+
+// 事件类型
+const (
+	EventTask    = "task"    // 任务新增或状态变化，数据为完整的任务
+	EventRemoved = "removed" // 任务已从列表中移除，数据为 RemovedEvent
+)
+
+// EnqueueRequest 加入上传队列的请求，未设置的字段使用 serve 启动参数或已保存的配置
+// 不接受请求中的Token，使用其他账号上传时按名称指定已保存的账号配置
+type EnqueueRequest struct {
+	FilePath     string `json:"file_path"`               // 本地文件路径（绝对路径，或相对 serve 的工作目录）
+	Name         string `json:"name,omitempty"`          // 上传后的文件名
+	Model        *int   `json:"model,omitempty"`         // 文件有效期
+	MrID         string `json:"mr_id,omitempty"`         // 目录ID
+	ChunkSize    int    `json:"chunk_size,omitempty"`    // 分片大小(MB)
+	SkipUpload   *int   `json:"skip_upload,omitempty"`   // 1=检查秒传
+	UploadServer string `json:"upload_server,omitempty"` // 指定上传服务器地址
+	ServerName   string `json:"server_name,omitempty"`   // 上传服务器名称，仅用于显示
+	Profile      string `json:"profile,omitempty"`       // 使用该账号配置保存的Token上传，default 为默认账号
+}
+
+// Health 服务状态
+type Health struct {
+	Version string `json:"version"`
+	PID     int    `json:"pid"`
+	Queued  int    `json:"queued"` // 等待上传的任务数
+	Active  int    `json:"active"` // 正在上传的任务数
+	Tasks   int    `json:"tasks"`  // 列表中的任务总数
+}
+
+// RemovedEvent 任务移除事件的数据
+type RemovedEvent struct {
+	ID string `json:"id"`
+}
+
+// Event 事件流中的单个事件
+type Event struct {
+	Type string          // EventTask 或 EventRemoved
+	Data json.RawMessage // 事件数据
+}
+
+// ErrorResponse 请求失败时的响应
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// ParseAddr 解析监听或连接地址："unix:/path/to.sock" 为Unix socket，其他为 host:port
+// TCP地址只允许本机回环地址，避免上传队列暴露到网络
+func ParseAddr(addr string) (network, address string, err error) {
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		if path == "" {
+			return "", "", fmt.Errorf("Unix socket 路径不能为空")
+		}
+		return "unix", path, nil
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", "", fmt.Errorf("无效的地址 %s: %w", addr, err)
+	}
+	if host != "localhost" {
+		if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			return "", "", fmt.Errorf("只能使用本机地址 (127.0.0.1、::1、localhost)，当前: %s", host)
+		}
+	}
+	return "tcp", addr, nil
+}
@@ -0,0 +1,79 @@
+package daemon
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SecretFileName 访问密钥文件名，位于 ~/.tmplink 下，只有当前用户可以读取
+const SecretFileName = "serve.secret"
+
+// SecretPath 访问密钥文件路径
+func SecretPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".tmplink", SecretFileName)
+	}
+	return filepath.Join(homeDir, ".tmplink", SecretFileName)
+}
+
+// LoadOrCreateSecret 读取访问密钥，不存在时生成随机密钥并以0600权限创建
+// 同时启动的多个进程中只有一个能创建成功，其他进程读取已创建的密钥
+func LoadOrCreateSecret(path string) (string, error) {
+	if secret, err := LoadSecret(path); err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return secret, err
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成访问密钥失败: %w", err)
+	}
+	secret := hex.EncodeToString(buf)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("创建访问密钥目录失败: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, fs.ErrExist) {
+		return LoadSecret(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("创建访问密钥文件失败: %w", err)
+	}
+	if _, err := f.WriteString(secret + "\n"); err != nil {
+		f.Close()
+		os.Remove(path)
+		return "", fmt.Errorf("写入访问密钥文件失败: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("写入访问密钥文件失败: %w", err)
+	}
+	return secret, nil
+}
+
+// LoadSecret 读取访问密钥
+func LoadSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("读取访问密钥失败: %w", err)
+	}
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return "", fmt.Errorf("访问密钥文件为空: %s", path)
+	}
+	return secret, nil
+}
+
+// Authorized 请求的 Authorization 头是否携带正确的访问密钥
+func Authorized(header, secret string) bool {
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
@@ -0,0 +1,23 @@
+// Package filelock 跨进程的文件锁，配置文件和凭据文件在读取-修改-写入期间持有锁，
+// 同时运行的 CLI、GUI 和后台服务不会互相覆盖对方的修改
+package filelock
+
+import (
+	"fmt"
+	"os"
+)
+
+// With 持有 path 的排他锁执行 fn，锁文件为同目录下的 path.lock，其他进程持有锁时等待
+// path 所在的目录需要已经存在
+func With(path string, fn func() error) error {
+	lock, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("打开锁文件失败: %w", err)
+	}
+	defer lock.Close()
+	if err := lockFile(lock); err != nil {
+		return fmt.Errorf("锁定文件失败: %w", err)
+	}
+	defer unlockFile(lock)
+	return fn()
+}
@@ -0,0 +1,291 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"tmplink_uploader/internal/i18n"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sahilm/fuzzy"
+)
+
+// 文件浏览器的排序方式
+type sortMode int
+
+const (
+	sortByName    sortMode = iota // 按名称（目录列表的原始顺序）
+	sortBySize                    // 按大小，大文件在前，目录排在最前
+	sortByModTime                 // 按修改时间，最新的在前
+)
+
+// 文件浏览器的输入框
+type browserInputMode int
+
+const (
+	browserInputNone   browserInputMode = iota
+	browserInputFilter                  // 模糊筛选（/）
+	browserInputPath                    // 跳转到路径（g）
+)
+
+// newBrowserInput 创建文件浏览器的输入框
+func newBrowserInput() textinput.Model {
+	input := textinput.New()
+	input.Width = 60
+	return input
+}
+
+// leadingEntryCount 文件列表开头固定条目的数量（占位符和 ..），这些条目不参与筛选和排序
+func leadingEntryCount(files []FileInfo) int {
+	count := 0
+	for _, file := range files {
+		if file.Name == "" || file.Name == ".." {
+			count++
+			continue
+		}
+		break
+	}
+	return count
+}
+
+// applyFileView 根据筛选条件和排序方式，从目录的完整列表生成显示的文件列表
+func (m *Model) applyFileView() {
+	leading := leadingEntryCount(m.allFiles)
+	entries := append([]FileInfo(nil), m.allFiles[leading:]...)
+
+	if m.filterQuery != "" {
+		// 有筛选条件时按匹配程度排序
+		names := make([]string, len(entries))
+		for i, entry := range entries {
+			names[i] = entry.Name
+		}
+		matches := fuzzy.Find(m.filterQuery, names)
+		filtered := make([]FileInfo, 0, len(matches))
+		for _, match := range matches {
+			filtered = append(filtered, entries[match.Index])
+		}
+		entries = filtered
+	} else {
+		switch m.sortMode {
+		case sortBySize:
+			sort.SliceStable(entries, func(i, j int) bool {
+				if entries[i].IsDir != entries[j].IsDir {
+					return entries[i].IsDir
+				}
+				if entries[i].IsDir {
+					return false // 目录保持名称顺序
+				}
+				return entries[i].Size > entries[j].Size
+			})
+		case sortByModTime:
+			sort.SliceStable(entries, func(i, j int) bool {
+				return entries[i].ModTime.After(entries[j].ModTime)
+			})
+		}
+	}
+
+	m.files = append(append([]FileInfo(nil), m.allFiles[:leading]...), entries...)
+	if m.selectedIndex >= len(m.files) {
+		m.selectedIndex = len(m.files) - 1
+	}
+	if m.selectedIndex < 1 && len(m.files) > 1 {
+		m.selectedIndex = 1
+	}
+}
+
+// handleFilesLoaded 目录加载完成，保存完整列表并生成显示的列表
+func (m Model) handleFilesLoaded(msg FilesLoadedMsg) (tea.Model, tea.Cmd) {
+	m.allFiles = msg.Files
+	m.applyFileView()
+
+	// 跳转到文件时选中该文件
+	if m.selectAfterLoad != "" {
+		for i, file := range m.files {
+			if file.Name == m.selectAfterLoad {
+				m.selectedIndex = i
+				break
+			}
+		}
+		m.selectAfterLoad = ""
+	}
+	return m, nil
+}
+
+// changeDirectory 进入目录，清除筛选条件
+func (m Model) changeDirectory(dir string) (tea.Model, tea.Cmd) {
+	m.currentDir = dir
+	m.selectedIndex = 1
+	m.filterQuery = ""
+	return m, m.loadFiles()
+}
+
+// cycleSortMode 切换排序方式：名称 -> 大小 -> 修改时间
+func (m Model) cycleSortMode() (tea.Model, tea.Cmd) {
+	m.sortMode = (m.sortMode + 1) % 3
+	m.applyFileView()
+	return m, nil
+}
+
+// openBrowserInput 打开筛选或路径输入框
+func (m Model) openBrowserInput(mode browserInputMode) (tea.Model, tea.Cmd) {
+	m.browserInputMode = mode
+	switch mode {
+	case browserInputFilter:
+		m.browserInput.Prompt = "/ "
+		m.browserInput.Placeholder = i18n.T("filebrowser.filter_placeholder")
+		m.browserInput.SetValue(m.filterQuery)
+	case browserInputPath:
+		m.browserInput.Prompt = i18n.T("filebrowser.path_prompt")
+		m.browserInput.Placeholder = i18n.T("filebrowser.path_placeholder")
+		m.browserInput.SetValue(m.currentDir + string(filepath.Separator))
+	}
+	m.browserInput.CursorEnd()
+	return m, m.browserInput.Focus()
+}
+
+// closeBrowserInput 关闭输入框
+func (m *Model) closeBrowserInput() {
+	m.browserInputMode = browserInputNone
+	m.browserInput.Blur()
+	m.browserInput.SetValue("")
+}
+
+// handleBrowserInput 处理输入框打开时的按键
+func (m Model) handleBrowserInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.browserInputMode == browserInputPath {
+		switch msg.String() {
+		case "esc":
+			m.closeBrowserInput()
+			return m, nil
+		case "enter":
+			path := m.browserInput.Value()
+			m.closeBrowserInput()
+			return m.jumpToPath(path)
+		}
+		var cmd tea.Cmd
+		m.browserInput, cmd = m.browserInput.Update(msg)
+		return m, cmd
+	}
+
+	// 模糊筛选：输入时实时更新列表，上下键在结果中移动
+	switch msg.String() {
+	case "esc":
+		m.closeBrowserInput()
+		m.filterQuery = ""
+		m.applyFileView()
+		return m, nil
+	case "enter":
+		m.closeBrowserInput()
+		return m, nil
+	case "up", "down":
+		return m.moveSelection(msg.String())
+	}
+
+	var cmd tea.Cmd
+	m.browserInput, cmd = m.browserInput.Update(msg)
+	if query := m.browserInput.Value(); query != m.filterQuery {
+		m.filterQuery = query
+		m.selectedIndex = leadingEntryCount(m.allFiles)
+		m.applyFileView()
+	}
+	return m, cmd
+}
+
+// moveSelection 上下移动光标（跳过第一个占位符条目）
+func (m Model) moveSelection(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "up":
+		if m.selectedIndex > 1 {
+			m.selectedIndex--
+		}
+	case "down":
+		if m.selectedIndex < len(m.files)-1 {
+			m.selectedIndex++
+		} else if m.selectedIndex == 0 {
+			m.selectedIndex = 1
+		}
+	}
+	return m, nil
+}
+
+// jumpToPath 跳转到输入的路径：目录则进入，文件则进入所在目录并选中该文件
+// 支持 ~ 开头的路径，相对路径以当前目录为基准
+func (m Model) jumpToPath(path string) (tea.Model, tea.Cmd) {
+	path = strings.TrimSpace(path)
+	path = strings.Trim(path, `"'`) // 粘贴的路径可能带引号
+	if path == "" {
+		return m, nil
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(homeDir, path[1:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.currentDir, path)
+	}
+	path = filepath.Clean(path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		m.err = fmt.Errorf("无法打开路径: %v", err)
+		m.state = StateError
+		return m, nil
+	}
+
+	if info.IsDir() {
+		return m.changeDirectory(path)
+	}
+	m.selectAfterLoad = filepath.Base(path)
+	// 隐藏文件需要显示隐藏文件才能选中
+	if strings.HasPrefix(m.selectAfterLoad, ".") {
+		m.showHidden = true
+	}
+	return m.changeDirectory(filepath.Dir(path))
+}
+
+// isBrowserTyping 文件浏览器的输入框是否正在输入
+func (m Model) isBrowserTyping() bool {
+	return m.state == StateMain && m.browserInputMode != browserInputNone
+}
+
+// renderBrowserHeader 渲染排序方式、筛选条件或输入框
+func (m Model) renderBrowserHeader() string {
+	var s strings.Builder
+
+	if m.browserInputMode != browserInputNone {
+		s.WriteString(m.browserInput.View())
+		s.WriteString("\n")
+	}
+
+	if m.filterQuery != "" {
+		matched := len(m.files) - leadingEntryCount(m.files)
+		total := len(m.allFiles) - leadingEntryCount(m.allFiles)
+		if m.browserInputMode != browserInputFilter {
+			s.WriteString(i18n.Tf("filebrowser.filter", m.filterQuery, matched, total))
+		} else {
+			s.WriteString(i18n.Tf("filebrowser.filter_count", matched, total))
+		}
+		if matched == 0 {
+			s.WriteString(i18n.T("filebrowser.no_match"))
+		}
+	}
+
+	return s.String()
+}
+
+// sortModeLabel 排序方式的显示名称
+func (m Model) sortModeLabel() string {
+	switch m.sortMode {
+	case sortBySize:
+		return i18n.T("filebrowser.sort_size")
+	case sortByModTime:
+		return i18n.T("filebrowser.sort_mtime")
+	default:
+		return i18n.T("filebrowser.sort_name")
+	}
+}
@@ -0,0 +1,214 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"tmplink_uploader/internal/config"
+	"tmplink_uploader/internal/daemon"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// daemonReconnectDelay 与 tmplink-cli serve 断开后重新连接的间隔
+const daemonReconnectDelay = 2 * time.Second
+
+// WithDaemon 切换为服务模式：通过 tmplink-cli serve 的接口上传，不再启动CLI进程和轮询状态文件
+// 任务列表改为显示服务中的任务
+func (m Model) WithDaemon(client *daemon.Client) Model {
+	m.daemon = client
+	m.uploadTasks = []TaskStatus{}
+	m.statusFiles = make(map[string]string)
+	m.activeUploads = 0
+	m.updateUploadTable()
+	return m
+}
+
+// 服务模式的消息类型
+type DaemonConnectedMsg struct {
+	Stream *daemon.EventStream
+}
+
+type DaemonDisconnectedMsg struct {
+	Error string
+}
+
+type DaemonReconnectMsg struct{}
+
+// DaemonTaskMsg 任务新增或变化
+type DaemonTaskMsg struct {
+	Task   TaskStatus
+	Stream *daemon.EventStream
+}
+
+type DaemonTaskRemovedMsg struct {
+	TaskID string
+	Stream *daemon.EventStream
+}
+
+type DaemonRequestFailedMsg struct {
+	Error string
+}
+
+// connectDaemon 订阅服务的事件流
+func (m Model) connectDaemon() tea.Cmd {
+	client := m.daemon
+	return func() tea.Msg {
+		stream, err := client.Events(context.Background())
+		if err != nil {
+			return DaemonDisconnectedMsg{Error: err.Error()}
+		}
+		return DaemonConnectedMsg{Stream: stream}
+	}
+}
+
+// waitDaemonEvent 等待事件流中的下一个事件
+func waitDaemonEvent(stream *daemon.EventStream) tea.Cmd {
+	return func() tea.Msg {
+		for {
+			event, err := stream.Next()
+			if err != nil {
+				stream.Close()
+				return DaemonDisconnectedMsg{Error: err.Error()}
+			}
+
+			switch event.Type {
+			case daemon.EventTask:
+				var task TaskStatus
+				if json.Unmarshal(event.Data, &task) == nil {
+					return DaemonTaskMsg{Task: task, Stream: stream}
+				}
+			case daemon.EventRemoved:
+				var removed daemon.RemovedEvent
+				if json.Unmarshal(event.Data, &removed) == nil {
+					return DaemonTaskRemovedMsg{TaskID: removed.ID, Stream: stream}
+				}
+			}
+		}
+	}
+}
+
+// handleDaemonMsg 处理服务模式的消息，返回 false 表示不是服务模式的消息
+func (m Model) handleDaemonMsg(msg tea.Msg) (tea.Model, tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case DaemonConnectedMsg:
+		// 连接后服务会重新发送全部任务，清空列表以去掉断开期间被移除的任务
+		m.daemonOffline = false
+		m.uploadTasks = []TaskStatus{}
+		m.refreshDaemonTasks()
+		return m, waitDaemonEvent(msg.Stream), true
+
+	case DaemonDisconnectedMsg:
+		m.daemonOffline = true
+		return m, tea.Tick(daemonReconnectDelay, func(time.Time) tea.Msg {
+			return DaemonReconnectMsg{}
+		}), true
+
+	case DaemonReconnectMsg:
+		return m, m.connectDaemon(), true
+
+	case DaemonTaskMsg:
+		m.upsertDaemonTask(msg.Task)
+		return m, waitDaemonEvent(msg.Stream), true
+
+	case DaemonTaskRemovedMsg:
+		m.removeDaemonTask(msg.TaskID)
+		return m, waitDaemonEvent(msg.Stream), true
+
+	case DaemonRequestFailedMsg:
+		m.err = fmt.Errorf("%s", msg.Error)
+		m.state = StateError
+		return m, nil, true
+	}
+	return m, nil, false
+}
+
+// upsertDaemonTask 更新或添加服务中的任务
+func (m *Model) upsertDaemonTask(task TaskStatus) {
+	found := false
+	for i := range m.uploadTasks {
+		if m.uploadTasks[i].ID == task.ID {
+			m.uploadTasks[i] = task
+			found = true
+			break
+		}
+	}
+	if !found {
+		m.uploadTasks = append(m.uploadTasks, task)
+	}
+	m.refreshDaemonTasks()
+}
+
+// removeDaemonTask 从列表中移除任务
+func (m *Model) removeDaemonTask(taskID string) {
+	for i := range m.uploadTasks {
+		if m.uploadTasks[i].ID == taskID {
+			m.uploadTasks = append(m.uploadTasks[:i], m.uploadTasks[i+1:]...)
+			break
+		}
+	}
+	if len(m.uploadTasks) > 0 && m.uploadTable.Cursor() >= len(m.uploadTasks) {
+		m.uploadTable.SetCursor(len(m.uploadTasks) - 1)
+	}
+	m.refreshDaemonTasks()
+}
+
+// refreshDaemonTasks 根据任务列表重新计算上传数并刷新表格
+func (m *Model) refreshDaemonTasks() {
+	m.activeUploads = 0
+	for _, task := range m.uploadTasks {
+		if isTaskActive(task.Status) {
+			m.activeUploads++
+		}
+	}
+	m.updateUploadTable()
+}
+
+// enqueueDaemonUploads 按顺序将文件加入服务的上传队列，参数与启动CLI进程时相同
+// 新任务由事件流添加到列表
+func (m Model) enqueueDaemonUploads(filePaths []string, options uploadOptions) tea.Cmd {
+	client := m.daemon
+
+	skipUpload := 1
+	if !m.config.QuickUpload {
+		skipUpload = 0
+	}
+	base := daemon.EnqueueRequest{
+		Model:      &options.model,
+		MrID:       options.folder.ID,
+		ChunkSize:  m.config.ChunkSize,
+		SkipUpload: &skipUpload,
+		Profile:    config.ProfileName(m.config.ActiveProfile()),
+	}
+	if m.serverIndex < len(m.availableServers) && len(m.availableServers) > 0 {
+		selectedServer := m.availableServers[m.serverIndex]
+		base.ServerName = selectedServer.Name
+		base.UploadServer = selectedServer.URL
+	}
+
+	return func() tea.Msg {
+		for _, filePath := range filePaths {
+			req := base
+			req.FilePath = filePath
+			if err := client.Enqueue(context.Background(), req, nil); err != nil {
+				return DaemonRequestFailedMsg{Error: fmt.Sprintf("加入上传队列失败: %v", err)}
+			}
+		}
+		return nil
+	}
+}
+
+// removeDaemonTasks 从服务中移除任务，进行中的任务会被取消
+func (m Model) removeDaemonTasks(taskIDs ...string) tea.Cmd {
+	client := m.daemon
+	return func() tea.Msg {
+		for _, id := range taskIDs {
+			if err := client.Remove(context.Background(), id); err != nil {
+				return DaemonRequestFailedMsg{Error: fmt.Sprintf("移除任务失败: %v", err)}
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,357 @@
+package tui
+
+import (
+	"context"
+	"strings"
+
+	"tmplink_uploader/internal/api"
+	"tmplink_uploader/internal/config"
+	"tmplink_uploader/internal/i18n"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// remoteFolder 上传的目标文件夹
+type remoteFolder struct {
+	ID   string
+	Path string // 形如 /Projects/2026，只知道目录ID时为空
+}
+
+// defaultUploadFolder 配置中保存的默认目标文件夹
+func defaultUploadFolder(saved config.Config) remoteFolder {
+	if saved.MrID == "" || saved.MrID == api.RootFolderID {
+		return remoteFolder{ID: api.RootFolderID, Path: "/"}
+	}
+	return remoteFolder{ID: saved.MrID}
+}
+
+// label 文件夹的显示名称
+func (f remoteFolder) label() string {
+	if f.Path != "" {
+		return f.Path
+	}
+	return i18n.Tf("folder.id_only", f.ID)
+}
+
+// folderPurpose 打开文件夹选择界面的位置，决定 u 键的作用和返回的界面
+type folderPurpose int
+
+const (
+	folderForBrowser  folderPurpose = iota // 文件浏览器：选择之后加入的上传使用的文件夹
+	folderForUpload                        // 上传确认界面：只修改本次上传的文件夹
+	folderForSettings                      // 设置界面：修改默认文件夹
+)
+
+// folderPicker 远程文件夹选择界面的状态
+type folderPicker struct {
+	purpose   folderPurpose
+	trail     []api.Folder // 从根目录到当前文件夹经过的文件夹（不含根目录）
+	folders   []api.Folder // 当前文件夹的子文件夹
+	cursor    int
+	loading   bool
+	err       string
+	creating  bool // 正在输入新文件夹的名称
+	nameInput textinput.Model
+	selectID  string // 列表加载完成后选中的文件夹
+}
+
+// currentID 当前文件夹的目录ID
+func (p folderPicker) currentID() string {
+	if len(p.trail) == 0 {
+		return api.RootFolderID
+	}
+	return p.trail[len(p.trail)-1].MrID
+}
+
+// currentPath 当前文件夹的路径
+func (p folderPicker) currentPath() string {
+	names := make([]string, len(p.trail))
+	for i, folder := range p.trail {
+		names[i] = folder.Name
+	}
+	return api.JoinFolderPath(names)
+}
+
+// FoldersLoadedMsg 子文件夹列表加载完成
+type FoldersLoadedMsg struct {
+	ParentID string
+	Folders  []api.Folder
+	Error    string
+}
+
+// FolderCreatedMsg 新文件夹创建完成
+type FolderCreatedMsg struct {
+	Folder api.Folder
+	Error  string
+}
+
+// newFolderNameInput 创建新文件夹名称的输入框
+func newFolderNameInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = "+ "
+	input.Width = 40
+	input.CharLimit = 100
+	return input
+}
+
+// openFolderPicker 打开文件夹选择界面，从根目录开始浏览
+func (m Model) openFolderPicker(purpose folderPurpose) (tea.Model, tea.Cmd) {
+	m.state = StateFolderPicker
+	m.folderPicker.purpose = purpose
+	m.folderPicker.trail = nil
+	m.folderPicker.creating = false
+	return m, m.loadFolders()
+}
+
+// loadFolders 加载当前文件夹的子文件夹
+func (m *Model) loadFolders() tea.Cmd {
+	m.folderPicker.loading = true
+	m.folderPicker.err = ""
+	m.folderPicker.folders = nil
+	m.folderPicker.cursor = 0
+
+	client := newAPIClient(m.apiServer, m.config.Token)
+	parentID := m.folderPicker.currentID()
+	return func() tea.Msg {
+		resp, err := client.ListFolders(context.Background(), parentID)
+		if err != nil {
+			return FoldersLoadedMsg{ParentID: parentID, Error: err.Error()}
+		}
+		if resp.Status != 1 {
+			return FoldersLoadedMsg{ParentID: parentID, Error: i18n.Tf("folder.list_failed", resp.Status)}
+		}
+		return FoldersLoadedMsg{ParentID: parentID, Folders: resp.Folders}
+	}
+}
+
+// createFolder 在当前文件夹中创建新文件夹
+func (m Model) createFolder(name string) tea.Cmd {
+	client := newAPIClient(m.apiServer, m.config.Token)
+	parentID := m.folderPicker.currentID()
+	return func() tea.Msg {
+		resp, err := client.CreateFolder(context.Background(), parentID, name)
+		if err != nil {
+			return FolderCreatedMsg{Error: err.Error()}
+		}
+		if resp.Status != 1 {
+			return FolderCreatedMsg{Error: i18n.Tf("folder.create_failed", resp.Status)}
+		}
+		return FolderCreatedMsg{Folder: resp.Folder}
+	}
+}
+
+// handleFoldersLoaded 子文件夹列表加载完成，忽略已离开的文件夹的结果
+func (m Model) handleFoldersLoaded(msg FoldersLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.ParentID != m.folderPicker.currentID() {
+		return m, nil
+	}
+	m.folderPicker.loading = false
+	m.folderPicker.err = msg.Error
+	m.folderPicker.folders = msg.Folders
+
+	if m.folderPicker.selectID != "" {
+		for i, folder := range m.folderPicker.folders {
+			if folder.MrID == m.folderPicker.selectID {
+				m.folderPicker.cursor = i
+				break
+			}
+		}
+		m.folderPicker.selectID = ""
+	}
+	return m, nil
+}
+
+// handleFolderCreated 新文件夹创建完成，重新加载列表并选中新文件夹
+func (m Model) handleFolderCreated(msg FolderCreatedMsg) (tea.Model, tea.Cmd) {
+	if msg.Error != "" {
+		m.folderPicker.loading = false
+		m.folderPicker.err = msg.Error
+		return m, nil
+	}
+	m.folderPicker.selectID = msg.Folder.MrID
+	return m, m.loadFolders()
+}
+
+// handleFolderPicker 处理文件夹选择界面的按键
+func (m Model) handleFolderPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	picker := &m.folderPicker
+
+	// 输入新文件夹名称
+	if picker.creating {
+		switch msg.String() {
+		case "esc":
+			picker.creating = false
+			picker.nameInput.Blur()
+			return m, nil
+		case "enter":
+			name := strings.TrimSpace(picker.nameInput.Value())
+			if name == "" || strings.Contains(name, "/") {
+				return m, nil
+			}
+			picker.creating = false
+			picker.nameInput.Blur()
+			picker.loading = true
+			return m, m.createFolder(name)
+		}
+		var cmd tea.Cmd
+		picker.nameInput, cmd = picker.nameInput.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.closeFolderPicker()
+		return m, nil
+	case "up":
+		if picker.cursor > 0 {
+			picker.cursor--
+		}
+		return m, nil
+	case "down":
+		if picker.cursor < len(picker.folders)-1 {
+			picker.cursor++
+		}
+		return m, nil
+	case "enter", "right":
+		// 进入选中的子文件夹
+		if picker.loading || picker.cursor >= len(picker.folders) {
+			return m, nil
+		}
+		picker.trail = append(picker.trail, picker.folders[picker.cursor])
+		return m, m.loadFolders()
+	case "left", "backspace":
+		// 返回上级文件夹，并选中刚才所在的文件夹
+		if len(picker.trail) == 0 {
+			return m, nil
+		}
+		picker.selectID = picker.currentID()
+		picker.trail = picker.trail[:len(picker.trail)-1]
+		return m, m.loadFolders()
+	case "r":
+		return m, m.loadFolders()
+	case "n":
+		picker.creating = true
+		picker.nameInput.SetValue("")
+		picker.nameInput.Placeholder = i18n.T("folder.name_placeholder")
+		return m, picker.nameInput.Focus()
+	case "u":
+		// 使用当前文件夹：在上传确认界面中只用于本次上传，在设置界面中设为默认文件夹
+		folder := remoteFolder{ID: picker.currentID(), Path: picker.currentPath()}
+		switch picker.purpose {
+		case folderForUpload:
+			if m.pendingUpload != nil {
+				m.pendingUpload.options.folder = folder
+			}
+		case folderForSettings:
+			return m.saveDefaultFolder(folder)
+		default:
+			m.uploadFolder = folder
+		}
+		m.closeFolderPicker()
+		return m, nil
+	case "d":
+		return m.saveDefaultFolder(remoteFolder{ID: picker.currentID(), Path: picker.currentPath()})
+	}
+	return m, nil
+}
+
+// saveDefaultFolder 设为默认文件夹并保存到配置，之后加入的上传也使用该文件夹
+func (m Model) saveDefaultFolder(folder remoteFolder) (tea.Model, tea.Cmd) {
+	m.uploadFolder = folder
+	m.config.MrID = folder.ID
+	if err := m.config.Save(); err != nil {
+		m.folderPicker.err = i18n.Tf("folder.save_failed", err)
+		return m, nil
+	}
+	if m.folderPicker.purpose == folderForUpload && m.pendingUpload != nil {
+		m.pendingUpload.options.folder = folder
+	}
+	m.closeFolderPicker()
+	return m, nil
+}
+
+// closeFolderPicker 返回打开文件夹选择界面之前的界面
+func (m *Model) closeFolderPicker() {
+	switch m.folderPicker.purpose {
+	case folderForUpload:
+		m.state = StateConfirmUpload
+	case folderForSettings:
+		m.state = StateSettings
+	default:
+		m.state = StateMain
+	}
+}
+
+// renderFolderPicker 渲染文件夹选择界面
+func (m Model) renderFolderPicker() string {
+	picker := m.folderPicker
+	var s strings.Builder
+
+	destination := m.uploadFolder.label()
+	switch picker.purpose {
+	case folderForUpload:
+		if m.pendingUpload != nil {
+			destination = m.pendingUpload.options.folder.label()
+		}
+	case folderForSettings:
+		destination = m.defaultFolderLabel()
+	}
+
+	s.WriteString(titleStyle.Render(i18n.T("folder.title")))
+	s.WriteString("\n")
+	s.WriteString(i18n.Tf("folder.current", picker.currentPath()))
+	s.WriteString(helpStyle.Render(i18n.Tf("folder.destination", destination)))
+	s.WriteString("\n\n")
+
+	switch {
+	case picker.loading:
+		s.WriteString(m.spinner.View() + " " + i18n.T("folder.loading"))
+		s.WriteString("\n")
+	case picker.err != "":
+		s.WriteString(errorStyle.Render(picker.err))
+		s.WriteString("\n")
+	case len(picker.folders) == 0:
+		s.WriteString(helpStyle.Render(i18n.T("folder.empty")))
+		s.WriteString("\n")
+	default:
+		maxHeight := m.height - 12 // 为三行状态栏、标题和当前路径留空间
+		if maxHeight < 5 || m.height == 0 {
+			maxHeight = 10
+		}
+		startIndex := 0
+		if picker.cursor >= maxHeight {
+			startIndex = picker.cursor - maxHeight + 1
+		}
+		endIndex := startIndex + maxHeight
+		if endIndex > len(picker.folders) {
+			endIndex = len(picker.folders)
+		}
+
+		for i := startIndex; i < endIndex; i++ {
+			line := "  📁 " + picker.folders[i].Name
+			if i == picker.cursor {
+				line = lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Render("> 📁 " + picker.folders[i].Name)
+			}
+			s.WriteString(line)
+			s.WriteString("\n")
+		}
+		if len(picker.folders) > maxHeight {
+			s.WriteString(i18n.Tf("filebrowser.scroll", startIndex+1, endIndex, len(picker.folders)))
+		}
+	}
+
+	if picker.creating {
+		s.WriteString("\n")
+		s.WriteString(picker.nameInput.View())
+		s.WriteString("\n")
+	}
+
+	return s.String()
+}
+
+// isNamingFolder 是否正在输入新文件夹的名称
+func (m Model) isNamingFolder() bool {
+	return m.state == StateFolderPicker && m.folderPicker.creating
+}
@@ -1,11 +1,9 @@
 package tui
 
 import (
-	"crypto/sha1"
-	"encoding/hex"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"os/exec"
@@ -14,6 +12,10 @@ import (
 	"syscall"
 	"time"
 
+	"tmplink_uploader/internal/api"
+	"tmplink_uploader/internal/config"
+	"tmplink_uploader/internal/credential"
+	"tmplink_uploader/internal/daemon"
 	"tmplink_uploader/internal/i18n"
 
 	"github.com/charmbracelet/bubbles/filepicker"
@@ -39,6 +41,9 @@ const (
 	StateSettings                           // 上传设置
 	StateUploadList                         // 上传管理器
 	StateError                              // 错误状态
+	StateConfirmUpload                      // 确认上传（文件有效期和目标文件夹）
+	StateFolderPicker                       // 选择远程文件夹
+	StateProfilePicker                      // 切换账号
 )
 
 // 用户信息
@@ -57,33 +62,17 @@ type ServerOption struct {
 	URL  string // 实际URL
 }
 
-// 配置结构
-type Config struct {
-	Token              string    `json:"token"`
-	UploadServer       string    `json:"upload_server"`
-	SelectedServerName string    `json:"selected_server_name"` // 选中的服务器名称
-	ChunkSize          int       `json:"chunk_size"`           // 存储MB数
-	MaxConcurrent      int       `json:"max_concurrent"`
-	QuickUpload        bool      `json:"quick_upload"`
-	SkipUpload         bool      `json:"skip_upload"`
-	LastUpdateCheck    time.Time `json:"last_update_check"`    // 最后一次更新检查时间
-	Language           string    `json:"language"`             // 界面语言
-	// CLI专用字段
-	Model int    `json:"model"` // CLI文件过期模式
-	MrID  string `json:"mr_id"` // CLI目录ID
-}
-
 // getAvailableServers 从API获取可用的上传服务器列表
-func getAvailableServers(token string) ([]ServerOption, error) {
+func getAvailableServers(client *api.Client) ([]ServerOption, error) {
 	var servers []ServerOption
 
 	// 如果没有token，返回空列表
-	if token == "" {
+	if client.Token == "" {
 		return servers, nil
 	}
 
 	// 调用API获取服务器列表
-	apiServers, err := fetchServerListFromAPI(token)
+	apiServers, err := fetchServerListFromAPI(client)
 	if err != nil {
 		// 如果API调用失败，返回空列表和错误
 		return servers, err
@@ -93,100 +82,74 @@ func getAvailableServers(token string) ([]ServerOption, error) {
 	return apiServers, nil
 }
 
-// fetchServerListFromAPI 从API获取服务器列表
-func fetchServerListFromAPI(token string) ([]ServerOption, error) {
-	client := &http.Client{Timeout: 10 * time.Second}
+// newAPIClient 创建TUI使用的API客户端
+func newAPIClient(apiServer, token string) *api.Client {
+	client := api.NewClient(token)
+	client.BaseURL = apiServer
+	client.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	client.UserAgent = "tmplink-tui"
+	return client
+}
 
+// fetchServerListFromAPI 从API获取服务器列表
+func fetchServerListFromAPI(client *api.Client) ([]ServerOption, error) {
 	// 使用upload_request_select2 API获取服务器列表
 	// 需要提供一个虚拟文件信息来获取服务器列表
-	formData := fmt.Sprintf("action=upload_request_select2&sha1=dummy&filename=dummy.txt&filesize=1024&model=1&token=%s", token)
-
-	req, err := http.NewRequest("POST", "https://tmplink-sec.vxtrans.com/api_v2/file", strings.NewReader(formData))
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	selectResp, err := client.UploadRequestSelect(context.Background(), api.UploadSelectRequest{
+		SHA1:     "dummy",
+		Filename: "dummy.txt",
+		Filesize: 1024,
+		Model:    1,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	var apiResp struct {
-		Status int `json:"status"`
-		Data   struct {
-			Servers interface{} `json:"servers"`
-		} `json:"data"`
-	}
-
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return nil, fmt.Errorf("解析API响应失败: %w", err)
-	}
-
-	if apiResp.Status != 1 {
-		return nil, fmt.Errorf("API返回错误状态: %d", apiResp.Status)
+	if selectResp.Status != 1 {
+		return nil, fmt.Errorf("API返回错误状态: %d", selectResp.Status)
 	}
 
 	var servers []ServerOption
-
-	// 解析servers字段
-	if apiResp.Data.Servers != nil {
-		if serverList, ok := apiResp.Data.Servers.([]interface{}); ok {
-			for _, serverItem := range serverList {
-				if serverObj, ok := serverItem.(map[string]interface{}); ok {
-					if title, hasTitle := serverObj["title"].(string); hasTitle {
-						if url, hasURL := serverObj["url"].(string); hasURL {
-							servers = append(servers, ServerOption{
-								Name: title,
-								URL:  url,
-							})
-						}
-					}
-				}
-			}
-		}
+	for _, server := range selectResp.Servers {
+		servers = append(servers, ServerOption{
+			Name: server.Title,
+			URL:  server.URL,
+		})
 	}
 
 	return servers, nil
 }
 
-// 默认配置
-func defaultConfig() Config {
-	return Config{
-		Token:              "",
-		UploadServer:       "",
-		SelectedServerName: "",
-		ChunkSize:          3, // 3MB
-		MaxConcurrent:      5,
-		QuickUpload:        true,
-		SkipUpload:         false,
-		Language:           "", // 空表示尚未选择，首次运行时展示语言选择界面
-		Model:              0,  // CLI默认过期模式
-		MrID:               "0", // CLI默认根目录
-	}
-}
-
 // 任务状态
 type TaskStatus struct {
-	ID          string    `json:"id"`
-	Status      string    `json:"status"`
-	FilePath    string    `json:"file_path"`
-	FileName    string    `json:"file_name"`
-	FileSize    int64     `json:"file_size"`
-	Progress    float64   `json:"progress"`
-	UploadSpeed float64   `json:"upload_speed,omitempty"` // KB/s
-	ServerName  string    `json:"server_name,omitempty"`  // 上传服务器名称
-	ProcessID   int       `json:"process_id,omitempty"`   // CLI进程号
-	DownloadURL string    `json:"download_url,omitempty"`
-	ErrorMsg    string    `json:"error_msg,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID           string    `json:"id"`
+	Status       string    `json:"status"`
+	FilePath     string    `json:"file_path"`
+	FileName     string    `json:"file_name"`
+	FileSize     int64     `json:"file_size"`
+	HashProgress float64   `json:"hash_progress"` // SHA1计算进度（0-100）
+	Progress     float64   `json:"progress"`
+	UploadSpeed  float64   `json:"upload_speed,omitempty"` // KB/s
+	ServerName   string    `json:"server_name,omitempty"`  // 上传服务器名称
+	ProcessID    int       `json:"process_id,omitempty"`   // CLI进程号
+	Model        *int      `json:"model,omitempty"`        // 文件有效期，旧版本的任务为空
+	MrID         string    `json:"mr_id,omitempty"`        // 目标文件夹的目录ID
+	FolderPath   string    `json:"folder_path,omitempty"`  // 目标文件夹的路径（仅用于显示）
+	DownloadURL  string    `json:"download_url,omitempty"`
+	ErrorMsg     string    `json:"error_msg,omitempty"`
+	ErrorType    string    `json:"error_type,omitempty"` // 失败类别: auth/quota/destination/file/network/server
+	ErrorCode    int       `json:"error_code,omitempty"` // 服务器返回的错误代码或状态码
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// isTaskActive 任务是否仍在进行中（CLI进程尚未结束）
+func isTaskActive(status string) bool {
+	switch status {
+	case "starting", "pending", "hashing", "uploading":
+		return true
+	}
+	return false
 }
 
 // 文件信息
@@ -202,7 +165,9 @@ type Model struct {
 	// 基本状态
 	state        State
 	cliPath      string
-	config       Config
+	apiServer    string // API服务器地址
+	linkBase     string // 下载链接前缀，传递给CLI
+	config       config.Config
 	userInfo     UserInfo
 	selectedFile string
 	uploadTasks  []TaskStatus
@@ -219,10 +184,29 @@ type Model struct {
 
 	// 文件浏览器状态
 	currentDir    string
-	files         []FileInfo
+	files         []FileInfo // 显示的文件列表（经过筛选和排序）
+	allFiles      []FileInfo // 当前目录的完整列表
 	selectedIndex int
 	showHidden    bool
 
+	// 筛选、排序和路径跳转
+	filterQuery      string
+	sortMode         sortMode
+	browserInputMode browserInputMode
+	browserInput     textinput.Model
+	selectAfterLoad  string // 目录加载完成后选中的条目名称
+
+	// 多选和目录上传
+	markedFiles   map[string]int64 // 已标记待上传的文件（绝对路径 -> 文件大小）
+	pendingUpload *uploadRequest   // 等待确认的上传
+
+	// 远程文件夹
+	uploadFolder remoteFolder // 新加入的上传使用的目标文件夹
+	folderPicker folderPicker
+
+	// 账号切换
+	profilePicker profilePicker
+
 	// 设置界面状态
 	settingsIndex    int
 	settingsInputs   map[string]textinput.Model
@@ -237,6 +221,12 @@ type Model struct {
 	isLoading         bool
 	isValidatingToken bool // 标记是否正在验证Token
 	activeUploads     int
+	queuePaused       bool  // 上传队列已暂停，不再启动排队中的任务
+	lastTaskID        int64 // 最近生成的任务ID中的时间戳
+
+	// 服务模式：通过 tmplink-cli serve 上传，为 nil 时为每个文件启动CLI进程
+	daemon        *daemon.Client
+	daemonOffline bool // 与服务的连接已断开，正在重连
 }
 
 // 导航菜单项
@@ -250,10 +240,19 @@ func (i menuItem) Title() string       { return i.title }
 func (i menuItem) Description() string { return i.desc }
 
 // NewModel 创建新的TUI模型
-func NewModel(cliPath string) Model {
+// apiServer 和 linkBase 为空时依次使用环境变量、配置文件和默认值
+func NewModel(cliPath, apiServer, linkBase string) Model {
 	// 加载配置
 	config := loadConfig()
 
+	// 确定API服务器地址和下载链接前缀
+	if apiServer == "" {
+		apiServer = api.ResolveAPIServer(config.APIServer)
+	}
+	if linkBase == "" {
+		linkBase = api.ResolveLinkBase(config.LinkBase)
+	}
+
 	// 初始化token输入框
 	tokenInput := textinput.New()
 	tokenInput.Placeholder = i18n.T("auth.placeholder")
@@ -313,6 +312,7 @@ func NewModel(cliPath string) Model {
 		{Title: i18n.T("upload_list.col_progress"), Width: 10},
 		{Title: i18n.T("upload_list.col_speed"), Width: 10},
 		{Title: i18n.T("upload_list.col_server"), Width: 12},
+		{Title: i18n.T("upload_list.col_destination"), Width: 18},
 		{Title: i18n.T("upload_list.col_status"), Width: 10},
 	}
 
@@ -335,6 +335,7 @@ func NewModel(cliPath string) Model {
 	// 清理无效状态文件并加载有效任务
 	var uploadTasks []TaskStatus
 	var statusFiles map[string]string
+	var queuePaused bool
 	if config.Token != "" {
 		validTasks, validStatusFiles, err := cleanupAndLoadTasks()
 		if err == nil {
@@ -345,6 +346,11 @@ func NewModel(cliPath string) Model {
 			uploadTasks = make([]TaskStatus, 0)
 			statusFiles = make(map[string]string)
 		}
+
+		// 恢复上次退出时尚未启动的排队任务
+		queue := loadQueue()
+		uploadTasks = append(uploadTasks, queue.Tasks...)
+		queuePaused = queue.Paused
 	} else {
 		uploadTasks = make([]TaskStatus, 0)
 		statusFiles = make(map[string]string)
@@ -365,12 +371,18 @@ func NewModel(cliPath string) Model {
 	concurrencyInput.SetValue(fmt.Sprintf("%d", config.MaxConcurrent))
 	settingsInputs["concurrency"] = concurrencyInput
 
+	parallelInput := textinput.New()
+	parallelInput.Placeholder = i18n.T("settings.parallel_uploads_placeholder")
+	parallelInput.Width = 20
+	parallelInput.SetValue(fmt.Sprintf("%d", config.ParallelUploads))
+	settingsInputs["parallel_uploads"] = parallelInput
+
 	// 默认设置焦点（在用户验证前假设非赞助用户）
 	// 没有所有用户都可编辑的设置，所以先不设置焦点
 	initialSettingsIndex := 0
 
 	// 初始化服务器列表和索引（在没有token时为空列表）
-	availableServers, _ := getAvailableServers("") // 空token，返回空列表
+	availableServers, _ := getAvailableServers(newAPIClient(apiServer, "")) // 空token，返回空列表
 	serverIndex := 0
 	// 如果有配置的服务器，根据配置的服务器URL或名称找到对应的索引
 	if config.SelectedServerName != "" {
@@ -385,6 +397,8 @@ func NewModel(cliPath string) Model {
 	return Model{
 		state:            initialState,
 		cliPath:          cliPath,
+		apiServer:        apiServer,
+		linkBase:         linkBase,
 		config:           config,
 		tokenInput:       tokenInput,
 		filePicker:       fp,
@@ -397,12 +411,18 @@ func NewModel(cliPath string) Model {
 		files:            []FileInfo{},
 		selectedIndex:    1,     // 跳过占位符，从第一个真实条目开始
 		showHidden:       false, // 默认不显示隐藏文件
+		markedFiles:      make(map[string]int64),
+		browserInput:     newBrowserInput(),
+		uploadFolder:     defaultUploadFolder(config),
+		folderPicker:     folderPicker{nameInput: newFolderNameInput()},
+		profilePicker:    profilePicker{nameInput: newProfileNameInput()},
 		settingsIndex:    initialSettingsIndex,
 		settingsInputs:   settingsInputs,
 		serverIndex:      serverIndex,
 		availableServers: availableServers,
 		uploadTasks:      uploadTasks,
 		statusFiles:      statusFiles,
+		queuePaused:      queuePaused,
 		langIndex:        0,
 		isLoading:        strings.TrimSpace(config.Token) != "" && initialState == StateInit,
 	}
@@ -424,13 +444,22 @@ func (m Model) Init() tea.Cmd {
 	// 加载文件列表
 	cmds = append(cmds, m.loadFiles())
 
+	// 服务模式下订阅服务的任务事件
+	if m.daemon != nil {
+		cmds = append(cmds, m.connectDaemon())
+		return tea.Batch(cmds...)
+	}
+
 	// 为恢复的上传任务启动进度监控
 	for _, task := range m.uploadTasks {
-		if task.Status == "uploading" || task.Status == "pending" || task.Status == "starting" {
+		if isTaskActive(task.Status) {
 			cmds = append(cmds, m.startProgressTimer(task.ID))
 		}
 	}
 
+	// 启动恢复的排队任务
+	cmds = append(cmds, func() tea.Msg { return QueueDispatchMsg{} })
+
 	return tea.Batch(cmds...)
 }
 
@@ -438,6 +467,12 @@ func (m Model) Init() tea.Cmd {
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
+	if m.daemon != nil {
+		if model, cmd, handled := m.handleDaemonMsg(msg); handled {
+			return model, cmd
+		}
+	}
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -452,7 +487,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// 用户验证成功后，从API获取最新的服务器列表
-		if updatedServers, err := getAvailableServers(m.config.Token); err == nil {
+		if updatedServers, err := getAvailableServers(newAPIClient(m.apiServer, m.config.Token)); err == nil {
 			m.availableServers = updatedServers
 
 			// 如果没有配置的服务器，默认选择第一个可用服务器
@@ -518,7 +553,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.state = StateMain
 
 		// 用户验证成功后，从API获取最新的服务器列表
-		if updatedServers, err := getAvailableServers(m.config.Token); err == nil {
+		if updatedServers, err := getAvailableServers(newAPIClient(m.apiServer, m.config.Token)); err == nil {
 			m.availableServers = updatedServers
 
 			// 如果没有配置的服务器，默认选择第一个可用服务器
@@ -567,8 +602,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case FilesLoadedMsg:
-		m.files = msg.Files
-		return m, nil
+		return m.handleFilesLoaded(msg)
+
+	case FoldersLoadedMsg:
+		return m.handleFoldersLoaded(msg)
+
+	case FolderCreatedMsg:
+		return m.handleFolderCreated(msg)
 
 	case spinner.TickMsg:
 		m.spinner, cmd = m.spinner.Update(msg)
@@ -586,11 +626,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case UploadErrorMsg:
 		return m.handleUploadError(msg)
 
+	case UploadCancelledMsg:
+		return m.handleUploadCancelled(msg)
+
 	case ProcessStartedMsg:
 		return m.handleProcessStarted(msg)
 
 	case CheckProgressTickMsg:
 		return m.handleProgressTick(msg)
+
+	case ProcessExitedMsg:
+		return m.handleProcessExited(msg)
+
+	case QueueDispatchMsg:
+		return m.handleQueueDispatch()
+
+	case DirScannedMsg:
+		return m.handleDirScanned(msg)
 	}
 
 	// 更新各组件
@@ -610,8 +662,13 @@ func (m *Model) updateComponentSizes() {
 // handleKeyPress 处理键盘输入
 func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
-	case "ctrl+c", "q":
+	case "ctrl+c":
 		return m, tea.Quit
+	case "q":
+		// 在文件浏览器、文件夹选择或账号切换界面的输入框中输入时不退出
+		if !m.isBrowserTyping() && !m.isNamingFolder() && !m.isNamingProfile() {
+			return m, tea.Quit
+		}
 	}
 
 	switch m.state {
@@ -629,6 +686,12 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleUploadList(msg)
 	case StateError:
 		return m.handleError(msg)
+	case StateConfirmUpload:
+		return m.handleConfirmUpload(msg)
+	case StateFolderPicker:
+		return m.handleFolderPicker(msg)
+	case StateProfilePicker:
+		return m.handleProfilePicker(msg)
 	}
 
 	return m, nil
@@ -670,6 +733,10 @@ func (m Model) handleLanguageSelect(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			concInput.Placeholder = i18n.T("settings.concurrency_placeholder")
 			m.settingsInputs["concurrency"] = concInput
 		}
+		if parallelInput, ok := m.settingsInputs["parallel_uploads"]; ok {
+			parallelInput.Placeholder = i18n.T("settings.parallel_uploads_placeholder")
+			m.settingsInputs["parallel_uploads"] = parallelInput
+		}
 
 		// 更新表格列标题
 		columns := []table.Column{
@@ -678,12 +745,13 @@ func (m Model) handleLanguageSelect(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			{Title: i18n.T("upload_list.col_progress"), Width: 10},
 			{Title: i18n.T("upload_list.col_speed"), Width: 10},
 			{Title: i18n.T("upload_list.col_server"), Width: 12},
+			{Title: i18n.T("upload_list.col_destination"), Width: 18},
 			{Title: i18n.T("upload_list.col_status"), Width: 10},
 		}
 		m.uploadTable.SetColumns(columns)
 
 		// 保存配置
-		saveConfig(m.config)
+		m.config.Save()
 
 		// 进入Token输入界面
 		m.state = StateTokenInput
@@ -695,6 +763,12 @@ func (m Model) handleLanguageSelect(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 // handleTokenInput 处理token输入
 func (m Model) handleTokenInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
+	case "ctrl+p":
+		// 有其他账号配置时可以切换账号，不输入当前账号的Token
+		if len(m.config.Profiles) > 0 && !m.isLoading {
+			return m.openProfilePicker()
+		}
+		return m, nil
 	case "enter":
 		if m.tokenInput.Value() != "" {
 			// 验证token，但不保存到配置直到验证成功
@@ -725,22 +799,33 @@ func (m Model) handleTokenValidationFailed(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 
 // handleMainView 处理主界面输入
 func (m Model) handleMainView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// 筛选或路径输入框打开时，按键交给输入框处理
+	if m.browserInputMode != browserInputNone {
+		return m.handleBrowserInput(msg)
+	}
+
 	switch msg.String() {
 	case "tab":
 		m.state = StateSettings
 		return m, nil
 	case "enter":
 		return m.handleFileSelection()
-	case "up":
-		if m.selectedIndex > 1 { // 跳过第一个占位符条目
-			m.selectedIndex--
-		}
-		return m, nil
-	case "down":
-		if m.selectedIndex < len(m.files)-1 {
-			m.selectedIndex++
-		} else if m.selectedIndex == 0 { // 如果在占位符上，移动到第一个真实条目
-			m.selectedIndex = 1
+	case "up", "down":
+		return m.moveSelection(msg.String())
+	case "/":
+		// 模糊筛选当前目录
+		return m.openBrowserInput(browserInputFilter)
+	case "g":
+		// 输入路径跳转
+		return m.openBrowserInput(browserInputPath)
+	case "s":
+		// 切换排序方式
+		return m.cycleSortMode()
+	case "esc":
+		// 清除筛选条件
+		if m.filterQuery != "" {
+			m.filterQuery = ""
+			m.applyFileView()
 		}
 		return m, nil
 	case "left", "right":
@@ -755,6 +840,24 @@ func (m Model) handleMainView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.showHidden = !m.showHidden
 		m.selectedIndex = 1 // 重置选择索引，跳过占位符
 		return m, m.loadFiles()
+	case " ":
+		// 标记/取消标记当前文件
+		return m.toggleMark()
+	case "a":
+		// 标记当前目录中的全部文件
+		return m.toggleMarkAll()
+	case "u":
+		// 上传全部已标记的文件
+		return m.uploadMarkedFiles()
+	case "r":
+		// 递归上传目录
+		return m.uploadDirectory()
+	case "f":
+		// 选择上传的目标文件夹
+		return m.openFolderPicker(folderForBrowser)
+	case "P":
+		// 切换账号
+		return m.openProfilePicker()
 	}
 
 	return m, nil
@@ -765,9 +868,9 @@ func (m Model) handleSettings(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// 根据用户类型确定可用设置（需与 renderSettings 保持一致）
 	var settingsKeys []string
 	if m.userInfo.IsSponsored {
-		settingsKeys = []string{"chunk_size", "concurrency", "server", "quick_upload", "language"}
+		settingsKeys = []string{"chunk_size", "concurrency", "parallel_uploads", "server", "quick_upload", "model", "folder", "language"}
 	} else {
-		settingsKeys = []string{"language"}
+		settingsKeys = []string{"model", "folder", "parallel_uploads", "language"}
 	}
 
 	switch msg.String() {
@@ -832,6 +935,12 @@ func (m Model) handleSettings(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				if m.userInfo.IsSponsored {
 					m.config.QuickUpload = !m.config.QuickUpload
 				}
+			case "model":
+				delta := 1
+				if msg.String() == "left" {
+					delta = -1
+				}
+				m.config.Model = cycleModel(m.config.Model, delta)
 			case "language":
 				langs := i18n.SupportedLanguages
 				curIdx := 0
@@ -864,6 +973,9 @@ func (m Model) handleSettings(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if currentKey == "quick_upload" && m.userInfo.IsSponsored {
 				m.config.QuickUpload = !m.config.QuickUpload
 			}
+			if currentKey == "folder" {
+				return m.openFolderPicker(folderForSettings)
+			}
 		}
 		return m, nil
 	case "enter":
@@ -905,6 +1017,10 @@ func (m Model) applyLanguage(lang string) Model {
 		concInput.Placeholder = i18n.T("settings.concurrency_placeholder")
 		m.settingsInputs["concurrency"] = concInput
 	}
+	if parallelInput, ok := m.settingsInputs["parallel_uploads"]; ok {
+		parallelInput.Placeholder = i18n.T("settings.parallel_uploads_placeholder")
+		m.settingsInputs["parallel_uploads"] = parallelInput
+	}
 
 	columns := []table.Column{
 		{Title: i18n.T("upload_list.col_filename"), Width: 25},
@@ -912,6 +1028,7 @@ func (m Model) applyLanguage(lang string) Model {
 		{Title: i18n.T("upload_list.col_progress"), Width: 10},
 		{Title: i18n.T("upload_list.col_speed"), Width: 10},
 		{Title: i18n.T("upload_list.col_server"), Width: 12},
+		{Title: i18n.T("upload_list.col_destination"), Width: 18},
 		{Title: i18n.T("upload_list.col_status"), Width: 10},
 	}
 	m.uploadTable.SetColumns(columns)
@@ -937,6 +1054,21 @@ func (m Model) handleUploadList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "y":
 		// 清除所有任务
 		return m.clearAllTasks()
+	case "P":
+		// 切换账号
+		return m.openProfilePicker()
+	}
+
+	// 调整队列顺序和暂停只用于本地队列，服务模式下由 tmplink-cli serve 管理队列
+	if m.daemon == nil {
+		switch msg.String() {
+		case "p":
+			return m.toggleQueuePaused()
+		case "shift+up", "K":
+			return m.moveQueuedTask(-1)
+		case "shift+down", "J":
+			return m.moveQueuedTask(1)
+		}
 	}
 
 	var cmd tea.Cmd
@@ -998,14 +1130,14 @@ func (m Model) isFileUploadAllowed(filePath string) (bool, string) {
 		return true, ""
 	}
 
-	// 只有上传失败的文件才允许重新上传
-	if status == "failed" {
+	// 只有上传失败或已取消的文件才允许重新上传
+	if status == "failed" || status == "cancelled" {
 		return true, ""
 	}
 
 	// 其他状态都不允许重复上传
 	switch status {
-	case "starting", "pending", "uploading":
+	case "starting", "pending", "hashing", "uploading":
 		return false, i18n.T("upload.in_progress")
 	case "completed":
 		return false, i18n.T("upload.completed")
@@ -1029,9 +1161,7 @@ func (m Model) handleFileSelection() (tea.Model, tea.Cmd) {
 		} else {
 			// 进入目录
 			newDir := filepath.Join(m.currentDir, selectedFile.Name)
-			m.currentDir = newDir
-			m.selectedIndex = 1
-			return m, m.loadFiles()
+			return m.changeDirectory(newDir)
 		}
 	} else {
 		// 选择文件进行上传
@@ -1061,7 +1191,7 @@ func (m Model) handleFileSelection() (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
-		return m.startFileUpload(filePath)
+		return m.confirmFileUploads([]string{filePath})
 	}
 }
 
@@ -1069,9 +1199,8 @@ func (m Model) handleFileSelection() (tea.Model, tea.Cmd) {
 func (m Model) navigateToParent() (tea.Model, tea.Cmd) {
 	parentDir := filepath.Dir(m.currentDir)
 	if parentDir != m.currentDir { // 确保不是根目录
-		m.currentDir = parentDir
-		m.selectedIndex = 1
-		return m, m.loadFiles()
+		m.selectAfterLoad = filepath.Base(m.currentDir) // 选中刚才所在的目录
+		return m.changeDirectory(parentDir)
 	}
 	return m, nil
 }
@@ -1088,8 +1217,14 @@ func (m Model) cancelSelectedUpload() (tea.Model, tea.Cmd) {
 
 	task := m.uploadTasks[selectedRow]
 
+	// 服务模式：由服务取消并移除任务
+	if m.daemon != nil {
+		m.removeDaemonTask(task.ID)
+		return m, m.removeDaemonTasks(task.ID)
+	}
+
 	// 如果任务正在运行，先尝试终止进程
-	if task.Status == "uploading" || task.Status == "pending" || task.Status == "starting" {
+	if isTaskActive(task.Status) {
 		if task.ProcessID > 0 {
 			// 尝试终止CLI进程
 			if process, err := os.FindProcess(task.ProcessID); err == nil {
@@ -1135,16 +1270,34 @@ func (m Model) cancelSelectedUpload() (tea.Model, tea.Cmd) {
 	// 更新上传表格显示
 	m.updateUploadTable()
 
-	return m, nil
+	// 保存队列，取消的任务腾出位置后启动下一个排队任务
+	m.saveQueue()
+	cmd := m.dispatchQueue()
+
+	return m, cmd
 }
 
 // clearCompletedTasks 清除所有已完成任务
 func (m Model) clearCompletedTasks() (tea.Model, tea.Cmd) {
+	// 服务模式：从服务中移除已结束的任务
+	if m.daemon != nil {
+		var finished []string
+		for _, task := range m.uploadTasks {
+			if !isTaskActive(task.Status) {
+				finished = append(finished, task.ID)
+			}
+		}
+		for _, id := range finished {
+			m.removeDaemonTask(id)
+		}
+		return m, m.removeDaemonTasks(finished...)
+	}
+
 	var activeTasks []TaskStatus
 
 	// 遍历任务，只保留未完成的任务
 	for _, task := range m.uploadTasks {
-		if task.Status != "completed" && task.Status != "failed" {
+		if task.Status != "completed" && task.Status != "failed" && task.Status != "cancelled" {
 			// 保留进行中或等待中的任务
 			activeTasks = append(activeTasks, task)
 		} else {
@@ -1173,9 +1326,21 @@ func (m Model) clearCompletedTasks() (tea.Model, tea.Cmd) {
 
 // clearAllTasks 清除所有任务
 func (m Model) clearAllTasks() (tea.Model, tea.Cmd) {
+	// 服务模式：由服务取消并移除全部任务
+	if m.daemon != nil {
+		var ids []string
+		for _, task := range m.uploadTasks {
+			ids = append(ids, task.ID)
+		}
+		m.uploadTasks = []TaskStatus{}
+		m.uploadTable.SetCursor(0)
+		m.refreshDaemonTasks()
+		return m, m.removeDaemonTasks(ids...)
+	}
+
 	// 终止所有运行中的任务
 	for _, task := range m.uploadTasks {
-		if task.Status == "uploading" || task.Status == "pending" || task.Status == "starting" {
+		if isTaskActive(task.Status) {
 			if task.ProcessID > 0 {
 				// 尝试终止CLI进程
 				if process, err := os.FindProcess(task.ProcessID); err == nil {
@@ -1203,10 +1368,11 @@ func (m Model) clearAllTasks() (tea.Model, tea.Cmd) {
 		}
 	}
 
-	// 清空所有任务
+	// 清空所有任务（包括排队中的任务）
 	m.uploadTasks = []TaskStatus{}
 	m.statusFiles = make(map[string]string)
 	m.activeUploads = 0
+	m.saveQueue()
 
 	// 重置表格
 	m.uploadTable.SetCursor(0)
@@ -1221,9 +1387,16 @@ func (m Model) clearAllTasks() (tea.Model, tea.Cmd) {
 func (m Model) handleUploadProgress(msg UploadProgressMsg) (tea.Model, tea.Cmd) {
 	for i, task := range m.uploadTasks {
 		if task.ID == msg.TaskID {
+			// 任务已结束时忽略之后到达的进度
+			if !isTaskActive(task.Status) {
+				return m, nil
+			}
 			m.uploadTasks[i].Progress = msg.Progress
 			m.uploadTasks[i].UploadSpeed = msg.Speed
-			if msg.Progress > 0 {
+			m.uploadTasks[i].HashProgress = msg.HashProgress
+			if msg.Status == "hashing" || msg.Status == "uploading" {
+				m.uploadTasks[i].Status = msg.Status
+			} else if msg.Progress > 0 {
 				m.uploadTasks[i].Status = "uploading"
 			}
 			m.uploadTasks[i].UpdatedAt = time.Now()
@@ -1239,6 +1412,10 @@ func (m Model) handleUploadProgress(msg UploadProgressMsg) (tea.Model, tea.Cmd)
 func (m Model) handleUploadComplete(msg UploadCompleteMsg) (tea.Model, tea.Cmd) {
 	for i, task := range m.uploadTasks {
 		if task.ID == msg.TaskID {
+			// 进程结束和定时检查可能先后报告同一结果，只处理一次
+			if !isTaskActive(task.Status) {
+				return m, nil
+			}
 			m.uploadTasks[i].Status = "completed"
 			m.uploadTasks[i].Progress = 100.0 // CLI使用0-100的百分比
 			m.uploadTasks[i].DownloadURL = msg.DownloadURL
@@ -1248,13 +1425,19 @@ func (m Model) handleUploadComplete(msg UploadCompleteMsg) (tea.Model, tea.Cmd)
 		}
 	}
 	m.updateUploadTable()
-	return m, nil
+	cmd := m.dispatchQueue()
+	return m, cmd
 }
 
 // handleUploadError 处理上传错误
 func (m Model) handleUploadError(msg UploadErrorMsg) (tea.Model, tea.Cmd) {
 	// 如果有TaskID，更新对应任务状态
 	if msg.TaskID != "" {
+		task := m.findTask(msg.TaskID)
+		if task == nil || !isTaskActive(task.Status) {
+			// 任务已删除或已结束，计数已更新
+			return m, nil
+		}
 		for i, task := range m.uploadTasks {
 			if task.ID == msg.TaskID {
 				m.uploadTasks[i].Status = "failed"
@@ -1268,7 +1451,26 @@ func (m Model) handleUploadError(msg UploadErrorMsg) (tea.Model, tea.Cmd) {
 		m.err = fmt.Errorf("上传失败: %s", msg.Error)
 	}
 	m.activeUploads--
-	return m, nil
+	cmd := m.dispatchQueue()
+	return m, cmd
+}
+
+// handleUploadCancelled 处理CLI进程收到信号后记录的取消状态
+func (m Model) handleUploadCancelled(msg UploadCancelledMsg) (tea.Model, tea.Cmd) {
+	if task := m.findTask(msg.TaskID); task == nil || !isTaskActive(task.Status) {
+		return m, nil
+	}
+	for i, task := range m.uploadTasks {
+		if task.ID == msg.TaskID {
+			m.uploadTasks[i].Status = "cancelled"
+			m.uploadTasks[i].UpdatedAt = time.Now()
+			break
+		}
+	}
+	m.updateUploadTable()
+	m.activeUploads--
+	cmd := m.dispatchQueue()
+	return m, cmd
 }
 
 // handleProcessStarted 处理进程启动
@@ -1284,8 +1486,55 @@ func (m Model) handleProcessStarted(msg ProcessStartedMsg) (tea.Model, tea.Cmd)
 	}
 	m.updateUploadTable()
 
-	// 启动定时器进行进度监控
-	return m, m.startProgressTimer(msg.TaskID)
+	// 启动定时器进行进度监控，同时等待进程结束
+	return m, tea.Batch(m.startProgressTimer(msg.TaskID), waitProcessExit(msg.TaskID, msg.Exited))
+}
+
+// waitProcessExit 等待CLI进程结束，返回 ProcessExitedMsg
+func waitProcessExit(taskID string, exited <-chan error) tea.Cmd {
+	if exited == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return ProcessExitedMsg{TaskID: taskID, Err: <-exited}
+	}
+}
+
+// handleProcessExited 处理CLI进程结束：状态文件中已有最终状态时按该状态处理，
+// 否则进程异常退出（崩溃、被强制终止等），将任务记录为失败并释放队列中的位置
+func (m Model) handleProcessExited(msg ProcessExitedMsg) (tea.Model, tea.Cmd) {
+	task := m.findTask(msg.TaskID)
+	if task == nil || !isTaskActive(task.Status) {
+		return m, nil
+	}
+
+	switch final := m.checkProgress(msg.TaskID)().(type) {
+	case UploadCompleteMsg:
+		return m.handleUploadComplete(final)
+	case UploadErrorMsg:
+		return m.handleUploadError(final)
+	case UploadCancelledMsg:
+		return m.handleUploadCancelled(final)
+	}
+
+	errMsg := "CLI进程已退出但未记录上传结果"
+	if msg.Err != nil {
+		errMsg = fmt.Sprintf("CLI进程异常退出: %v", msg.Err)
+	}
+	if statusFile, ok := m.statusFiles[msg.TaskID]; ok {
+		errMsg += fmt.Sprintf(" (日志: %s.log)", statusFile)
+	}
+	return m.handleUploadError(UploadErrorMsg{TaskID: msg.TaskID, Error: errMsg})
+}
+
+// findTask 按ID查找任务，不存在时返回 nil
+func (m Model) findTask(taskID string) *TaskStatus {
+	for i := range m.uploadTasks {
+		if m.uploadTasks[i].ID == taskID {
+			return &m.uploadTasks[i]
+		}
+	}
+	return nil
 }
 
 // handleProgressTick 处理进度检查定时器
@@ -1296,7 +1545,7 @@ func (m Model) handleProgressTick(msg CheckProgressTickMsg) (tea.Model, tea.Cmd)
 	for i, task := range m.uploadTasks {
 		if task.ID == msg.TaskID {
 			currentTask = &m.uploadTasks[i]
-			if task.Status == "starting" || task.Status == "pending" || task.Status == "uploading" {
+			if isTaskActive(task.Status) {
 				taskExists = true
 			}
 			break
@@ -1310,8 +1559,11 @@ func (m Model) handleProgressTick(msg CheckProgressTickMsg) (tea.Model, tea.Cmd)
 
 	// 检查进程是否还在运行
 	if currentTask.ProcessID > 0 && !isProcessRunning(currentTask.ProcessID) {
-		// 进程已结束，进行最后一次状态检查
-		return m, m.checkProgress(msg.TaskID)
+		// 进程已结束，停止定时检查，由 handleProcessExited 做最后一次状态检查；
+		// 本次启动的进程会先收到带退出错误的 ProcessExitedMsg，这里稍后补发的消息用于上次运行时恢复的任务
+		return m, tea.Tick(time.Second, func(time.Time) tea.Msg {
+			return ProcessExitedMsg{TaskID: msg.TaskID}
+		})
 	}
 
 	// 检查进度并继续定时器
@@ -1327,6 +1579,16 @@ func (m Model) updateComponents(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 	var cmd tea.Cmd
 
+	// 更新文件浏览器输入框（光标闪烁）
+	if m.isBrowserTyping() {
+		m.browserInput, cmd = m.browserInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.isNamingFolder() {
+		m.folderPicker.nameInput, cmd = m.folderPicker.nameInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
 	// 更新文件选择器
 	if m.state == StateMain {
 		m.filePicker, cmd = m.filePicker.Update(msg)
@@ -1334,28 +1596,24 @@ func (m Model) updateComponents(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// 检查文件选择
 		if didSelect, path := m.filePicker.DidSelectFile(msg); didSelect {
-			return m.startFileUpload(path)
+			return m.confirmFileUploads([]string{path})
 		}
 	}
 
 	return m, tea.Batch(cmds...)
 }
 
-// startFileUpload 开始文件上传
-func (m Model) startFileUpload(filePath string) (tea.Model, tea.Cmd) {
-	m.selectedFile = filePath
-	m.activeUploads++
-
-	// 生成任务ID（包含纳秒确保唯一性）
-	taskID := fmt.Sprintf("task_%d", time.Now().UnixNano())
-	homeDir, _ := os.UserHomeDir()
-	statusDir := filepath.Join(homeDir, ".tmplink", "tasks")
-	os.MkdirAll(statusDir, 0755)
-	statusFile := filepath.Join(statusDir, taskID+".json")
-	m.statusFiles[taskID] = statusFile
+// startFileUploads 按顺序将多个文件加入上传队列，使用确认界面中选择的文件有效期和目标文件夹
+func (m Model) startFileUploads(filePaths []string, options uploadOptions) (tea.Model, tea.Cmd) {
+	if len(filePaths) == 0 {
+		return m, nil
+	}
+	m.selectedFile = filePaths[len(filePaths)-1]
 
-	// 立即创建任务状态并添加到任务列表
-	fileInfo, _ := os.Stat(filePath)
+	// 服务模式：加入服务的上传队列，任务由事件流添加到列表
+	if m.daemon != nil {
+		return m, m.enqueueDaemonUploads(filePaths, options)
+	}
 
 	// 获取当前选中的服务器名称
 	selectedServerName := "未知"
@@ -1363,25 +1621,51 @@ func (m Model) startFileUpload(filePath string) (tea.Model, tea.Cmd) {
 		selectedServerName = m.availableServers[m.serverIndex].Name
 	}
 
-	task := TaskStatus{
-		ID:         taskID,
-		Status:     "starting",
-		FilePath:   filePath,
-		FileName:   filepath.Base(filePath),
-		FileSize:   fileInfo.Size(),
-		Progress:   0.0,
-		ServerName: selectedServerName, // 设置服务器名称
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
-	}
+	// 立即创建任务状态并添加到任务列表，排队等待启动
+	for _, filePath := range filePaths {
+		fileInfo, err := os.Stat(filePath)
+		if err != nil {
+			continue
+		}
+
+		model := options.model
+		task := TaskStatus{
+			ID:         m.newTaskID(),
+			Status:     "queued",
+			FilePath:   filePath,
+			FileName:   filepath.Base(filePath),
+			FileSize:   fileInfo.Size(),
+			Progress:   0.0,
+			ServerName: selectedServerName, // 设置服务器名称
+			Model:      &model,
+			MrID:       options.folder.ID,
+			FolderPath: options.folder.Path,
+			CreatedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
+		}
 
-	// 添加到任务列表
-	m.uploadTasks = append(m.uploadTasks, task)
+		// 添加到任务列表
+		m.uploadTasks = append(m.uploadTasks, task)
+	}
 
 	// 更新上传表格
 	m.updateUploadTable()
 
-	return m, m.startUpload(filePath, taskID, statusFile)
+	// 保存队列，未达到并发上限时立即启动
+	m.saveQueue()
+	cmd := m.dispatchQueue()
+
+	return m, cmd
+}
+
+// newTaskID 生成任务ID（包含纳秒确保唯一性，同一批次中的任务依次递增）
+func (m *Model) newTaskID() string {
+	id := time.Now().UnixNano()
+	if id <= m.lastTaskID {
+		id = m.lastTaskID + 1
+	}
+	m.lastTaskID = id
+	return fmt.Sprintf("task_%d", id)
 }
 
 // updateUploadTable 更新上传任务表格
@@ -1401,22 +1685,31 @@ func (m *Model) updateUploadTable() {
 			sizeStr = fmt.Sprintf("%.1fGB", float64(task.FileSize)/(1024*1024*1024))
 		}
 
-		// 格式化进度 (CLI返回的是0-100的百分比，直接使用)
+		// 格式化进度 (CLI返回的是0-100的百分比，直接使用)，计算SHA1期间显示计算进度
 		progressStr := fmt.Sprintf("%.1f%%", task.Progress)
+		if task.Status == "hashing" {
+			progressStr = fmt.Sprintf("%.1f%%", task.HashProgress)
+		}
 
 		// 状态翻译
 		statusStr := task.Status
 		switch task.Status {
+		case "queued":
+			statusStr = i18n.T("task.queued")
 		case "starting":
 			statusStr = i18n.T("task.starting")
 		case "pending":
 			statusStr = i18n.T("task.pending")
+		case "hashing":
+			statusStr = i18n.T("task.hashing")
 		case "uploading":
 			statusStr = i18n.T("task.uploading")
 		case "completed":
 			statusStr = i18n.T("task.completed")
 		case "failed":
 			statusStr = i18n.T("task.failed")
+		case "cancelled":
+			statusStr = i18n.T("task.cancelled")
 		}
 
 		// 速度显示（上传中和已完成都显示最终速度）
@@ -1441,6 +1734,7 @@ func (m *Model) updateUploadTable() {
 			progressStr,
 			speedStr,
 			serverStr,
+			taskDestination(task),
 			statusStr,
 		}
 		rows = append(rows, row)
@@ -1536,6 +1830,12 @@ func (m Model) renderTokenInput() string {
 	content.WriteString(subtitleStyle.Render(i18n.T("auth.subtitle")))
 	content.WriteString("\n\n")
 
+	// 有多个账号配置时显示正在为哪个账号输入Token
+	if len(m.config.Profiles) > 0 {
+		content.WriteString(subtitleStyle.Render(i18n.Tf("auth.profile", config.ProfileName(m.config.ActiveProfile()))))
+		content.WriteString("\n\n")
+	}
+
 	// 显示错误信息（如果有）
 	if m.err != nil {
 		errorBoxStyle := lipgloss.NewStyle().
@@ -1642,6 +1942,7 @@ func (m Model) renderStatusBar() string {
 	} else {
 		line1 = i18n.T("status.not_logged_in")
 	}
+	line1 += i18n.Tf("status.profile", config.ProfileName(m.config.ActiveProfile()))
 	lines = append(lines, statusBarStyle.Width(statusWidth).Render(line1))
 
 	// 第二行：存储信息
@@ -1671,12 +1972,26 @@ func (m Model) renderStatusBar() string {
 		}
 		line2 += uploadText
 	}
+	if queued := m.countQueuedTasks(); queued > 0 {
+		line2 += i18n.Tf("status.queued", queued)
+	}
+	if m.daemon != nil && m.daemonOffline {
+		line2 += i18n.T("status.daemon_offline")
+	}
 	lines = append(lines, statusBarStyle.Width(statusWidth).Render(line2))
 
 	// 第三行：操作提示
 	var line3 string
 	switch m.state {
 	case StateMain:
+		if m.browserInputMode == browserInputFilter {
+			line3 = i18n.T("filebrowser.filter_keys")
+			break
+		}
+		if m.browserInputMode == browserInputPath {
+			line3 = i18n.T("filebrowser.path_keys")
+			break
+		}
 		parentDir := filepath.Dir(m.currentDir)
 		enterAction := i18n.T("nav.enter")
 		if len(m.files) > 0 && m.selectedIndex < len(m.files) {
@@ -1696,6 +2011,20 @@ func (m Model) renderStatusBar() string {
 		line3 = i18n.T("upload_list.keys")
 	case StateError:
 		line3 = i18n.T("error.keys")
+	case StateConfirmUpload:
+		line3 = i18n.T("confirm.keys")
+	case StateFolderPicker:
+		if m.folderPicker.creating {
+			line3 = i18n.T("folder.create_keys")
+		} else {
+			line3 = i18n.T("folder.keys")
+		}
+	case StateProfilePicker:
+		if m.profilePicker.creating {
+			line3 = i18n.T("profile.create_keys")
+		} else {
+			line3 = i18n.T("profile.keys")
+		}
 	default:
 		line3 = i18n.T("default.keys")
 	}
@@ -1759,8 +2088,8 @@ func cleanupAndLoadTasks() ([]TaskStatus, map[string]string, error) {
 		// 检查任务状态
 		shouldKeep := false
 
-		if task.Status == "completed" || task.Status == "failed" {
-			// 已完成或失败的任务保留并加载到UI中
+		if task.Status == "completed" || task.Status == "failed" || task.Status == "cancelled" {
+			// 已完成、失败或已取消的任务保留并加载到UI中
 			shouldKeep = true
 			validTasks = append(validTasks, task)
 			statusFiles[task.ID] = statusFile
@@ -1821,6 +2150,12 @@ func (m Model) renderContent() string {
 		return m.renderUploadList()
 	case StateError:
 		return m.renderError()
+	case StateConfirmUpload:
+		return m.renderConfirmUpload()
+	case StateFolderPicker:
+		return m.renderFolderPicker()
+	case StateProfilePicker:
+		return m.renderProfilePicker()
 	default:
 		return i18n.T("unknown_state")
 	}
@@ -2001,9 +2336,19 @@ func (m Model) renderMainView() string {
 	if m.showHidden {
 		title += i18n.T("filebrowser.show_hidden")
 	}
+	title += m.sortModeLabel()
 	s.WriteString(titleStyle.Render(title))
 	s.WriteString("\n")
 	s.WriteString(i18n.Tf("filebrowser.current_dir", m.currentDir))
+
+	// 目标文件夹、已标记的文件、筛选条件和输入框
+	var header strings.Builder
+	header.WriteString(i18n.Tf("filebrowser.destination", m.uploadFolder.label()))
+	if count, totalSize := m.markedSummary(); count > 0 {
+		header.WriteString(i18n.Tf("filebrowser.marked", count, formatFileSize(totalSize)))
+	}
+	header.WriteString(m.renderBrowserHeader())
+	s.WriteString(header.String())
 	s.WriteString(helpStyle.Render(i18n.T("filebrowser.legend")))
 
 	// 文件列表
@@ -2011,7 +2356,7 @@ func (m Model) renderMainView() string {
 		s.WriteString(i18n.T("filebrowser.empty"))
 	} else {
 		// 显示文件列表
-		maxHeight := m.height - 10 // 为三行状态栏和标题留空间
+		maxHeight := m.height - 10 - strings.Count(header.String(), "\n") // 为三行状态栏和标题留空间
 		if maxHeight < 5 || m.height == 0 {
 			maxHeight = 10 // 为未初始化的终端提供合理的默认值
 		}
@@ -2041,6 +2386,17 @@ func (m Model) renderMainView() string {
 				prefix = "> "
 			}
 
+			// 有标记的文件时显示标记列
+			marked := false
+			if len(m.markedFiles) > 0 {
+				marked = !file.IsDir && m.isFileMarked(filepath.Join(m.currentDir, file.Name))
+				if marked {
+					prefix += "✓ "
+				} else {
+					prefix += "  "
+				}
+			}
+
 			// 文件/目录图标和状态圆点
 			var icon string
 			var statusDot string
@@ -2055,14 +2411,16 @@ func (m Model) renderMainView() string {
 				status, exists := m.getFileUploadStatus(filePath)
 				if exists {
 					switch status {
-					case "starting", "pending":
+					case "queued", "starting", "pending":
 						statusDot = " 🟡" // 黄色圆点：等待中
-					case "uploading":
+					case "hashing", "uploading":
 						statusDot = " 🔵" // 蓝色圆点：上传中
 					case "completed":
 						statusDot = " 🟢" // 绿色圆点：已完成
 					case "failed":
 						statusDot = " 🔴" // 红色圆点：上传失败
+					case "cancelled":
+						statusDot = " ⚪" // 白色圆点：已取消
 					default:
 						statusDot = ""
 					}
@@ -2090,9 +2448,11 @@ func (m Model) renderMainView() string {
 				line += fmt.Sprintf(" (%s)", sizeStr)
 			}
 
-			// 根据选中状态设置颜色
+			// 根据选中和标记状态设置颜色
 			if i == m.selectedIndex {
 				line = lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Render(line)
+			} else if marked {
+				line = lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Render(line)
 			}
 
 			s.WriteString(line)
@@ -2128,19 +2488,27 @@ func (m Model) renderSettings() string {
 	var settingsSponsored []bool
 
 	if m.userInfo.IsSponsored {
-		settingsKeys = []string{"chunk_size", "concurrency", "server", "quick_upload", "language"}
+		settingsKeys = []string{"chunk_size", "concurrency", "parallel_uploads", "server", "quick_upload", "model", "folder", "language"}
 		settingsLabels = []string{
 			i18n.T("settings.chunk_size"),
 			i18n.T("settings.concurrency"),
+			i18n.T("settings.parallel_uploads"),
 			i18n.T("settings.server"),
 			i18n.T("settings.quick_upload"),
+			i18n.T("settings.model"),
+			i18n.T("settings.folder"),
 			i18n.T("settings.language"),
 		}
-		settingsSponsored = []bool{true, true, true, true, false}
+		settingsSponsored = []bool{true, true, false, true, true, false, false, false}
 	} else {
-		settingsKeys = []string{"language"}
-		settingsLabels = []string{i18n.T("settings.language")}
-		settingsSponsored = []bool{false}
+		settingsKeys = []string{"model", "folder", "parallel_uploads", "language"}
+		settingsLabels = []string{
+			i18n.T("settings.model"),
+			i18n.T("settings.folder"),
+			i18n.T("settings.parallel_uploads"),
+			i18n.T("settings.language"),
+		}
+		settingsSponsored = []bool{false, false, false, false}
 	}
 
 	for i, key := range settingsKeys {
@@ -2171,6 +2539,10 @@ func (m Model) renderSettings() string {
 				status = i18n.T("settings.on")
 			}
 			line = fmt.Sprintf("%s%s\n%s  %s %s", prefix, label, strings.Repeat(" ", len(prefix)), status, i18n.T("settings.toggle_space"))
+		} else if key == "model" {
+			line = fmt.Sprintf("%s%s\n%s  %s %s", prefix, label, strings.Repeat(" ", len(prefix)), modelLabel(m.config.Model), i18n.T("settings.switch_lr"))
+		} else if key == "folder" {
+			line = fmt.Sprintf("%s%s\n%s  %s %s", prefix, label, strings.Repeat(" ", len(prefix)), m.defaultFolderLabel(), i18n.T("settings.choose_space"))
 		} else if key == "language" {
 			currentLang := i18n.LanguageNames[i18n.GetLanguage()]
 			line = fmt.Sprintf("%s%s\n%s  %s %s", prefix, label, strings.Repeat(" ", len(prefix)), currentLang, i18n.T("settings.switch_lr"))
@@ -2205,7 +2577,11 @@ func (m Model) renderSettings() string {
 func (m Model) renderUploadList() string {
 	var s strings.Builder
 
-	s.WriteString(titleStyle.Render(i18n.T("upload_list.title")))
+	title := i18n.T("upload_list.title")
+	if m.queuePaused && m.daemon == nil {
+		title += i18n.T("upload_list.paused")
+	}
+	s.WriteString(titleStyle.Render(title))
 	s.WriteString("\n\n")
 
 	if len(m.uploadTasks) == 0 {
@@ -2232,8 +2608,17 @@ func (m Model) renderError() string {
 	return s.String()
 }
 
-// startUpload 开始上传文件
-func (m Model) startUpload(filePath, taskID, statusFile string) tea.Cmd {
+// startUpload 启动CLI进程上传任务中的文件
+func (m Model) startUpload(task TaskStatus, statusFile string) tea.Cmd {
+	filePath, taskID := task.FilePath, task.ID
+	mrID := task.MrID
+	if mrID == "" {
+		mrID = api.RootFolderID
+	}
+	model := m.config.Model
+	if task.Model != nil {
+		model = *task.Model
+	}
 	return func() tea.Msg {
 		// CLI现在是自包含的，不需要预先获取上传信息
 		// 启动CLI进程，只传递CLI支持的参数
@@ -2255,14 +2640,16 @@ func (m Model) startUpload(filePath, taskID, statusFile string) tea.Cmd {
 		// 构建CLI命令参数
 		args := []string{
 			"-file", filePath,
-			"-token", m.config.Token,
 			"-task-id", taskID,
 			"-status-file", statusFile,
 			"-chunk-size", fmt.Sprintf("%d", m.config.ChunkSize),
-			"-model", "1",
-			"-mr-id", "0",
+			"-concurrency", fmt.Sprintf("%d", m.config.MaxConcurrent),
+			"-model", fmt.Sprintf("%d", model),
+			"-mr-id", mrID,
 			"-skip-upload", skipUpload,
 			"-server-name", selectedServerName,
+			"-api-server", m.apiServer,
+			"-link-base", m.linkBase,
 		}
 
 		// GUI模式下始终传递选中的上传服务器地址
@@ -2271,6 +2658,11 @@ func (m Model) startUpload(filePath, taskID, statusFile string) tea.Cmd {
 		}
 
 		cmd := exec.Command(m.cliPath, args...)
+		// Token通过环境变量传递，命令行参数可以被其他用户通过 ps 看到
+		// 同时指定账号配置，CLI读取的保存值与TUI使用同一个账号
+		cmd.Env = append(os.Environ(),
+			credential.TokenEnv+"="+m.config.Token,
+			config.ProfileEnv+"="+config.ProfileName(m.config.ActiveProfile()))
 
 		// 设置输出到文件，便于调试
 		logFile := statusFile + ".log"
@@ -2288,114 +2680,17 @@ func (m Model) startUpload(filePath, taskID, statusFile string) tea.Cmd {
 		// 获取进程ID
 		processID := cmd.Process.Pid
 
-		// 后台等待进程完成
+		// 后台等待进程结束，退出结果由 waitProcessExit 转为消息
+		exited := make(chan error, 1)
 		go func() {
-			cmd.Wait() // 等待进程完成
+			exited <- cmd.Wait()
 		}()
 
 		// 返回进程启动消息，包含进程ID
-		return ProcessStartedMsg{TaskID: taskID, ProcessID: processID}
+		return ProcessStartedMsg{TaskID: taskID, ProcessID: processID, Exited: exited}
 	}
 }
 
-// UploadInfo 上传信息
-type UploadInfo struct {
-	Server string
-	UToken string
-}
-
-// getUploadInfo 获取上传服务器和token信息
-func (m Model) getUploadInfo(filePath string) (*UploadInfo, error) {
-	// 获取文件信息
-	fileInfo, err := os.Stat(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("获取文件信息失败: %w", err)
-	}
-
-	// 计算文件SHA1
-	sha1Hash, err := calculateFileSHA1(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("计算SHA1失败: %w", err)
-	}
-
-	client := &http.Client{Timeout: 10 * time.Second}
-
-	// 调用upload_request_select2获取上传服务器
-	formData := fmt.Sprintf("action=upload_request_select2&sha1=%s&filename=%s&filesize=%d&model=1&token=%s",
-		sha1Hash, filepath.Base(filePath), fileInfo.Size(), m.config.Token)
-
-	req, err := http.NewRequest("POST", "https://tmplink-sec.vxtrans.com/api_v2/file", strings.NewReader(formData))
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var selectResp struct {
-		Status int `json:"status"`
-		Data   struct {
-			UToken  string      `json:"utoken"`
-			Servers interface{} `json:"servers"`
-		} `json:"data"`
-	}
-
-	if err := json.Unmarshal(body, &selectResp); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %w", err)
-	}
-
-	if selectResp.Status != 1 {
-		return nil, fmt.Errorf("获取上传服务器失败，状态码: %d", selectResp.Status)
-	}
-
-	// 解析servers字段
-	var uploadServer string
-	if selectResp.Data.Servers != nil {
-		if serverList, ok := selectResp.Data.Servers.([]interface{}); ok && len(serverList) > 0 {
-			// servers是对象数组，每个对象有url字段
-			if serverObj, ok := serverList[0].(map[string]interface{}); ok {
-				if serverURL, ok := serverObj["url"].(string); ok {
-					uploadServer = serverURL
-				}
-			}
-		}
-	}
-
-	if uploadServer == "" {
-		return nil, fmt.Errorf("无法获取上传服务器地址")
-	}
-
-	return &UploadInfo{
-		Server: uploadServer,
-		UToken: selectResp.Data.UToken,
-	}, nil
-}
-
-// calculateFileSHA1 计算文件SHA1
-func calculateFileSHA1(filePath string) (string, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
-	hasher := sha1.New()
-	if _, err := io.Copy(hasher, file); err != nil {
-		return "", err
-	}
-
-	return hex.EncodeToString(hasher.Sum(nil)), nil
-}
-
 // checkProgress 检查上传进度
 func (m Model) checkProgress(taskID string) tea.Cmd {
 	return func() tea.Msg {
@@ -2422,9 +2717,11 @@ func (m Model) checkProgress(taskID string) tea.Cmd {
 			return UploadCompleteMsg{TaskID: taskID, DownloadURL: task.DownloadURL}
 		case "failed":
 			return UploadErrorMsg{Error: task.ErrorMsg, TaskID: taskID}
+		case "cancelled":
+			return UploadCancelledMsg{TaskID: taskID}
 		default:
 			// 返回当前进度，继续监控
-			return UploadProgressMsg{TaskID: taskID, Progress: task.Progress, Speed: task.UploadSpeed}
+			return UploadProgressMsg{TaskID: taskID, Status: task.Status, Progress: task.Progress, HashProgress: task.HashProgress, Speed: task.UploadSpeed}
 		}
 	}
 }
@@ -2438,9 +2735,11 @@ func (m Model) startProgressTimer(taskID string) tea.Cmd {
 
 // 消息类型
 type UploadProgressMsg struct {
-	TaskID   string
-	Progress float64
-	Speed    float64 // KB/s
+	TaskID       string
+	Status       string // CLI状态文件中的状态（hashing/uploading等）
+	Progress     float64
+	HashProgress float64 // SHA1计算进度
+	Speed        float64 // KB/s
 }
 
 type UploadCompleteMsg struct {
@@ -2456,77 +2755,42 @@ type UploadErrorMsg struct {
 type ProcessStartedMsg struct {
 	TaskID    string
 	ProcessID int
+	Exited    <-chan error // 进程结束时收到 Wait 的结果
 }
 
-type CheckProgressTickMsg struct {
+// ProcessExitedMsg CLI进程已结束
+type ProcessExitedMsg struct {
 	TaskID string
+	Err    error // 进程的退出错误，正常退出时为 nil
 }
 
-// 样式
-// getConfigPath 获取配置文件路径
-func getConfigPath() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		homeDir = "/tmp"
-	}
-	return filepath.Join(homeDir, ".tmplink_config.json")
+type UploadCancelledMsg struct {
+	TaskID string
 }
 
-// loadConfig 加载配置
-func loadConfig() Config {
-	configPath := getConfigPath()
-
-	// 如果配置文件不存在，返回默认配置
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return defaultConfig()
-	}
+type CheckProgressTickMsg struct {
+	TaskID string
+}
 
-	// 读取配置文件
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return defaultConfig()
-	}
+// 样式
+// credentialStore 保存API Token的凭据存储（与CLI共用）
+var credentialStore = credential.Default()
 
-	// 解析配置
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return defaultConfig()
-	}
+// loadConfig 加载配置和当前账号的配置，Token从凭据存储读取
+// 配置文件无法读取时使用默认配置，旧版本的配置在读取时迁移
+func loadConfig() config.Config {
+	saved, _ := config.Load()
 
-	// 清理旧的默认配置
-	if config.UploadServer == "https://tmplink-sec.vxtrans.com/api_v2" {
-		config.UploadServer = ""
-	}
-	if config.SelectedServerName == "默认 (自动选择)" || config.SelectedServerName == "默认服务器" {
-		config.SelectedServerName = ""
-	}
+	// 指定的账号配置不存在时使用默认账号
+	saved.UseProfile(selectedProfileName(saved))
+	saved.LoadToken()
 
 	// 应用已保存的语言设置
-	if config.Language != "" {
-		i18n.SetLanguage(config.Language)
-	}
-
-	return config
-}
-
-// saveConfig 保存配置
-func saveConfig(config Config) error {
-	configPath := getConfigPath()
-
-	// 确保目录存在
-	configDir := filepath.Dir(configPath)
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return err
-	}
-
-	// 序列化配置
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return err
+	if saved.Language != "" {
+		i18n.SetLanguage(saved.Language)
 	}
 
-	// 写入文件
-	return os.WriteFile(configPath, data, 0644)
+	return saved
 }
 
 // simplifyErrorMessage 简化错误信息，使其对用户更友好
@@ -2552,7 +2816,7 @@ func simplifyErrorMessage(err string) string {
 func (m Model) validateAndSaveToken(token string) tea.Cmd {
 	return func() tea.Msg {
 		// 先验证token
-		userInfo, err := callUserAPI(token)
+		userInfo, err := callUserAPI(newAPIClient(m.apiServer, token))
 		if err != nil {
 			return UserInfoErrorMsg{Error: err.Error()}
 		}
@@ -2560,7 +2824,7 @@ func (m Model) validateAndSaveToken(token string) tea.Cmd {
 		// 验证成功后保存token到配置
 		config := m.config
 		config.Token = token
-		if err := saveConfig(config); err != nil {
+		if err := config.Save(); err != nil {
 			return UserInfoErrorMsg{Error: fmt.Sprintf("保存配置失败: %v", err)}
 		}
 
@@ -2579,7 +2843,7 @@ func (m Model) startReturnToTokenInputDelay() tea.Cmd {
 func (m Model) fetchUserInfo() tea.Cmd {
 	return func() tea.Msg {
 		// 调用实际API获取用户信息
-		userInfo, err := callUserAPI(m.config.Token)
+		userInfo, err := callUserAPI(newAPIClient(m.apiServer, m.config.Token))
 		if err != nil {
 			return UserInfoErrorMsg{Error: err.Error()}
 		}
@@ -2589,103 +2853,38 @@ func (m Model) fetchUserInfo() tea.Cmd {
 }
 
 // callUserAPI 调用用户信息API
-func callUserAPI(token string) (UserInfo, error) {
-	client := &http.Client{Timeout: 10 * time.Second}
+func callUserAPI(client *api.Client) (UserInfo, error) {
+	ctx := context.Background()
 
 	// 第一步：获取基本用户信息和存储信息
-	detailData := fmt.Sprintf("action=get_detail&token=%s", token)
-	detailReq, err := http.NewRequest("POST", "https://tmplink-sec.vxtrans.com/api_v2/user", strings.NewReader(detailData))
+	detailResp, err := client.GetDetail(ctx)
 	if err != nil {
 		return UserInfo{}, err
 	}
 
-	detailReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	detailResp, err := client.Do(detailReq)
-	if err != nil {
-		return UserInfo{}, err
-	}
-	defer detailResp.Body.Close()
-
-	if detailResp.StatusCode != 200 {
-		return UserInfo{}, fmt.Errorf("HTTP错误: %d", detailResp.StatusCode)
-	}
-
-	detailBody, err := io.ReadAll(detailResp.Body)
-	if err != nil {
-		return UserInfo{}, err
-	}
-
-	// 解析详细信息响应
-	var detailApiResp struct {
-		Status int `json:"status"`
-		Data   struct {
-			UID         int64 `json:"uid"`
-			Storage     int64 `json:"storage"`
-			StorageUsed int64 `json:"storage_used"`
-			Sponsor     bool  `json:"sponsor"`
-		} `json:"data"`
-		Msg string `json:"msg"`
-	}
-
-	if err := json.Unmarshal(detailBody, &detailApiResp); err != nil {
-		return UserInfo{}, fmt.Errorf("解析详细信息失败: %w", err)
-	}
-
-	if detailApiResp.Status != 1 {
-		return UserInfo{}, fmt.Errorf("获取详细信息失败: %s", detailApiResp.Msg)
+	if detailResp.Status != 1 {
+		return UserInfo{}, fmt.Errorf("获取详细信息失败: %s", detailResp.Msg)
 	}
 
 	// 第二步：获取用户名信息
-	userInfoData := fmt.Sprintf("action=pf_userinfo_get&token=%s", token)
-	userInfoReq, err := http.NewRequest("POST", "https://tmplink-sec.vxtrans.com/api_v2/user", strings.NewReader(userInfoData))
-	if err != nil {
-		return UserInfo{}, err
-	}
-
-	userInfoReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	userInfoResp, err := client.Do(userInfoReq)
+	profileResp, err := client.GetUserProfile(ctx)
 	if err != nil {
 		return UserInfo{}, err
 	}
-	defer userInfoResp.Body.Close()
-
-	if userInfoResp.StatusCode != 200 {
-		return UserInfo{}, fmt.Errorf("HTTP错误: %d", userInfoResp.StatusCode)
-	}
-
-	userInfoBody, err := io.ReadAll(userInfoResp.Body)
-	if err != nil {
-		return UserInfo{}, err
-	}
-
-	// 解析用户信息响应
-	var userInfoApiResp struct {
-		Status int `json:"status"`
-		Data   struct {
-			Nickname string `json:"nickname"`
-		} `json:"data"`
-		Msg string `json:"msg"`
-	}
-
-	if err := json.Unmarshal(userInfoBody, &userInfoApiResp); err != nil {
-		return UserInfo{}, fmt.Errorf("解析用户信息失败: %w", err)
-	}
 
 	// 如果获取用户名失败，使用默认值
 	username := "用户"
-	if userInfoApiResp.Status == 1 && userInfoApiResp.Data.Nickname != "" {
-		username = userInfoApiResp.Data.Nickname
+	if profileResp.Status == 1 && profileResp.Profile.Nickname != "" {
+		username = profileResp.Profile.Nickname
 	}
 
 	return UserInfo{
 		Username:    username,
 		Email:       "", // API似乎不返回邮箱
-		UID:         fmt.Sprintf("%d", detailApiResp.Data.UID),
-		IsSponsored: detailApiResp.Data.Sponsor,
-		UsedSpace:   detailApiResp.Data.StorageUsed,
-		TotalSpace:  detailApiResp.Data.Storage,
+		UID:         fmt.Sprintf("%d", detailResp.Detail.UID),
+		IsSponsored: detailResp.Detail.Sponsor,
+		UsedSpace:   detailResp.Detail.StorageUsed,
+		TotalSpace:  detailResp.Detail.Storage,
 	}, nil
 }
 
@@ -2776,11 +2975,11 @@ func (m Model) saveSettings() (tea.Model, tea.Cmd) {
 	var settingsSponsored []bool
 
 	if m.userInfo.IsSponsored {
-		settingsKeys = []string{"chunk_size", "concurrency", "server", "quick_upload", "language"}
-		settingsSponsored = []bool{true, true, true, true, false}
+		settingsKeys = []string{"chunk_size", "concurrency", "parallel_uploads", "server", "quick_upload", "model", "folder", "language"}
+		settingsSponsored = []bool{true, true, false, true, true, false, false, false}
 	} else {
-		settingsKeys = []string{"language"}
-		settingsSponsored = []bool{false}
+		settingsKeys = []string{"model", "folder", "parallel_uploads", "language"}
+		settingsSponsored = []bool{false, false, false, false}
 	}
 
 	// 解析和验证输入值
@@ -2803,6 +3002,9 @@ func (m Model) saveSettings() (tea.Model, tea.Cmd) {
 		} else if key == "quick_upload" && m.userInfo.IsSponsored {
 			// 已在按键处理中直接修改
 			continue
+		} else if key == "model" || key == "folder" {
+			// 文件有效期已在按键处理中直接修改，默认文件夹在文件夹选择界面中保存
+			continue
 		} else if key == "language" {
 			// 语言已在按键处理中通过 applyLanguage 修改，config.Language 已更新
 			continue
@@ -2836,19 +3038,27 @@ func (m Model) saveSettings() (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 			m.config.MaxConcurrent = intValue
+		case "parallel_uploads":
+			if intValue < 1 || intValue > 10 {
+				m.err = fmt.Errorf("同时上传文件数必须在 1-10 之间")
+				m.state = StateError
+				return m, nil
+			}
+			m.config.ParallelUploads = intValue
 		}
 	}
 
 	// 保存配置到文件
-	if err := saveConfig(m.config); err != nil {
+	if err := m.config.Save(); err != nil {
 		m.err = fmt.Errorf("保存配置失败: %w", err)
 		m.state = StateError
 		return m, nil
 	}
 
-	// 返回主界面
+	// 返回主界面，同时上传文件数可能已调整，检查是否可以启动更多排队任务
 	m.state = StateMain
-	return m, nil
+	cmd := m.dispatchQueue()
+	return m, cmd
 }
 
 // 样式
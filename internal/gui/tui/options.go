@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"tmplink_uploader/internal/api"
+	"tmplink_uploader/internal/i18n"
+)
+
+// uploadOptions 上传使用的文件有效期和目标文件夹
+type uploadOptions struct {
+	model  int
+	folder remoteFolder
+}
+
+// defaultUploadOptions 新上传的默认选项：设置中的文件有效期和当前的目标文件夹
+func (m Model) defaultUploadOptions() uploadOptions {
+	return uploadOptions{model: m.config.Model, folder: m.uploadFolder}
+}
+
+// uploadModels 可选的文件有效期 (0=24小时, 1=3天, 2=7天, 99=永久)
+var uploadModels = []int{0, 1, 2, 99}
+
+// modelLabel 文件有效期的显示名称
+func modelLabel(model int) string {
+	switch model {
+	case 0:
+		return i18n.T("model.24h")
+	case 1:
+		return i18n.T("model.3d")
+	case 2:
+		return i18n.T("model.7d")
+	case 99:
+		return i18n.T("model.forever")
+	}
+	return i18n.Tf("model.unknown", model)
+}
+
+// cycleModel 切换到上一个 (delta=-1) 或下一个 (delta=1) 文件有效期
+func cycleModel(model, delta int) int {
+	index := 0
+	for i, value := range uploadModels {
+		if value == model {
+			index = i
+			break
+		}
+	}
+	index = (index + delta + len(uploadModels)) % len(uploadModels)
+	return uploadModels[index]
+}
+
+// defaultFolderLabel 设置中保存的默认文件夹的显示名称
+func (m Model) defaultFolderLabel() string {
+	if m.uploadFolder.ID == m.config.MrID && m.uploadFolder.Path != "" {
+		return m.uploadFolder.label()
+	}
+	return defaultUploadFolder(m.config).label()
+}
+
+// taskDestination 上传列表中显示的文件有效期和目标文件夹
+func taskDestination(task TaskStatus) string {
+	if task.Model == nil && task.MrID == "" {
+		return ""
+	}
+
+	folder := remoteFolder{ID: task.MrID, Path: task.FolderPath}
+	if folder.ID == "" || (folder.ID == api.RootFolderID && folder.Path == "") {
+		folder = remoteFolder{ID: api.RootFolderID, Path: "/"}
+	}
+	if task.Model == nil {
+		return folder.label()
+	}
+	return modelLabel(*task.Model) + " " + folder.label()
+}
@@ -0,0 +1,250 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"tmplink_uploader/internal/config"
+	"tmplink_uploader/internal/credential"
+	"tmplink_uploader/internal/i18n"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// profilePicker 账号切换界面的状态
+type profilePicker struct {
+	names       []string
+	tokens      map[string]string // 账号名称 -> 遮盖后的Token，未保存Token时为空
+	cursor      int
+	returnState State // 关闭后返回的界面
+	err         string
+	creating    bool // 正在输入新账号配置的名称
+	nameInput   textinput.Model
+}
+
+// newProfileNameInput 新账号配置名称的输入框
+func newProfileNameInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = "+ "
+	input.Width = 32
+	input.CharLimit = 32
+	return input
+}
+
+// openProfilePicker 打开账号切换界面，选中当前使用的账号
+func (m Model) openProfilePicker() (tea.Model, tea.Cmd) {
+	picker := &m.profilePicker
+	picker.returnState = m.state
+	picker.names = m.config.ProfileNames()
+	picker.tokens = make(map[string]string, len(picker.names))
+	picker.err = ""
+	picker.creating = false
+	picker.cursor = 0
+	for i, name := range picker.names {
+		if name == m.config.ActiveProfile() {
+			picker.cursor = i
+			picker.tokens[name] = credential.Redact(m.config.Token)
+			continue
+		}
+		if token, err := credentialStore.Load(name); err == nil {
+			picker.tokens[name] = credential.Redact(token)
+		}
+	}
+	m.state = StateProfilePicker
+	return m, nil
+}
+
+// handleProfilePicker 处理账号切换界面的按键
+func (m Model) handleProfilePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	picker := &m.profilePicker
+
+	// 输入新账号配置的名称
+	if picker.creating {
+		switch msg.String() {
+		case "esc":
+			picker.creating = false
+			picker.nameInput.Blur()
+			return m, nil
+		case "enter":
+			return m.createProfile(strings.TrimSpace(picker.nameInput.Value()))
+		}
+		var cmd tea.Cmd
+		picker.nameInput, cmd = picker.nameInput.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.state = picker.returnState
+		return m, nil
+	case "up":
+		if picker.cursor > 0 {
+			picker.cursor--
+		}
+		return m, nil
+	case "down":
+		if picker.cursor < len(picker.names)-1 {
+			picker.cursor++
+		}
+		return m, nil
+	case "enter":
+		if picker.cursor < len(picker.names) {
+			return m.switchProfile(picker.names[picker.cursor])
+		}
+		return m, nil
+	case "n":
+		picker.creating = true
+		picker.err = ""
+		picker.nameInput.SetValue("")
+		picker.nameInput.Placeholder = i18n.T("profile.name_placeholder")
+		return m, picker.nameInput.Focus()
+	}
+	return m, nil
+}
+
+// createProfile 添加账号配置并切换过去，之后输入该账号的Token
+func (m Model) createProfile(name string) (tea.Model, tea.Cmd) {
+	picker := &m.profilePicker
+	if name == "" {
+		return m, nil
+	}
+	if config.ValidateProfileName(name) != nil {
+		picker.err = i18n.T("profile.invalid_name")
+		return m, nil
+	}
+	if _, exists := m.config.Profiles[name]; exists {
+		picker.err = i18n.Tf("profile.exists", name)
+		return m, nil
+	}
+	if m.daemon == nil && (m.activeUploads > 0 || m.countQueuedTasks() > 0) {
+		picker.err = i18n.T("profile.busy")
+		return m, nil
+	}
+
+	picker.creating = false
+	picker.nameInput.Blur()
+	m.config.SetProfile(name, config.NewProfile())
+	return m.switchProfile(name)
+}
+
+// switchProfile 切换到指定的账号，保存为当前账号后重新获取用户信息
+// 本地队列中的任务使用启动时的账号上传，有进行中或排队的任务时不能切换
+func (m Model) switchProfile(name string) (tea.Model, tea.Cmd) {
+	picker := &m.profilePicker
+	if name == m.config.ActiveProfile() && m.config.Token != "" {
+		m.state = picker.returnState
+		return m, nil
+	}
+	if m.daemon == nil && (m.activeUploads > 0 || m.countQueuedTasks() > 0) {
+		picker.err = i18n.T("profile.busy")
+		return m, nil
+	}
+
+	token, err := credentialStore.Load(name)
+	if err != nil {
+		picker.err = err.Error()
+		return m, nil
+	}
+
+	config := m.config
+	config.Token = "" // Token已在凭据存储中，不需要重新保存
+	config.UseProfile(name)
+	config.Profile = name
+	if err := config.Save(); err != nil {
+		picker.err = i18n.Tf("profile.save_failed", err)
+		return m, nil
+	}
+	config.Token = token
+
+	m.config = config
+	m.userInfo = UserInfo{}
+	m.uploadFolder = defaultUploadFolder(config)
+	m.serverIndex = 0 // 获取到用户信息后按账号保存的服务器重新选择
+	m.settingsIndex = 0
+	for key, input := range m.settingsInputs {
+		input.Blur()
+		m.settingsInputs[key] = input
+	}
+	if chunkInput, ok := m.settingsInputs["chunk_size"]; ok {
+		chunkInput.SetValue(fmt.Sprintf("%d", config.ChunkSize))
+		m.settingsInputs["chunk_size"] = chunkInput
+	}
+
+	// 该账号还没有保存Token时先输入Token
+	if token == "" {
+		m.err = nil
+		m.state = StateTokenInput
+		m.tokenInput.SetValue("")
+		return m, m.tokenInput.Focus()
+	}
+
+	m.state = StateInit
+	m.isLoading = true
+	return m, m.fetchUserInfo()
+}
+
+// renderProfilePicker 渲染账号切换界面
+func (m Model) renderProfilePicker() string {
+	picker := m.profilePicker
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render(i18n.T("profile.title")))
+	s.WriteString("\n")
+
+	nameWidth := 0
+	for _, name := range picker.names {
+		if width := lipgloss.Width(config.ProfileName(name)); width > nameWidth {
+			nameWidth = width
+		}
+	}
+	for i, name := range picker.names {
+		token := picker.tokens[name]
+		if token == "" {
+			token = i18n.T("profile.no_token")
+		}
+		label := config.ProfileName(name) + strings.Repeat(" ", nameWidth-lipgloss.Width(config.ProfileName(name)))
+		if i == picker.cursor {
+			label = lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Render("> " + label)
+		} else {
+			label = "  " + label
+		}
+		s.WriteString(label + "  " + helpStyle.Render(token))
+		if name == m.config.ActiveProfile() {
+			s.WriteString(i18n.T("profile.current"))
+		}
+		s.WriteString("\n")
+	}
+
+	if picker.creating {
+		s.WriteString("\n")
+		s.WriteString(picker.nameInput.View())
+		s.WriteString("\n")
+	}
+	if picker.err != "" {
+		s.WriteString("\n")
+		s.WriteString(errorStyle.Render(picker.err))
+		s.WriteString("\n")
+	}
+
+	return s.String()
+}
+
+// isNamingProfile 是否正在输入新账号配置的名称
+func (m Model) isNamingProfile() bool {
+	return m.state == StateProfilePicker && m.profilePicker.creating
+}
+
+// selectedProfileName 启动时使用的账号配置：TMPLINK_PROFILE 环境变量 > 保存的当前账号
+func selectedProfileName(saved config.Config) string {
+	name := saved.Profile
+	if env := os.Getenv(config.ProfileEnv); env != "" {
+		name = env
+	}
+	if name == config.DefaultProfileName {
+		return ""
+	}
+	return name
+}
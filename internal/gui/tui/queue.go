@@ -0,0 +1,184 @@
+package tui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// uploadQueue 持久化的上传队列：尚未启动的任务（按上传顺序）和暂停状态
+// 已启动的任务由各自的状态文件记录
+type uploadQueue struct {
+	Paused bool         `json:"paused"`
+	Tasks  []TaskStatus `json:"tasks"`
+}
+
+// QueueDispatchMsg 检查队列并启动等待中的任务
+type QueueDispatchMsg struct{}
+
+// getQueuePath 获取队列文件路径
+func getQueuePath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "/tmp"
+	}
+	return filepath.Join(homeDir, ".tmplink", "queue.json")
+}
+
+// loadQueue 读取上次保存的队列，文件不存在或无法解析时返回空队列
+func loadQueue() uploadQueue {
+	var queue uploadQueue
+	data, err := os.ReadFile(getQueuePath())
+	if err != nil {
+		return queue
+	}
+	if err := json.Unmarshal(data, &queue); err != nil {
+		return uploadQueue{}
+	}
+
+	// 只保留仍在排队且文件仍存在的任务
+	tasks := queue.Tasks[:0]
+	for _, task := range queue.Tasks {
+		if task.Status != "queued" {
+			continue
+		}
+		if _, err := os.Stat(task.FilePath); err != nil {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	queue.Tasks = tasks
+	return queue
+}
+
+// saveQueue 保存队列（先写临时文件再重命名）
+func (m Model) saveQueue() error {
+	queue := uploadQueue{Paused: m.queuePaused, Tasks: []TaskStatus{}}
+	for _, task := range m.uploadTasks {
+		if task.Status == "queued" {
+			queue.Tasks = append(queue.Tasks, task)
+		}
+	}
+
+	queuePath := getQueuePath()
+	if err := os.MkdirAll(filepath.Dir(queuePath), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(queue, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := queuePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, queuePath)
+}
+
+// maxParallelUploads 同时上传的文件数上限（设置中的同时上传文件数）
+// 每个文件另有 MaxConcurrent 个分片并发上传，总连接数为两者之积
+func (m Model) maxParallelUploads() int {
+	if m.config.ParallelUploads < 1 {
+		return 1
+	}
+	return m.config.ParallelUploads
+}
+
+// countQueuedTasks 统计排队中的任务数
+func (m Model) countQueuedTasks() int {
+	count := 0
+	for _, task := range m.uploadTasks {
+		if task.Status == "queued" {
+			count++
+		}
+	}
+	return count
+}
+
+// dispatchQueue 按顺序启动排队中的任务，直到进行中的任务数达到上限；队列暂停时不启动新任务
+func (m *Model) dispatchQueue() tea.Cmd {
+	if m.queuePaused {
+		return nil
+	}
+
+	running := 0
+	for _, task := range m.uploadTasks {
+		if isTaskActive(task.Status) {
+			running++
+		}
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	statusDir := filepath.Join(homeDir, ".tmplink", "tasks")
+
+	var cmds []tea.Cmd
+	for i := range m.uploadTasks {
+		if running >= m.maxParallelUploads() {
+			break
+		}
+		task := &m.uploadTasks[i]
+		if task.Status != "queued" {
+			continue
+		}
+
+		os.MkdirAll(statusDir, 0755)
+		statusFile := filepath.Join(statusDir, task.ID+".json")
+		m.statusFiles[task.ID] = statusFile
+
+		task.Status = "starting"
+		task.UpdatedAt = time.Now()
+		running++
+		m.activeUploads++
+		cmds = append(cmds, m.startUpload(*task, statusFile))
+	}
+
+	if len(cmds) == 0 {
+		return nil
+	}
+	m.saveQueue()
+	m.updateUploadTable()
+	return tea.Batch(cmds...)
+}
+
+// handleQueueDispatch 处理队列检查消息
+func (m Model) handleQueueDispatch() (tea.Model, tea.Cmd) {
+	cmd := m.dispatchQueue()
+	return m, cmd
+}
+
+// toggleQueuePaused 暂停或继续队列，正在上传的任务不受影响
+func (m Model) toggleQueuePaused() (tea.Model, tea.Cmd) {
+	m.queuePaused = !m.queuePaused
+	m.saveQueue()
+	cmd := m.dispatchQueue()
+	return m, cmd
+}
+
+// moveQueuedTask 调整选中的排队任务在队列中的位置，delta 为 -1（提前）或 1（推后）
+// 只与相邻的排队任务交换位置，已启动的任务保持不动
+func (m Model) moveQueuedTask(delta int) (tea.Model, tea.Cmd) {
+	selectedRow := m.uploadTable.Cursor()
+	if selectedRow < 0 || selectedRow >= len(m.uploadTasks) || m.uploadTasks[selectedRow].Status != "queued" {
+		return m, nil
+	}
+
+	target := -1
+	for i := selectedRow + delta; i >= 0 && i < len(m.uploadTasks); i += delta {
+		if m.uploadTasks[i].Status == "queued" {
+			target = i
+			break
+		}
+	}
+	if target < 0 {
+		return m, nil
+	}
+
+	m.uploadTasks[selectedRow], m.uploadTasks[target] = m.uploadTasks[target], m.uploadTasks[selectedRow]
+	m.uploadTable.SetCursor(target)
+	m.updateUploadTable()
+	m.saveQueue()
+	return m, nil
+}
@@ -0,0 +1,296 @@
+package tui
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"tmplink_uploader/internal/i18n"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxUploadFileSize 单个文件的大小上限 (50GB)
+const maxUploadFileSize = 50 * 1024 * 1024 * 1024
+
+// uploadRequest 等待确认的上传
+type uploadRequest struct {
+	dir       string   // 上传目录时为目录路径，上传文件时为空
+	files     []string // 待上传的文件（按路径排序）
+	totalSize int64
+	skipped   int // 已在上传列表中、超出大小限制或无法读取的文件数
+	options   uploadOptions
+}
+
+// DirScannedMsg 目录扫描完成
+type DirScannedMsg struct {
+	Upload uploadRequest
+	Error  string
+}
+
+// formatFileSize 格式化文件大小
+func formatFileSize(size int64) string {
+	switch {
+	case size < 1024:
+		return fmt.Sprintf("%dB", size)
+	case size < 1024*1024:
+		return fmt.Sprintf("%.1fKB", float64(size)/1024)
+	case size < 1024*1024*1024:
+		return fmt.Sprintf("%.1fMB", float64(size)/(1024*1024))
+	default:
+		return fmt.Sprintf("%.1fGB", float64(size)/(1024*1024*1024))
+	}
+}
+
+// isFileMarked 文件是否已被标记
+func (m Model) isFileMarked(filePath string) bool {
+	_, ok := m.markedFiles[filePath]
+	return ok
+}
+
+// toggleMark 标记或取消标记光标所在的文件，并移动到下一项
+func (m Model) toggleMark() (tea.Model, tea.Cmd) {
+	if len(m.files) == 0 || m.selectedIndex >= len(m.files) {
+		return m, nil
+	}
+
+	file := m.files[m.selectedIndex]
+	if !file.IsDir && file.Name != "" {
+		filePath := filepath.Join(m.currentDir, file.Name)
+		if m.isFileMarked(filePath) {
+			delete(m.markedFiles, filePath)
+		} else {
+			m.markedFiles[filePath] = file.Size
+		}
+	}
+
+	if m.selectedIndex < len(m.files)-1 {
+		m.selectedIndex++
+	}
+	return m, nil
+}
+
+// toggleMarkAll 标记当前目录中的全部文件，已全部标记时取消标记
+func (m Model) toggleMarkAll() (tea.Model, tea.Cmd) {
+	allMarked := true
+	for _, file := range m.files {
+		if !file.IsDir && file.Name != "" && !m.isFileMarked(filepath.Join(m.currentDir, file.Name)) {
+			allMarked = false
+			break
+		}
+	}
+
+	for _, file := range m.files {
+		if file.IsDir || file.Name == "" {
+			continue
+		}
+		filePath := filepath.Join(m.currentDir, file.Name)
+		if allMarked {
+			delete(m.markedFiles, filePath)
+		} else {
+			m.markedFiles[filePath] = file.Size
+		}
+	}
+	return m, nil
+}
+
+// markedSummary 已标记的文件数和总大小
+func (m Model) markedSummary() (int, int64) {
+	var totalSize int64
+	for _, size := range m.markedFiles {
+		totalSize += size
+	}
+	return len(m.markedFiles), totalSize
+}
+
+// checkUploadable 检查文件是否可以上传：普通文件、不超过大小限制、不在上传列表中
+func (m Model) checkUploadable(filePath string) (int64, bool) {
+	info, err := os.Stat(filePath)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > maxUploadFileSize {
+		return 0, false
+	}
+	if allowed, _ := m.isFileUploadAllowed(filePath); !allowed {
+		return 0, false
+	}
+	return info.Size(), true
+}
+
+// uploadMarkedFiles 上传全部已标记的文件，无法上传的文件被跳过
+func (m Model) uploadMarkedFiles() (tea.Model, tea.Cmd) {
+	if len(m.markedFiles) == 0 {
+		return m, nil
+	}
+
+	var filePaths []string
+	for filePath := range m.markedFiles {
+		if _, ok := m.checkUploadable(filePath); ok {
+			filePaths = append(filePaths, filePath)
+		}
+	}
+	m.markedFiles = make(map[string]int64)
+
+	if len(filePaths) == 0 {
+		m.err = fmt.Errorf("所选文件均已在上传列表中或无法上传")
+		m.state = StateError
+		return m, nil
+	}
+
+	// 按路径排序，保证上传顺序与文件浏览器中的顺序一致
+	sort.Strings(filePaths)
+	return m.confirmFileUploads(filePaths)
+}
+
+// confirmFileUploads 显示上传确认界面，确认后按顺序将文件加入上传队列
+func (m Model) confirmFileUploads(filePaths []string) (tea.Model, tea.Cmd) {
+	upload := uploadRequest{files: filePaths, options: m.defaultUploadOptions()}
+	for _, filePath := range filePaths {
+		if info, err := os.Stat(filePath); err == nil {
+			upload.totalSize += info.Size()
+		}
+	}
+	m.pendingUpload = &upload
+	m.state = StateConfirmUpload
+	return m, nil
+}
+
+// uploadDirectory 扫描光标所在的目录（光标不在目录上时为当前目录），扫描完成后请求确认
+func (m Model) uploadDirectory() (tea.Model, tea.Cmd) {
+	dir := m.currentDir
+	if len(m.files) > 0 && m.selectedIndex < len(m.files) {
+		file := m.files[m.selectedIndex]
+		if file.IsDir && file.Name != ".." {
+			dir = filepath.Join(m.currentDir, file.Name)
+		}
+	}
+	return m, m.scanDirectory(dir)
+}
+
+// scanDirectory 递归扫描目录中可以上传的文件，未显示隐藏文件时同时跳过隐藏文件和目录
+func (m Model) scanDirectory(dir string) tea.Cmd {
+	// 扫描在后台进行，使用任务列表的副本检查文件是否已在上传列表中
+	m.uploadTasks = append([]TaskStatus(nil), m.uploadTasks...)
+	return func() tea.Msg {
+		upload := uploadRequest{dir: dir}
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// 无法读取的子目录跳过，根目录无法读取时报错
+				if path == dir {
+					return err
+				}
+				upload.skipped++
+				return nil
+			}
+			if path != dir && !m.showHidden && strings.HasPrefix(d.Name(), ".") {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() || !d.Type().IsRegular() {
+				return nil
+			}
+
+			size, ok := m.checkUploadable(path)
+			if !ok {
+				upload.skipped++
+				return nil
+			}
+			upload.files = append(upload.files, path)
+			upload.totalSize += size
+			return nil
+		})
+		if err != nil {
+			return DirScannedMsg{Error: fmt.Sprintf("读取目录失败: %v", err)}
+		}
+		return DirScannedMsg{Upload: upload}
+	}
+}
+
+// handleDirScanned 目录扫描完成，显示确认界面
+func (m Model) handleDirScanned(msg DirScannedMsg) (tea.Model, tea.Cmd) {
+	if msg.Error != "" {
+		m.err = fmt.Errorf("%s", msg.Error)
+		m.state = StateError
+		return m, nil
+	}
+	if len(msg.Upload.files) == 0 {
+		m.err = fmt.Errorf("目录中没有可以上传的文件: %s", msg.Upload.dir)
+		m.state = StateError
+		return m, nil
+	}
+
+	upload := msg.Upload
+	upload.options = m.defaultUploadOptions()
+	m.pendingUpload = &upload
+	m.state = StateConfirmUpload
+	return m, nil
+}
+
+// handleConfirmUpload 处理上传确认界面的按键：切换文件有效期、选择目标文件夹
+func (m Model) handleConfirmUpload(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	upload := m.pendingUpload
+	if upload == nil {
+		m.state = StateMain
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "enter", "y":
+		m.pendingUpload = nil
+		m.state = StateMain
+		return m.startFileUploads(upload.files, upload.options)
+	case "esc", "n":
+		m.pendingUpload = nil
+		m.state = StateMain
+		return m, nil
+	case "left":
+		upload.options.model = cycleModel(upload.options.model, -1)
+		return m, nil
+	case "right":
+		upload.options.model = cycleModel(upload.options.model, 1)
+		return m, nil
+	case "f":
+		return m.openFolderPicker(folderForUpload)
+	}
+	return m, nil
+}
+
+// renderConfirmUpload 渲染上传确认界面
+func (m Model) renderConfirmUpload() string {
+	var s strings.Builder
+
+	upload := m.pendingUpload
+	if upload == nil {
+		return ""
+	}
+
+	if upload.dir != "" {
+		s.WriteString(titleStyle.Render(i18n.T("confirm.title")))
+		s.WriteString("\n\n")
+		s.WriteString(i18n.Tf("confirm.summary", upload.dir, len(upload.files), formatFileSize(upload.totalSize)))
+	} else {
+		s.WriteString(titleStyle.Render(i18n.T("confirm.files_title")))
+		s.WriteString("\n\n")
+		if len(upload.files) == 1 {
+			s.WriteString(i18n.Tf("confirm.file", upload.files[0], formatFileSize(upload.totalSize)))
+		} else {
+			s.WriteString(i18n.Tf("confirm.files", len(upload.files), formatFileSize(upload.totalSize)))
+		}
+	}
+	if upload.skipped > 0 {
+		s.WriteString("\n")
+		s.WriteString(helpStyle.Render(i18n.Tf("confirm.skipped", upload.skipped)))
+	}
+
+	s.WriteString("\n\n")
+	s.WriteString(i18n.Tf("confirm.model", modelLabel(upload.options.model)))
+	s.WriteString("\n")
+	s.WriteString(i18n.Tf("confirm.folder", upload.options.folder.label()))
+	s.WriteString("\n\n")
+	s.WriteString(helpStyle.Render(i18n.T("confirm.help")))
+
+	return s.String()
+}
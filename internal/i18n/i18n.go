@@ -46,6 +46,7 @@ var translations = map[string]map[string]string{
 		"auth.instructions":   "请输入您的钛盘 Token 来开始使用：\n\n1. 访问 https://tmp.link/ 并登录您的账户\n2. 点击\"上传文件\"按钮，然后点击\"重新设定\"按钮，滑动到窗口底部，点击 \"使用 CLI 上传\"\n3. 点击 Token 以复制到剪贴板",
 		"auth.token_label":    "Token:",
 		"auth.help":           "💡 Enter: 验证并保存  •  Ctrl+C: 退出程序",
+		"auth.profile":        "账号配置: %s  •  Ctrl+P: 切换账号",
 		"auth.error_box":      "❌ 验证失败\n\n%s",
 		"auth.placeholder":    "请输入钛盘 API Token",
 
@@ -60,24 +61,30 @@ var translations = map[string]map[string]string{
 		"loading.token": "\n%s 正在验证Token...",
 
 		// Status bar
-		"status.user":          "用户: %s",
-		"status.not_logged_in": "用户: 未登录",
-		"status.sponsor":       " ✨ (赞助者)",
-		"status.regular":       " (普通用户)",
-		"status.storage":       "存储: %.1fGB/%.1fGB (%.1f%%)",
-		"status.no_storage":    "存储: 无私有空间",
-		"status.uploading":     " | 上传中: %d个文件",
-		"status.speed_mb":      " (%.1fMB/s)",
-		"status.speed_kb":      " (%.1fKB/s)",
+		"status.user":           "用户: %s",
+		"status.not_logged_in":  "用户: 未登录",
+		"status.sponsor":        " ✨ (赞助者)",
+		"status.regular":        " (普通用户)",
+		"status.storage":        "存储: %.1fGB/%.1fGB (%.1f%%)",
+		"status.no_storage":     "存储: 无私有空间",
+		"status.uploading":      " | 上传中: %d个文件",
+		"status.queued":         " | 排队: %d个文件",
+		"status.speed_mb":       " (%.1fMB/s)",
+		"status.speed_kb":       " (%.1fKB/s)",
+		"status.daemon_offline": " | 上传服务未连接，正在重连...",
+		"status.profile":        " | 账号: %s (P:切换)",
 
 		// Navigation hint keys
 		"nav.enter":           "进入",
 		"nav.upload":          "上传",
-		"nav.keys_with_parent":"↑↓:选择 ←→:上级 Enter:%s t:隐藏文件 Tab:设置 Q:退出",
-		"nav.keys_no_parent":  "↑↓:选择 Enter:%s t:隐藏文件 Tab:设置 Q:退出",
+		"nav.keys_with_parent":"↑↓:选择 ←→:上级 Enter:%s Space:标记 a:全选 u:上传所选 r:上传目录 /:筛选 s:排序 g:跳转 f:文件夹 t:隐藏文件 P:账号 Tab:设置 Q:退出",
+		"nav.keys_no_parent":  "↑↓:选择 Enter:%s Space:标记 a:全选 u:上传所选 r:上传目录 /:筛选 s:排序 g:跳转 f:文件夹 t:隐藏文件 P:账号 Tab:设置 Q:退出",
 		"settings.keys":       "↑↓:选择 Enter:保存 Tab:上传管理 Esc:返回 Q:退出",
-		"upload_list.keys":    "↑↓:选择 d:删除 t:清除完成 y:清除全部 Tab:文件浏览 Esc:返回 Q:退出",
+		"upload_list.keys":    "↑↓:选择 Shift+↑↓:调整顺序 p:暂停/继续 d:删除 t:清除完成 y:清除全部 P:账号 Tab:文件浏览 Esc:返回 Q:退出",
 		"error.keys":          "操作: Enter:重试 Esc:返回 Q:退出",
+		"confirm.keys":        "操作: Enter:开始上传 ←→:有效期 f:文件夹 Esc:取消 Q:退出",
+		"folder.keys":          "↑↓:选择 Enter/→:打开 ←:上级 u:上传到此文件夹 d:设为默认 n:新建文件夹 r:刷新 Esc:返回 Q:退出",
+		"folder.create_keys":   "Enter:创建 Esc:取消",
 		"default.keys":        "操作: Q:退出",
 
 		// Menu
@@ -93,9 +100,22 @@ var translations = map[string]map[string]string{
 		"filebrowser.title":       "文件浏览器",
 		"filebrowser.show_hidden": " (显示隐藏文件)",
 		"filebrowser.current_dir": "当前目录: %s\n",
+		"filebrowser.marked":      "已选择: %d个文件 (%s)\n",
+		"filebrowser.destination":        "上传到: %s\n",
 		"filebrowser.legend":      "📁目录 📄文件 🟡等待 🔵上传中 🟢已完成 🔴失败\n\n",
 		"filebrowser.empty":       "目录为空或正在加载...",
 		"filebrowser.scroll":      "\n[显示 %d-%d / 共 %d 项]",
+		"filebrowser.sort_name":          " · 按名称排序",
+		"filebrowser.sort_size":          " · 按大小排序",
+		"filebrowser.sort_mtime":         " · 按修改时间排序",
+		"filebrowser.filter":             "筛选: %s (%d/%d)\n",
+		"filebrowser.filter_count":       "匹配: %d/%d\n",
+		"filebrowser.no_match":           "没有匹配的文件\n",
+		"filebrowser.filter_placeholder": "输入文件名进行模糊筛选",
+		"filebrowser.path_prompt":        "跳转到: ",
+		"filebrowser.path_placeholder":   "输入或粘贴路径",
+		"filebrowser.filter_keys":        "输入以筛选 ↑↓:选择 Enter:确定 Esc:清除筛选",
+		"filebrowser.path_keys":          "Enter:跳转 Esc:取消",
 
 		// Settings
 		"settings.title":           "上传设置",
@@ -103,8 +123,11 @@ var translations = map[string]map[string]string{
 		"settings.require_sponsor": "⚠️  部分设置需要赞助者权限\n\n",
 		"settings.chunk_size":      "分块大小 (MB):",
 		"settings.concurrency":     "并发数:",
+		"settings.parallel_uploads": "同时上传文件数:",
 		"settings.server":          "上传服务器:",
 		"settings.quick_upload":    "快速上传:",
+		"settings.model":                   "文件有效期:",
+		"settings.folder":                  "默认上传文件夹:",
 		"settings.language":        "界面语言:",
 		"settings.default_server":  "默认",
 		"settings.on":              "开启",
@@ -112,29 +135,78 @@ var translations = map[string]map[string]string{
 		"settings.switch_lr":       "(←/→ 切换)",
 		"settings.toggle_space":    "(Space 切换)",
 		"settings.read_only":       "(只读)",
+		"settings.choose_space":            "(Space 选择)",
 		"settings.chunk_placeholder":       "分块大小(MB)",
 		"settings.concurrency_placeholder": "并发数",
+		"settings.parallel_uploads_placeholder": "同时上传文件数",
 
 		// Upload manager
 		"upload_list.title":       "上传管理器",
+		"upload_list.paused":      " (队列已暂停)",
 		"upload_list.empty":       "暂无上传任务",
 		"upload_list.col_filename":"文件名",
 		"upload_list.col_size":    "大小",
 		"upload_list.col_progress":"进度",
 		"upload_list.col_speed":   "速度",
 		"upload_list.col_server":  "服务器",
+		"upload_list.col_destination": "有效期/文件夹",
 		"upload_list.col_status":  "状态",
 
 		// Error screen
 		"error.title": "错误",
 		"error.retry": "• Enter: 重试 • Esc: 返回",
 
+		// Upload confirmation
+		"confirm.title":       "上传目录",
+		"confirm.summary":     "目录: %s\n文件数: %d\n总大小: %s",
+		"confirm.skipped":     "跳过 %d 个文件（已在上传列表中、超过50GB或无法读取）",
+		"confirm.files_title": "上传文件",
+		"confirm.file":        "文件: %s\n大小: %s",
+		"confirm.files":       "文件数: %d\n总大小: %s",
+		"confirm.model":       "文件有效期: %s",
+		"confirm.folder":      "上传到: %s",
+		"confirm.help":        "• Enter: 开始上传 • ←/→: 切换有效期 • f: 选择文件夹 • Esc: 取消",
+
+		// File expiry
+		"model.24h":     "24小时",
+		"model.3d":      "3天",
+		"model.7d":      "7天",
+		"model.forever": "永久",
+		"model.unknown": "模式 %d",
+
+		// Folder picker
+		"folder.title":            "选择上传文件夹",
+		"folder.current":          "当前位置: %s\n",
+		"folder.destination":      "上传到: %s",
+		"folder.loading":          "正在加载文件夹...",
+		"folder.empty":            "没有子文件夹",
+		"folder.id_only":          "目录ID %s",
+		"folder.list_failed":      "获取文件夹列表失败 (状态码: %d)",
+		"folder.create_failed":    "创建文件夹失败 (状态码: %d)",
+		"folder.save_failed":      "保存默认文件夹失败: %v",
+		"folder.name_placeholder": "新文件夹名称",
+
+		// Account profiles
+		"profile.title":            "切换账号",
+		"profile.current":          " (当前)",
+		"profile.no_token":         "未设置Token",
+		"profile.keys":             "↑↓:选择 Enter:切换 n:新建账号 Esc:返回 Q:退出",
+		"profile.create_keys":      "Enter:创建 Esc:取消",
+		"profile.name_placeholder": "新账号配置名称",
+		"profile.invalid_name":     "名称只能包含字母、数字、'.'、'_' 和 '-'，最长32个字符，且不能为 default",
+		"profile.exists":           "账号配置已存在: %s",
+		"profile.busy":             "有正在进行或排队的上传，完成后再切换账号",
+		"profile.save_failed":      "保存配置失败: %v",
+
 		// Task statuses
+		"task.queued":     "排队中",
 		"task.starting":   "启动中",
 		"task.pending":    "等待中",
+		"task.hashing":    "校验中",
 		"task.uploading":  "上传中",
 		"task.completed":  "已完成",
 		"task.failed":     "失败",
+		"task.cancelled":  "已取消",
 		"task.unknown_server": "未知",
 
 		// Upload check messages
@@ -168,6 +240,7 @@ var translations = map[string]map[string]string{
 		"auth.instructions":"Enter your TMPLINK Token to get started:\n\n1. Visit https://tmp.link/ and log in\n2. Click \"Upload File\", then \"Reset\", scroll to the bottom and click \"Use CLI Upload\"\n3. Click the Token to copy it to the clipboard",
 		"auth.token_label": "Token:",
 		"auth.help":        "💡 Enter: Verify & Save  •  Ctrl+C: Quit",
+		"auth.profile":     "Profile: %s  •  Ctrl+P: Switch account",
 		"auth.error_box":   "❌ Verification Failed\n\n%s",
 		"auth.placeholder": "Enter your TMPLINK API Token",
 
@@ -182,24 +255,30 @@ var translations = map[string]map[string]string{
 		"loading.token": "\n%s Validating token...",
 
 		// Status bar
-		"status.user":          "User: %s",
-		"status.not_logged_in": "User: Not logged in",
-		"status.sponsor":       " ✨ (Sponsor)",
-		"status.regular":       " (Free User)",
-		"status.storage":       "Storage: %.1fGB/%.1fGB (%.1f%%)",
-		"status.no_storage":    "Storage: No private space",
-		"status.uploading":     " | Uploading: %d file(s)",
-		"status.speed_mb":      " (%.1fMB/s)",
-		"status.speed_kb":      " (%.1fKB/s)",
+		"status.user":           "User: %s",
+		"status.not_logged_in":  "User: Not logged in",
+		"status.sponsor":        " ✨ (Sponsor)",
+		"status.regular":        " (Free User)",
+		"status.storage":        "Storage: %.1fGB/%.1fGB (%.1f%%)",
+		"status.no_storage":     "Storage: No private space",
+		"status.uploading":      " | Uploading: %d file(s)",
+		"status.queued":         " | Queued: %d file(s)",
+		"status.speed_mb":       " (%.1fMB/s)",
+		"status.speed_kb":       " (%.1fKB/s)",
+		"status.daemon_offline": " | Upload service offline, reconnecting...",
+		"status.profile":        " | Account: %s (P:Switch)",
 
 		// Navigation hint keys
 		"nav.enter":            "Open",
 		"nav.upload":           "Upload",
-		"nav.keys_with_parent": "↑↓:Select ←→:Parent Enter:%s t:Hidden Tab:Settings Q:Quit",
-		"nav.keys_no_parent":   "↑↓:Select Enter:%s t:Hidden Tab:Settings Q:Quit",
+		"nav.keys_with_parent": "↑↓:Select ←→:Parent Enter:%s Space:Mark a:All u:UploadMarked r:UploadDir /:Filter s:Sort g:GoTo f:Folder t:Hidden P:Account Tab:Settings Q:Quit",
+		"nav.keys_no_parent":   "↑↓:Select Enter:%s Space:Mark a:All u:UploadMarked r:UploadDir /:Filter s:Sort g:GoTo f:Folder t:Hidden P:Account Tab:Settings Q:Quit",
 		"settings.keys":        "↑↓:Select Enter:Save Tab:Uploads Esc:Back Q:Quit",
-		"upload_list.keys":     "↑↓:Select d:Delete t:ClearDone y:ClearAll Tab:Files Esc:Back Q:Quit",
+		"upload_list.keys":     "↑↓:Select Shift+↑↓:Reorder p:Pause/Resume d:Delete t:ClearDone y:ClearAll P:Account Tab:Files Esc:Back Q:Quit",
 		"error.keys":           "Actions: Enter:Retry Esc:Back Q:Quit",
+		"confirm.keys":         "Actions: Enter:Upload ←→:Expiry f:Folder Esc:Cancel Q:Quit",
+		"folder.keys":          "↑↓:Select Enter/→:Open ←:Parent u:Upload here d:Set default n:New folder r:Refresh Esc:Back Q:Quit",
+		"folder.create_keys":   "Enter:Create Esc:Cancel",
 		"default.keys":         "Actions: Q:Quit",
 
 		// Menu
@@ -215,9 +294,22 @@ var translations = map[string]map[string]string{
 		"filebrowser.title":       "File Browser",
 		"filebrowser.show_hidden": " (Showing hidden files)",
 		"filebrowser.current_dir": "Current directory: %s\n",
+		"filebrowser.marked":      "Selected: %d file(s) (%s)\n",
+		"filebrowser.destination":        "Upload to: %s\n",
 		"filebrowser.legend":      "📁Dir 📄File 🟡Waiting 🔵Uploading 🟢Done 🔴Failed\n\n",
 		"filebrowser.empty":       "Directory is empty or loading...",
 		"filebrowser.scroll":      "\n[Showing %d-%d of %d items]",
+		"filebrowser.sort_name":          " · Sorted by name",
+		"filebrowser.sort_size":          " · Sorted by size",
+		"filebrowser.sort_mtime":         " · Sorted by modified time",
+		"filebrowser.filter":             "Filter: %s (%d/%d)\n",
+		"filebrowser.filter_count":       "Matches: %d/%d\n",
+		"filebrowser.no_match":           "No matching files\n",
+		"filebrowser.filter_placeholder": "Type to fuzzy-filter file names",
+		"filebrowser.path_prompt":        "Go to: ",
+		"filebrowser.path_placeholder":   "Type or paste a path",
+		"filebrowser.filter_keys":        "Type to filter ↑↓:Select Enter:Apply Esc:Clear filter",
+		"filebrowser.path_keys":          "Enter:Go Esc:Cancel",
 
 		// Settings
 		"settings.title":           "Upload Settings",
@@ -225,8 +317,11 @@ var translations = map[string]map[string]string{
 		"settings.require_sponsor": "⚠️  Some settings require sponsor access\n\n",
 		"settings.chunk_size":      "Chunk Size (MB):",
 		"settings.concurrency":     "Concurrency:",
+		"settings.parallel_uploads": "Parallel Files:",
 		"settings.server":          "Upload Server:",
 		"settings.quick_upload":    "Quick Upload:",
+		"settings.model":                   "File Expiry:",
+		"settings.folder":                  "Default Upload Folder:",
 		"settings.language":        "Interface Language:",
 		"settings.default_server":  "Default",
 		"settings.on":              "On",
@@ -234,29 +329,78 @@ var translations = map[string]map[string]string{
 		"settings.switch_lr":       "(←/→ Switch)",
 		"settings.toggle_space":    "(Space Toggle)",
 		"settings.read_only":       "(Read-only)",
+		"settings.choose_space":            "(Space Choose)",
 		"settings.chunk_placeholder":       "Chunk size (MB)",
 		"settings.concurrency_placeholder": "Concurrency",
+		"settings.parallel_uploads_placeholder": "Parallel files",
 
 		// Upload manager
 		"upload_list.title":        "Upload Manager",
+		"upload_list.paused":       " (queue paused)",
 		"upload_list.empty":        "No upload tasks",
 		"upload_list.col_filename": "Filename",
 		"upload_list.col_size":     "Size",
 		"upload_list.col_progress": "Progress",
 		"upload_list.col_speed":    "Speed",
 		"upload_list.col_server":   "Server",
+		"upload_list.col_destination": "Expiry/Folder",
 		"upload_list.col_status":   "Status",
 
 		// Error screen
 		"error.title": "Error",
 		"error.retry": "• Enter: Retry • Esc: Back",
 
+		// Upload confirmation
+		"confirm.title":       "Upload Directory",
+		"confirm.summary":     "Directory: %s\nFiles: %d\nTotal size: %s",
+		"confirm.skipped":     "Skipping %d file(s) (already in the upload list, over 50GB or unreadable)",
+		"confirm.files_title": "Upload Files",
+		"confirm.file":        "File: %s\nSize: %s",
+		"confirm.files":       "Files: %d\nTotal size: %s",
+		"confirm.model":       "Expiry: %s",
+		"confirm.folder":      "Upload to: %s",
+		"confirm.help":        "• Enter: Start upload • ←/→: Change expiry • f: Choose folder • Esc: Cancel",
+
+		// File expiry
+		"model.24h":     "24 hours",
+		"model.3d":      "3 days",
+		"model.7d":      "7 days",
+		"model.forever": "Permanent",
+		"model.unknown": "Mode %d",
+
+		// Folder picker
+		"folder.title":            "Choose Upload Folder",
+		"folder.current":          "Location: %s\n",
+		"folder.destination":      "Uploading to: %s",
+		"folder.loading":          "Loading folders...",
+		"folder.empty":            "No subfolders",
+		"folder.id_only":          "folder ID %s",
+		"folder.list_failed":      "Failed to list folders (status: %d)",
+		"folder.create_failed":    "Failed to create folder (status: %d)",
+		"folder.save_failed":      "Failed to save default folder: %v",
+		"folder.name_placeholder": "New folder name",
+
+		// Account profiles
+		"profile.title":            "Switch Account",
+		"profile.current":          " (current)",
+		"profile.no_token":         "No token",
+		"profile.keys":             "↑↓:Select Enter:Switch n:New account Esc:Back Q:Quit",
+		"profile.create_keys":      "Enter:Create Esc:Cancel",
+		"profile.name_placeholder": "New profile name",
+		"profile.invalid_name":     "Names may only contain letters, digits, '.', '_' and '-' (max 32 characters) and cannot be default",
+		"profile.exists":           "Profile already exists: %s",
+		"profile.busy":             "Uploads are in progress or queued, switch accounts after they finish",
+		"profile.save_failed":      "Failed to save config: %v",
+
 		// Task statuses
+		"task.queued":         "Queued",
 		"task.starting":       "Starting",
 		"task.pending":        "Pending",
+		"task.hashing":        "Hashing",
 		"task.uploading":      "Uploading",
 		"task.completed":      "Completed",
 		"task.failed":         "Failed",
+		"task.cancelled":      "Cancelled",
 		"task.unknown_server": "Unknown",
 
 		// Upload check messages
@@ -290,6 +434,7 @@ var translations = map[string]map[string]string{
 		"auth.instructions":"TMPLINK Tokenを入力して開始してください：\n\n1. https://tmp.link/ にアクセスしてログイン\n2. 「ファイルをアップロード」→「リセット」をクリックし、下にスクロールして「CLIでアップロード」をクリック\n3. Tokenをクリックしてクリップボードにコピー",
 		"auth.token_label": "Token:",
 		"auth.help":        "💡 Enter: 検証して保存  •  Ctrl+C: 終了",
+		"auth.profile":     "アカウント: %s  •  Ctrl+P: アカウント切替",
 		"auth.error_box":   "❌ 検証失敗\n\n%s",
 		"auth.placeholder": "TMPLINK API Tokenを入力",
 
@@ -304,24 +449,30 @@ var translations = map[string]map[string]string{
 		"loading.token": "\n%s Tokenを検証中...",
 
 		// Status bar
-		"status.user":          "ユーザー: %s",
-		"status.not_logged_in": "ユーザー: 未ログイン",
-		"status.sponsor":       " ✨ (スポンサー)",
-		"status.regular":       " (一般ユーザー)",
-		"status.storage":       "ストレージ: %.1fGB/%.1fGB (%.1f%%)",
-		"status.no_storage":    "ストレージ: プライベートスペースなし",
-		"status.uploading":     " | アップロード中: %dファイル",
-		"status.speed_mb":      " (%.1fMB/s)",
-		"status.speed_kb":      " (%.1fKB/s)",
+		"status.user":           "ユーザー: %s",
+		"status.not_logged_in":  "ユーザー: 未ログイン",
+		"status.sponsor":        " ✨ (スポンサー)",
+		"status.regular":        " (一般ユーザー)",
+		"status.storage":        "ストレージ: %.1fGB/%.1fGB (%.1f%%)",
+		"status.no_storage":     "ストレージ: プライベートスペースなし",
+		"status.uploading":      " | アップロード中: %dファイル",
+		"status.queued":         " | 待機中: %dファイル",
+		"status.speed_mb":       " (%.1fMB/s)",
+		"status.speed_kb":       " (%.1fKB/s)",
+		"status.daemon_offline": " | アップロードサービス未接続、再接続中...",
+		"status.profile":        " | アカウント: %s (P:切替)",
 
 		// Navigation hint keys
 		"nav.enter":            "開く",
 		"nav.upload":           "アップロード",
-		"nav.keys_with_parent": "↑↓:選択 ←→:上へ Enter:%s t:隠しファイル Tab:設定 Q:終了",
-		"nav.keys_no_parent":   "↑↓:選択 Enter:%s t:隠しファイル Tab:設定 Q:終了",
+		"nav.keys_with_parent": "↑↓:選択 ←→:上へ Enter:%s Space:選択 a:全選択 u:選択をアップ r:フォルダをアップ /:絞り込み s:並べ替え g:移動 f:フォルダ t:隠しファイル P:アカウント Tab:設定 Q:終了",
+		"nav.keys_no_parent":   "↑↓:選択 Enter:%s Space:選択 a:全選択 u:選択をアップ r:フォルダをアップ /:絞り込み s:並べ替え g:移動 f:フォルダ t:隠しファイル P:アカウント Tab:設定 Q:終了",
 		"settings.keys":        "↑↓:選択 Enter:保存 Tab:アップロード Esc:戻る Q:終了",
-		"upload_list.keys":     "↑↓:選択 d:削除 t:完了クリア y:全クリア Tab:ファイル Esc:戻る Q:終了",
+		"upload_list.keys":     "↑↓:選択 Shift+↑↓:並べ替え p:一時停止/再開 d:削除 t:完了クリア y:全クリア P:アカウント Tab:ファイル Esc:戻る Q:終了",
 		"error.keys":           "操作: Enter:再試行 Esc:戻る Q:終了",
+		"confirm.keys":         "操作: Enter:アップロード ←→:有効期限 f:フォルダ Esc:キャンセル Q:終了",
+		"folder.keys":          "↑↓:選択 Enter/→:開く ←:上へ u:ここにアップロード d:デフォルトに設定 n:新規フォルダ r:更新 Esc:戻る Q:終了",
+		"folder.create_keys":   "Enter:作成 Esc:キャンセル",
 		"default.keys":         "操作: Q:終了",
 
 		// Menu
@@ -337,9 +488,22 @@ var translations = map[string]map[string]string{
 		"filebrowser.title":       "ファイルブラウザ",
 		"filebrowser.show_hidden": " (隠しファイルを表示)",
 		"filebrowser.current_dir": "現在のディレクトリ: %s\n",
+		"filebrowser.marked":      "選択中: %dファイル (%s)\n",
+		"filebrowser.destination":        "アップロード先: %s\n",
 		"filebrowser.legend":      "📁ディレクトリ 📄ファイル 🟡待機中 🔵アップロード中 🟢完了 🔴失敗\n\n",
 		"filebrowser.empty":       "ディレクトリが空またはロード中...",
 		"filebrowser.scroll":      "\n[%d-%d / 全%d件を表示]",
+		"filebrowser.sort_name":          " · 名前順",
+		"filebrowser.sort_size":          " · サイズ順",
+		"filebrowser.sort_mtime":         " · 更新日時順",
+		"filebrowser.filter":             "絞り込み: %s (%d/%d)\n",
+		"filebrowser.filter_count":       "一致: %d/%d\n",
+		"filebrowser.no_match":           "一致するファイルはありません\n",
+		"filebrowser.filter_placeholder": "ファイル名を入力してあいまい検索",
+		"filebrowser.path_prompt":        "移動先: ",
+		"filebrowser.path_placeholder":   "パスを入力または貼り付け",
+		"filebrowser.filter_keys":        "入力で絞り込み ↑↓:選択 Enter:確定 Esc:解除",
+		"filebrowser.path_keys":          "Enter:移動 Esc:キャンセル",
 
 		// Settings
 		"settings.title":           "アップロード設定",
@@ -347,8 +511,11 @@ var translations = map[string]map[string]string{
 		"settings.require_sponsor": "⚠️  一部の設定はスポンサー権限が必要です\n\n",
 		"settings.chunk_size":      "チャンクサイズ (MB):",
 		"settings.concurrency":     "同時接続数:",
+		"settings.parallel_uploads": "同時アップロードファイル数:",
 		"settings.server":          "アップロードサーバー:",
 		"settings.quick_upload":    "クイックアップロード:",
+		"settings.model":                   "ファイル有効期限:",
+		"settings.folder":                  "デフォルトのアップロード先:",
 		"settings.language":        "インターフェース言語:",
 		"settings.default_server":  "デフォルト",
 		"settings.on":              "オン",
@@ -356,29 +523,78 @@ var translations = map[string]map[string]string{
 		"settings.switch_lr":       "(←/→ 切替)",
 		"settings.toggle_space":    "(スペース 切替)",
 		"settings.read_only":       "(読み取り専用)",
+		"settings.choose_space":            "(スペース 選択)",
 		"settings.chunk_placeholder":       "チャンクサイズ(MB)",
 		"settings.concurrency_placeholder": "同時接続数",
+		"settings.parallel_uploads_placeholder": "同時アップロードファイル数",
 
 		// Upload manager
 		"upload_list.title":        "アップロードマネージャー",
+		"upload_list.paused":       " (キュー一時停止中)",
 		"upload_list.empty":        "アップロードタスクなし",
 		"upload_list.col_filename": "ファイル名",
 		"upload_list.col_size":     "サイズ",
 		"upload_list.col_progress": "進捗",
 		"upload_list.col_speed":    "速度",
 		"upload_list.col_server":   "サーバー",
+		"upload_list.col_destination": "有効期限/フォルダ",
 		"upload_list.col_status":   "状態",
 
 		// Error screen
 		"error.title": "エラー",
 		"error.retry": "• Enter: 再試行 • Esc: 戻る",
 
+		// Upload confirmation
+		"confirm.title":       "フォルダのアップロード",
+		"confirm.summary":     "フォルダ: %s\nファイル数: %d\n合計サイズ: %s",
+		"confirm.skipped":     "%d ファイルをスキップ（アップロード済み・50GB超過・読み取り不可）",
+		"confirm.files_title": "ファイルのアップロード",
+		"confirm.file":        "ファイル: %s\nサイズ: %s",
+		"confirm.files":       "ファイル数: %d\n合計サイズ: %s",
+		"confirm.model":       "有効期限: %s",
+		"confirm.folder":      "アップロード先: %s",
+		"confirm.help":        "• Enter: アップロード開始 • ←/→: 有効期限を切替 • f: フォルダを選択 • Esc: キャンセル",
+
+		// File expiry
+		"model.24h":     "24時間",
+		"model.3d":      "3日",
+		"model.7d":      "7日",
+		"model.forever": "無期限",
+		"model.unknown": "モード %d",
+
+		// Folder picker
+		"folder.title":            "アップロード先フォルダの選択",
+		"folder.current":          "現在の場所: %s\n",
+		"folder.destination":      "アップロード先: %s",
+		"folder.loading":          "フォルダを読み込み中...",
+		"folder.empty":            "サブフォルダはありません",
+		"folder.id_only":          "フォルダID %s",
+		"folder.list_failed":      "フォルダ一覧の取得に失敗しました (ステータス: %d)",
+		"folder.create_failed":    "フォルダの作成に失敗しました (ステータス: %d)",
+		"folder.save_failed":      "デフォルトフォルダの保存に失敗しました: %v",
+		"folder.name_placeholder": "新しいフォルダ名",
+
+		// Account profiles
+		"profile.title":            "アカウント切替",
+		"profile.current":          " (使用中)",
+		"profile.no_token":         "トークン未設定",
+		"profile.keys":             "↑↓:選択 Enter:切替 n:新規アカウント Esc:戻る Q:終了",
+		"profile.create_keys":      "Enter:作成 Esc:キャンセル",
+		"profile.name_placeholder": "新しいプロファイル名",
+		"profile.invalid_name":     "名前に使えるのは英数字と '.'、'_'、'-' のみ（32文字まで、default は不可）",
+		"profile.exists":           "プロファイルは既に存在します: %s",
+		"profile.busy":             "アップロード中または待機中のタスクがあります。完了後に切り替えてください",
+		"profile.save_failed":      "設定の保存に失敗しました: %v",
+
 		// Task statuses
+		"task.queued":         "待機中",
 		"task.starting":       "起動中",
 		"task.pending":        "待機中",
+		"task.hashing":        "ハッシュ計算中",
 		"task.uploading":      "アップロード中",
 		"task.completed":      "完了",
 		"task.failed":         "失敗",
+		"task.cancelled":      "キャンセル済み",
 		"task.unknown_server": "不明",
 
 		// Upload check messages
@@ -412,6 +628,7 @@ var translations = map[string]map[string]string{
 		"auth.instructions": "Введите ваш TMPLINK Token для начала работы:\n\n1. Перейдите на https://tmp.link/ и войдите в аккаунт\n2. Нажмите \"Загрузить файл\", затем \"Сбросить\", прокрутите вниз и нажмите \"Использовать CLI\"\n3. Нажмите на Token, чтобы скопировать его",
 		"auth.token_label":  "Token:",
 		"auth.help":         "💡 Enter: Проверить и сохранить  •  Ctrl+C: Выход",
+		"auth.profile":      "Профиль: %s  •  Ctrl+P: Сменить аккаунт",
 		"auth.error_box":    "❌ Ошибка проверки\n\n%s",
 		"auth.placeholder":  "Введите TMPLINK API Token",
 
@@ -426,24 +643,30 @@ var translations = map[string]map[string]string{
 		"loading.token": "\n%s Проверка токена...",
 
 		// Status bar
-		"status.user":          "Пользователь: %s",
-		"status.not_logged_in": "Пользователь: Не авторизован",
-		"status.sponsor":       " ✨ (Спонсор)",
-		"status.regular":       " (Обычный пользователь)",
-		"status.storage":       "Хранилище: %.1fГБ/%.1fГБ (%.1f%%)",
-		"status.no_storage":    "Хранилище: Нет личного пространства",
-		"status.uploading":     " | Загрузка: %d файл(ов)",
-		"status.speed_mb":      " (%.1fМБ/с)",
-		"status.speed_kb":      " (%.1fКБ/с)",
+		"status.user":           "Пользователь: %s",
+		"status.not_logged_in":  "Пользователь: Не авторизован",
+		"status.sponsor":        " ✨ (Спонсор)",
+		"status.regular":        " (Обычный пользователь)",
+		"status.storage":        "Хранилище: %.1fГБ/%.1fГБ (%.1f%%)",
+		"status.no_storage":     "Хранилище: Нет личного пространства",
+		"status.uploading":      " | Загрузка: %d файл(ов)",
+		"status.queued":         " | В очереди: %d файл(ов)",
+		"status.speed_mb":       " (%.1fМБ/с)",
+		"status.speed_kb":       " (%.1fКБ/с)",
+		"status.daemon_offline": " | Служба загрузки недоступна, переподключение...",
+		"status.profile":        " | Аккаунт: %s (P:Сменить)",
 
 		// Navigation hint keys
 		"nav.enter":            "Открыть",
 		"nav.upload":           "Загрузить",
-		"nav.keys_with_parent": "↑↓:Выбор ←→:Назад Enter:%s t:Скрытые Tab:Настройки Q:Выход",
-		"nav.keys_no_parent":   "↑↓:Выбор Enter:%s t:Скрытые Tab:Настройки Q:Выход",
+		"nav.keys_with_parent": "↑↓:Выбор ←→:Назад Enter:%s Space:Отметить a:Все u:Загрузить отмеченные r:Загрузить папку /:Фильтр s:Сортировка g:Перейти f:Папка t:Скрытые P:Аккаунт Tab:Настройки Q:Выход",
+		"nav.keys_no_parent":   "↑↓:Выбор Enter:%s Space:Отметить a:Все u:Загрузить отмеченные r:Загрузить папку /:Фильтр s:Сортировка g:Перейти f:Папка t:Скрытые P:Аккаунт Tab:Настройки Q:Выход",
 		"settings.keys":        "↑↓:Выбор Enter:Сохранить Tab:Загрузки Esc:Назад Q:Выход",
-		"upload_list.keys":     "↑↓:Выбор d:Удалить t:Очистить y:Удалить всё Tab:Файлы Esc:Назад Q:Выход",
+		"upload_list.keys":     "↑↓:Выбор Shift+↑↓:Порядок p:Пауза/Продолжить d:Удалить t:Очистить y:Удалить всё P:Аккаунт Tab:Файлы Esc:Назад Q:Выход",
 		"error.keys":           "Действия: Enter:Повторить Esc:Назад Q:Выход",
+		"confirm.keys":         "Действия: Enter:Загрузить ←→:Срок f:Папка Esc:Отмена Q:Выход",
+		"folder.keys":          "↑↓:Выбор Enter/→:Открыть ←:Вверх u:Загружать сюда d:По умолчанию n:Новая папка r:Обновить Esc:Назад Q:Выход",
+		"folder.create_keys":   "Enter:Создать Esc:Отмена",
 		"default.keys":         "Действия: Q:Выход",
 
 		// Menu
@@ -459,9 +682,22 @@ var translations = map[string]map[string]string{
 		"filebrowser.title":       "Файловый менеджер",
 		"filebrowser.show_hidden": " (Показаны скрытые файлы)",
 		"filebrowser.current_dir": "Текущая папка: %s\n",
+		"filebrowser.marked":      "Выбрано: %d файл(ов) (%s)\n",
+		"filebrowser.destination":        "Загрузка в: %s\n",
 		"filebrowser.legend":      "📁Папка 📄Файл 🟡Ожидание 🔵Загрузка 🟢Готово 🔴Ошибка\n\n",
 		"filebrowser.empty":       "Папка пуста или загружается...",
 		"filebrowser.scroll":      "\n[Показано %d-%d из %d элементов]",
+		"filebrowser.sort_name":          " · По имени",
+		"filebrowser.sort_size":          " · По размеру",
+		"filebrowser.sort_mtime":         " · По дате изменения",
+		"filebrowser.filter":             "Фильтр: %s (%d/%d)\n",
+		"filebrowser.filter_count":       "Совпадений: %d/%d\n",
+		"filebrowser.no_match":           "Нет подходящих файлов\n",
+		"filebrowser.filter_placeholder": "Введите имя для нечёткого поиска",
+		"filebrowser.path_prompt":        "Перейти: ",
+		"filebrowser.path_placeholder":   "Введите или вставьте путь",
+		"filebrowser.filter_keys":        "Вводите для фильтра ↑↓:Выбор Enter:Применить Esc:Сбросить",
+		"filebrowser.path_keys":          "Enter:Перейти Esc:Отмена",
 
 		// Settings
 		"settings.title":           "Настройки загрузки",
@@ -469,8 +705,11 @@ var translations = map[string]map[string]string{
 		"settings.require_sponsor": "⚠️  Некоторые настройки требуют статуса спонсора\n\n",
 		"settings.chunk_size":      "Размер фрагмента (МБ):",
 		"settings.concurrency":     "Параллельные потоки:",
+		"settings.parallel_uploads": "Файлов одновременно:",
 		"settings.server":          "Сервер загрузки:",
 		"settings.quick_upload":    "Быстрая загрузка:",
+		"settings.model":                   "Срок хранения:",
+		"settings.folder":                  "Папка по умолчанию:",
 		"settings.language":        "Язык интерфейса:",
 		"settings.default_server":  "По умолчанию",
 		"settings.on":              "Вкл",
@@ -478,29 +717,78 @@ var translations = map[string]map[string]string{
 		"settings.switch_lr":       "(←/→ Переключить)",
 		"settings.toggle_space":    "(Пробел Переключить)",
 		"settings.read_only":       "(Только чтение)",
+		"settings.choose_space":            "(Пробел Выбрать)",
 		"settings.chunk_placeholder":       "Размер фрагмента (МБ)",
 		"settings.concurrency_placeholder": "Параллельные потоки",
+		"settings.parallel_uploads_placeholder": "Файлов одновременно",
 
 		// Upload manager
 		"upload_list.title":        "Менеджер загрузок",
+		"upload_list.paused":       " (очередь приостановлена)",
 		"upload_list.empty":        "Нет активных загрузок",
 		"upload_list.col_filename": "Имя файла",
 		"upload_list.col_size":     "Размер",
 		"upload_list.col_progress": "Прогресс",
 		"upload_list.col_speed":    "Скорость",
 		"upload_list.col_server":   "Сервер",
+		"upload_list.col_destination": "Срок/папка",
 		"upload_list.col_status":   "Статус",
 
 		// Error screen
 		"error.title": "Ошибка",
 		"error.retry": "• Enter: Повторить • Esc: Назад",
 
+		// Upload confirmation
+		"confirm.title":       "Загрузка папки",
+		"confirm.summary":     "Папка: %s\nФайлов: %d\nОбщий размер: %s",
+		"confirm.skipped":     "Пропущено файлов: %d (уже в списке, больше 50GB или недоступны)",
+		"confirm.files_title": "Загрузка файлов",
+		"confirm.file":        "Файл: %s\nРазмер: %s",
+		"confirm.files":       "Файлов: %d\nОбщий размер: %s",
+		"confirm.model":       "Срок хранения: %s",
+		"confirm.folder":      "Загрузить в: %s",
+		"confirm.help":        "• Enter: Начать загрузку • ←/→: Срок хранения • f: Выбрать папку • Esc: Отмена",
+
+		// File expiry
+		"model.24h":     "24 часа",
+		"model.3d":      "3 дня",
+		"model.7d":      "7 дней",
+		"model.forever": "Бессрочно",
+		"model.unknown": "Режим %d",
+
+		// Folder picker
+		"folder.title":            "Выбор папки для загрузки",
+		"folder.current":          "Расположение: %s\n",
+		"folder.destination":      "Загрузка в: %s",
+		"folder.loading":          "Загрузка списка папок...",
+		"folder.empty":            "Нет вложенных папок",
+		"folder.id_only":          "ID папки %s",
+		"folder.list_failed":      "Не удалось получить список папок (статус: %d)",
+		"folder.create_failed":    "Не удалось создать папку (статус: %d)",
+		"folder.save_failed":      "Не удалось сохранить папку по умолчанию: %v",
+		"folder.name_placeholder": "Имя новой папки",
+
+		// Account profiles
+		"profile.title":            "Смена аккаунта",
+		"profile.current":          " (текущий)",
+		"profile.no_token":         "Токен не задан",
+		"profile.keys":             "↑↓:Выбор Enter:Сменить n:Новый аккаунт Esc:Назад Q:Выход",
+		"profile.create_keys":      "Enter:Создать Esc:Отмена",
+		"profile.name_placeholder": "Имя нового профиля",
+		"profile.invalid_name":     "Имя может содержать только буквы, цифры, '.', '_' и '-' (до 32 символов) и не может быть default",
+		"profile.exists":           "Профиль уже существует: %s",
+		"profile.busy":             "Есть активные или ожидающие загрузки, смените аккаунт после их завершения",
+		"profile.save_failed":      "Не удалось сохранить настройки: %v",
+
 		// Task statuses
+		"task.queued":         "В очереди",
 		"task.starting":       "Запуск",
 		"task.pending":        "Ожидание",
+		"task.hashing":        "Хеширование",
 		"task.uploading":      "Загрузка",
 		"task.completed":      "Завершено",
 		"task.failed":         "Ошибка",
+		"task.cancelled":      "Отменено",
 		"task.unknown_server": "Неизвестно",
 
 		// Upload check messages
@@ -534,6 +822,7 @@ var translations = map[string]map[string]string{
 		"auth.instructions": "請輸入您的鈦盤 Token 以開始使用：\n\n1. 訪問 https://tmp.link/ 並登入您的帳戶\n2. 點擊「上傳文件」按鈕，再點擊「重新設定」，滑動到頁面底部，點擊「使用 CLI 上傳」\n3. 點擊 Token 以複製到剪貼簿",
 		"auth.token_label":  "Token:",
 		"auth.help":         "💡 Enter: 驗證並儲存  •  Ctrl+C: 退出程式",
+		"auth.profile":      "帳號設定: %s  •  Ctrl+P: 切換帳號",
 		"auth.error_box":    "❌ 驗證失敗\n\n%s",
 		"auth.placeholder":  "請輸入鈦盤 API Token",
 
@@ -548,24 +837,30 @@ var translations = map[string]map[string]string{
 		"loading.token": "\n%s 正在驗證Token...",
 
 		// Status bar
-		"status.user":          "用戶: %s",
-		"status.not_logged_in": "用戶: 未登入",
-		"status.sponsor":       " ✨ (贊助者)",
-		"status.regular":       " (一般用戶)",
-		"status.storage":       "儲存空間: %.1fGB/%.1fGB (%.1f%%)",
-		"status.no_storage":    "儲存空間: 無私人空間",
-		"status.uploading":     " | 上傳中: %d個文件",
-		"status.speed_mb":      " (%.1fMB/s)",
-		"status.speed_kb":      " (%.1fKB/s)",
+		"status.user":           "用戶: %s",
+		"status.not_logged_in":  "用戶: 未登入",
+		"status.sponsor":        " ✨ (贊助者)",
+		"status.regular":        " (一般用戶)",
+		"status.storage":        "儲存空間: %.1fGB/%.1fGB (%.1f%%)",
+		"status.no_storage":     "儲存空間: 無私人空間",
+		"status.uploading":      " | 上傳中: %d個文件",
+		"status.queued":         " | 排隊: %d個文件",
+		"status.speed_mb":       " (%.1fMB/s)",
+		"status.speed_kb":       " (%.1fKB/s)",
+		"status.daemon_offline": " | 上傳服務未連線，正在重新連線...",
+		"status.profile":        " | 帳號: %s (P:切換)",
 
 		// Navigation hint keys
 		"nav.enter":            "進入",
 		"nav.upload":           "上傳",
-		"nav.keys_with_parent": "↑↓:選擇 ←→:上層 Enter:%s t:隱藏文件 Tab:設定 Q:退出",
-		"nav.keys_no_parent":   "↑↓:選擇 Enter:%s t:隱藏文件 Tab:設定 Q:退出",
+		"nav.keys_with_parent": "↑↓:選擇 ←→:上層 Enter:%s Space:標記 a:全選 u:上傳所選 r:上傳目錄 /:篩選 s:排序 g:跳轉 f:資料夾 t:隱藏文件 P:帳號 Tab:設定 Q:退出",
+		"nav.keys_no_parent":   "↑↓:選擇 Enter:%s Space:標記 a:全選 u:上傳所選 r:上傳目錄 /:篩選 s:排序 g:跳轉 f:資料夾 t:隱藏文件 P:帳號 Tab:設定 Q:退出",
 		"settings.keys":        "↑↓:選擇 Enter:儲存 Tab:上傳管理 Esc:返回 Q:退出",
-		"upload_list.keys":     "↑↓:選擇 d:刪除 t:清除完成 y:清除全部 Tab:文件瀏覽 Esc:返回 Q:退出",
+		"upload_list.keys":     "↑↓:選擇 Shift+↑↓:調整順序 p:暫停/繼續 d:刪除 t:清除完成 y:清除全部 P:帳號 Tab:文件瀏覽 Esc:返回 Q:退出",
 		"error.keys":           "操作: Enter:重試 Esc:返回 Q:退出",
+		"confirm.keys":         "操作: Enter:開始上傳 ←→:有效期 f:資料夾 Esc:取消 Q:退出",
+		"folder.keys":          "↑↓:選擇 Enter/→:打開 ←:上級 u:上傳到此資料夾 d:設為預設 n:新建資料夾 r:重新整理 Esc:返回 Q:退出",
+		"folder.create_keys":   "Enter:建立 Esc:取消",
 		"default.keys":         "操作: Q:退出",
 
 		// Menu
@@ -581,9 +876,22 @@ var translations = map[string]map[string]string{
 		"filebrowser.title":       "文件瀏覽器",
 		"filebrowser.show_hidden": " (顯示隱藏文件)",
 		"filebrowser.current_dir": "當前目錄: %s\n",
+		"filebrowser.marked":      "已選擇: %d個文件 (%s)\n",
+		"filebrowser.destination":        "上傳到: %s\n",
 		"filebrowser.legend":      "📁目錄 📄文件 🟡等待 🔵上傳中 🟢已完成 🔴失敗\n\n",
 		"filebrowser.empty":       "目錄為空或正在載入...",
 		"filebrowser.scroll":      "\n[顯示 %d-%d / 共 %d 項]",
+		"filebrowser.sort_name":          " · 按名稱排序",
+		"filebrowser.sort_size":          " · 按大小排序",
+		"filebrowser.sort_mtime":         " · 按修改時間排序",
+		"filebrowser.filter":             "篩選: %s (%d/%d)\n",
+		"filebrowser.filter_count":       "符合: %d/%d\n",
+		"filebrowser.no_match":           "沒有符合的文件\n",
+		"filebrowser.filter_placeholder": "輸入文件名進行模糊篩選",
+		"filebrowser.path_prompt":        "跳轉到: ",
+		"filebrowser.path_placeholder":   "輸入或貼上路徑",
+		"filebrowser.filter_keys":        "輸入以篩選 ↑↓:選擇 Enter:確定 Esc:清除篩選",
+		"filebrowser.path_keys":          "Enter:跳轉 Esc:取消",
 
 		// Settings
 		"settings.title":           "上傳設定",
@@ -591,8 +899,11 @@ var translations = map[string]map[string]string{
 		"settings.require_sponsor": "⚠️  部分設定需要贊助者權限\n\n",
 		"settings.chunk_size":      "分塊大小 (MB):",
 		"settings.concurrency":     "並發數:",
+		"settings.parallel_uploads": "同時上傳檔案數:",
 		"settings.server":          "上傳伺服器:",
 		"settings.quick_upload":    "快速上傳:",
+		"settings.model":                   "文件有效期:",
+		"settings.folder":                  "預設上傳資料夾:",
 		"settings.language":        "介面語言:",
 		"settings.default_server":  "預設",
 		"settings.on":              "開啟",
@@ -600,29 +911,78 @@ var translations = map[string]map[string]string{
 		"settings.switch_lr":       "(←/→ 切換)",
 		"settings.toggle_space":    "(Space 切換)",
 		"settings.read_only":       "(唯讀)",
+		"settings.choose_space":            "(Space 選擇)",
 		"settings.chunk_placeholder":       "分塊大小(MB)",
 		"settings.concurrency_placeholder": "並發數",
+		"settings.parallel_uploads_placeholder": "同時上傳檔案數",
 
 		// Upload manager
 		"upload_list.title":        "上傳管理器",
+		"upload_list.paused":       " (佇列已暫停)",
 		"upload_list.empty":        "暫無上傳任務",
 		"upload_list.col_filename": "文件名",
 		"upload_list.col_size":     "大小",
 		"upload_list.col_progress": "進度",
 		"upload_list.col_speed":    "速度",
 		"upload_list.col_server":   "伺服器",
+		"upload_list.col_destination": "有效期/資料夾",
 		"upload_list.col_status":   "狀態",
 
 		// Error screen
 		"error.title": "錯誤",
 		"error.retry": "• Enter: 重試 • Esc: 返回",
 
+		// Upload confirmation
+		"confirm.title":       "上傳目錄",
+		"confirm.summary":     "目錄: %s\n文件數: %d\n總大小: %s",
+		"confirm.skipped":     "跳過 %d 個文件（已在上傳列表中、超過50GB或無法讀取）",
+		"confirm.files_title": "上傳文件",
+		"confirm.file":        "文件: %s\n大小: %s",
+		"confirm.files":       "文件數: %d\n總大小: %s",
+		"confirm.model":       "文件有效期: %s",
+		"confirm.folder":      "上傳到: %s",
+		"confirm.help":        "• Enter: 開始上傳 • ←/→: 切換有效期 • f: 選擇資料夾 • Esc: 取消",
+
+		// File expiry
+		"model.24h":     "24小時",
+		"model.3d":      "3天",
+		"model.7d":      "7天",
+		"model.forever": "永久",
+		"model.unknown": "模式 %d",
+
+		// Folder picker
+		"folder.title":            "選擇上傳資料夾",
+		"folder.current":          "目前位置: %s\n",
+		"folder.destination":      "上傳到: %s",
+		"folder.loading":          "正在載入資料夾...",
+		"folder.empty":            "沒有子資料夾",
+		"folder.id_only":          "目錄ID %s",
+		"folder.list_failed":      "取得資料夾列表失敗 (狀態碼: %d)",
+		"folder.create_failed":    "建立資料夾失敗 (狀態碼: %d)",
+		"folder.save_failed":      "儲存預設資料夾失敗: %v",
+		"folder.name_placeholder": "新資料夾名稱",
+
+		// Account profiles
+		"profile.title":            "切換帳號",
+		"profile.current":          " (目前)",
+		"profile.no_token":         "未設定Token",
+		"profile.keys":             "↑↓:選擇 Enter:切換 n:新建帳號 Esc:返回 Q:退出",
+		"profile.create_keys":      "Enter:建立 Esc:取消",
+		"profile.name_placeholder": "新帳號設定名稱",
+		"profile.invalid_name":     "名稱只能包含字母、數字、'.'、'_' 和 '-'，最長32個字元，且不能為 default",
+		"profile.exists":           "帳號設定已存在: %s",
+		"profile.busy":             "有正在進行或排隊的上傳，完成後再切換帳號",
+		"profile.save_failed":      "儲存設定失敗: %v",
+
 		// Task statuses
+		"task.queued":         "排隊中",
 		"task.starting":       "啟動中",
 		"task.pending":        "等待中",
+		"task.hashing":        "校驗中",
 		"task.uploading":      "上傳中",
 		"task.completed":      "已完成",
 		"task.failed":         "失敗",
+		"task.cancelled":      "已取消",
 		"task.unknown_server": "未知",
 
 		// Upload check messages
@@ -656,6 +1016,7 @@ var translations = map[string]map[string]string{
 		"auth.instructions": "Entrez votre Token TMPLINK pour commencer :\n\n1. Rendez-vous sur https://tmp.link/ et connectez-vous\n2. Cliquez sur \"Envoyer un fichier\", puis \"Réinitialiser\", faites défiler vers le bas et cliquez sur \"Utiliser le CLI\"\n3. Cliquez sur le Token pour le copier",
 		"auth.token_label":  "Token :",
 		"auth.help":         "💡 Entrée : Vérifier et enregistrer  •  Ctrl+C : Quitter",
+		"auth.profile":      "Profil : %s  •  Ctrl+P : Changer de compte",
 		"auth.error_box":    "❌ Échec de la vérification\n\n%s",
 		"auth.placeholder":  "Entrez votre Token API TMPLINK",
 
@@ -670,24 +1031,30 @@ var translations = map[string]map[string]string{
 		"loading.token": "\n%s Validation du token...",
 
 		// Status bar
-		"status.user":          "Utilisateur : %s",
-		"status.not_logged_in": "Utilisateur : Non connecté",
-		"status.sponsor":       " ✨ (Sponsor)",
-		"status.regular":       " (Utilisateur gratuit)",
-		"status.storage":       "Stockage : %.1fGo/%.1fGo (%.1f%%)",
-		"status.no_storage":    "Stockage : Aucun espace privé",
-		"status.uploading":     " | Envoi : %d fichier(s)",
-		"status.speed_mb":      " (%.1fMo/s)",
-		"status.speed_kb":      " (%.1fKo/s)",
+		"status.user":           "Utilisateur : %s",
+		"status.not_logged_in":  "Utilisateur : Non connecté",
+		"status.sponsor":        " ✨ (Sponsor)",
+		"status.regular":        " (Utilisateur gratuit)",
+		"status.storage":        "Stockage : %.1fGo/%.1fGo (%.1f%%)",
+		"status.no_storage":     "Stockage : Aucun espace privé",
+		"status.uploading":      " | Envoi : %d fichier(s)",
+		"status.queued":         " | En file : %d fichier(s)",
+		"status.speed_mb":       " (%.1fMo/s)",
+		"status.speed_kb":       " (%.1fKo/s)",
+		"status.daemon_offline": " | Service d'envoi hors ligne, reconnexion...",
+		"status.profile":        " | Compte : %s (P:Changer)",
 
 		// Navigation hint keys
 		"nav.enter":            "Ouvrir",
 		"nav.upload":           "Envoyer",
-		"nav.keys_with_parent": "↑↓:Sélect ←→:Parent Entrée:%s t:Cachés Tab:Param Q:Quitter",
-		"nav.keys_no_parent":   "↑↓:Sélect Entrée:%s t:Cachés Tab:Param Q:Quitter",
+		"nav.keys_with_parent": "↑↓:Sélect ←→:Parent Entrée:%s Espace:Marquer a:Tout u:Envoyer sélection r:Envoyer dossier /:Filtrer s:Trier g:Aller f:Dossier t:Cachés P:Compte Tab:Param Q:Quitter",
+		"nav.keys_no_parent":   "↑↓:Sélect Entrée:%s Espace:Marquer a:Tout u:Envoyer sélection r:Envoyer dossier /:Filtrer s:Trier g:Aller f:Dossier t:Cachés P:Compte Tab:Param Q:Quitter",
 		"settings.keys":        "↑↓:Sélect Entrée:Sauv Tab:Envois Échap:Retour Q:Quitter",
-		"upload_list.keys":     "↑↓:Sélect d:Supp t:Vider y:Tout supp Tab:Fichiers Échap:Retour Q:Quitter",
+		"upload_list.keys":     "↑↓:Sélect Shift+↑↓:Ordre p:Pause/Reprise d:Supp t:Vider y:Tout supp P:Compte Tab:Fichiers Échap:Retour Q:Quitter",
 		"error.keys":           "Actions : Entrée:Réessayer Échap:Retour Q:Quitter",
+		"confirm.keys":         "Actions : Entrée:Envoyer ←→:Durée f:Dossier Échap:Annuler Q:Quitter",
+		"folder.keys":          "↑↓:Sélect Entrée/→:Ouvrir ←:Parent u:Envoyer ici d:Par défaut n:Nouveau dossier r:Actualiser Échap:Retour Q:Quitter",
+		"folder.create_keys":   "Entrée:Créer Échap:Annuler",
 		"default.keys":         "Actions : Q:Quitter",
 
 		// Menu
@@ -703,9 +1070,22 @@ var translations = map[string]map[string]string{
 		"filebrowser.title":       "Explorateur de fichiers",
 		"filebrowser.show_hidden": " (Fichiers cachés visibles)",
 		"filebrowser.current_dir": "Dossier courant : %s\n",
+		"filebrowser.marked":      "Sélection : %d fichier(s) (%s)\n",
+		"filebrowser.destination":        "Destination : %s\n",
 		"filebrowser.legend":      "📁Dossier 📄Fichier 🟡Attente 🔵Envoi 🟢Terminé 🔴Erreur\n\n",
 		"filebrowser.empty":       "Dossier vide ou chargement...",
 		"filebrowser.scroll":      "\n[Affichage %d-%d sur %d éléments]",
+		"filebrowser.sort_name":          " · Tri par nom",
+		"filebrowser.sort_size":          " · Tri par taille",
+		"filebrowser.sort_mtime":         " · Tri par date de modification",
+		"filebrowser.filter":             "Filtre : %s (%d/%d)\n",
+		"filebrowser.filter_count":       "Correspondances : %d/%d\n",
+		"filebrowser.no_match":           "Aucun fichier correspondant\n",
+		"filebrowser.filter_placeholder": "Tapez pour filtrer les noms (approximatif)",
+		"filebrowser.path_prompt":        "Aller à : ",
+		"filebrowser.path_placeholder":   "Tapez ou collez un chemin",
+		"filebrowser.filter_keys":        "Tapez pour filtrer ↑↓:Sélect Entrée:Appliquer Échap:Effacer",
+		"filebrowser.path_keys":          "Entrée:Aller Échap:Annuler",
 
 		// Settings
 		"settings.title":           "Paramètres d'envoi",
@@ -713,8 +1093,11 @@ var translations = map[string]map[string]string{
 		"settings.require_sponsor": "⚠️  Certains paramètres nécessitent le statut sponsor\n\n",
 		"settings.chunk_size":      "Taille des fragments (Mo) :",
 		"settings.concurrency":     "Connexions simultanées :",
+		"settings.parallel_uploads": "Fichiers simultanés :",
 		"settings.server":          "Serveur d'envoi :",
 		"settings.quick_upload":    "Envoi rapide :",
+		"settings.model":                   "Durée de conservation :",
+		"settings.folder":                  "Dossier d'envoi par défaut :",
 		"settings.language":        "Langue de l'interface :",
 		"settings.default_server":  "Par défaut",
 		"settings.on":              "Activé",
@@ -722,29 +1105,78 @@ var translations = map[string]map[string]string{
 		"settings.switch_lr":       "(←/→ Changer)",
 		"settings.toggle_space":    "(Espace Basculer)",
 		"settings.read_only":       "(Lecture seule)",
+		"settings.choose_space":            "(Espace Choisir)",
 		"settings.chunk_placeholder":       "Taille des fragments (Mo)",
 		"settings.concurrency_placeholder": "Connexions simultanées",
+		"settings.parallel_uploads_placeholder": "Fichiers simultanés",
 
 		// Upload manager
 		"upload_list.title":        "Gestionnaire d'envois",
+		"upload_list.paused":       " (file en pause)",
 		"upload_list.empty":        "Aucun envoi en cours",
 		"upload_list.col_filename": "Nom du fichier",
 		"upload_list.col_size":     "Taille",
 		"upload_list.col_progress": "Progression",
 		"upload_list.col_speed":    "Vitesse",
 		"upload_list.col_server":   "Serveur",
+		"upload_list.col_destination": "Durée/Dossier",
 		"upload_list.col_status":   "Statut",
 
 		// Error screen
 		"error.title": "Erreur",
 		"error.retry": "• Entrée : Réessayer • Échap : Retour",
 
+		// Upload confirmation
+		"confirm.title":       "Envoyer un dossier",
+		"confirm.summary":     "Dossier : %s\nFichiers : %d\nTaille totale : %s",
+		"confirm.skipped":     "%d fichier(s) ignoré(s) (déjà dans la liste, plus de 50GB ou illisibles)",
+		"confirm.files_title": "Envoyer des fichiers",
+		"confirm.file":        "Fichier : %s\nTaille : %s",
+		"confirm.files":       "Fichiers : %d\nTaille totale : %s",
+		"confirm.model":       "Durée de conservation : %s",
+		"confirm.folder":      "Envoyer vers : %s",
+		"confirm.help":        "• Entrée : Lancer l'envoi • ←/→ : Changer la durée • f : Choisir le dossier • Échap : Annuler",
+
+		// File expiry
+		"model.24h":     "24 heures",
+		"model.3d":      "3 jours",
+		"model.7d":      "7 jours",
+		"model.forever": "Permanent",
+		"model.unknown": "Mode %d",
+
+		// Folder picker
+		"folder.title":            "Choisir le dossier de destination",
+		"folder.current":          "Emplacement : %s\n",
+		"folder.destination":      "Destination : %s",
+		"folder.loading":          "Chargement des dossiers...",
+		"folder.empty":            "Aucun sous-dossier",
+		"folder.id_only":          "dossier ID %s",
+		"folder.list_failed":      "Échec de la récupération des dossiers (statut : %d)",
+		"folder.create_failed":    "Échec de la création du dossier (statut : %d)",
+		"folder.save_failed":      "Échec de l'enregistrement du dossier par défaut : %v",
+		"folder.name_placeholder": "Nom du nouveau dossier",
+
+		// Account profiles
+		"profile.title":            "Changer de compte",
+		"profile.current":          " (actuel)",
+		"profile.no_token":         "Aucun token",
+		"profile.keys":             "↑↓:Sélect Entrée:Changer n:Nouveau compte Échap:Retour Q:Quitter",
+		"profile.create_keys":      "Entrée:Créer Échap:Annuler",
+		"profile.name_placeholder": "Nom du nouveau profil",
+		"profile.invalid_name":     "Le nom ne peut contenir que des lettres, chiffres, '.', '_' et '-' (32 caractères max) et ne peut pas être default",
+		"profile.exists":           "Le profil existe déjà : %s",
+		"profile.busy":             "Des envois sont en cours ou en attente, changez de compte une fois terminés",
+		"profile.save_failed":      "Échec de l'enregistrement de la configuration : %v",
+
 		// Task statuses
+		"task.queued":         "En file",
 		"task.starting":       "Démarrage",
 		"task.pending":        "En attente",
+		"task.hashing":        "Calcul du hachage",
 		"task.uploading":      "Envoi en cours",
 		"task.completed":      "Terminé",
 		"task.failed":         "Échec",
+		"task.cancelled":      "Annulé",
 		"task.unknown_server": "Inconnu",
 
 		// Upload check messages
@@ -778,6 +1210,7 @@ var translations = map[string]map[string]string{
 		"auth.instructions": "Masukkan Token TMPLINK anda untuk memulakan:\n\n1. Layari https://tmp.link/ dan log masuk\n2. Klik \"Muat Naik Fail\", kemudian \"Set Semula\", tatal ke bawah dan klik \"Guna CLI\"\n3. Klik Token untuk menyalinnya",
 		"auth.token_label":  "Token:",
 		"auth.help":         "💡 Enter: Sahkan & Simpan  •  Ctrl+C: Keluar",
+		"auth.profile":      "Profil: %s  •  Ctrl+P: Tukar akaun",
 		"auth.error_box":    "❌ Pengesahan Gagal\n\n%s",
 		"auth.placeholder":  "Masukkan Token API TMPLINK anda",
 
@@ -792,24 +1225,30 @@ var translations = map[string]map[string]string{
 		"loading.token": "\n%s Mengesahkan token...",
 
 		// Status bar
-		"status.user":          "Pengguna: %s",
-		"status.not_logged_in": "Pengguna: Belum log masuk",
-		"status.sponsor":       " ✨ (Penaja)",
-		"status.regular":       " (Pengguna Biasa)",
-		"status.storage":       "Storan: %.1fGB/%.1fGB (%.1f%%)",
-		"status.no_storage":    "Storan: Tiada ruang peribadi",
-		"status.uploading":     " | Memuat naik: %d fail",
-		"status.speed_mb":      " (%.1fMB/s)",
-		"status.speed_kb":      " (%.1fKB/s)",
+		"status.user":           "Pengguna: %s",
+		"status.not_logged_in":  "Pengguna: Belum log masuk",
+		"status.sponsor":        " ✨ (Penaja)",
+		"status.regular":        " (Pengguna Biasa)",
+		"status.storage":        "Storan: %.1fGB/%.1fGB (%.1f%%)",
+		"status.no_storage":     "Storan: Tiada ruang peribadi",
+		"status.uploading":      " | Memuat naik: %d fail",
+		"status.queued":         " | Dalam giliran: %d fail",
+		"status.speed_mb":       " (%.1fMB/s)",
+		"status.speed_kb":       " (%.1fKB/s)",
+		"status.daemon_offline": " | Perkhidmatan muat naik terputus, menyambung semula...",
+		"status.profile":        " | Akaun: %s (P:Tukar)",
 
 		// Navigation hint keys
 		"nav.enter":            "Buka",
 		"nav.upload":           "Muat Naik",
-		"nav.keys_with_parent": "↑↓:Pilih ←→:Induk Enter:%s t:Tersembunyi Tab:Tetapan Q:Keluar",
-		"nav.keys_no_parent":   "↑↓:Pilih Enter:%s t:Tersembunyi Tab:Tetapan Q:Keluar",
+		"nav.keys_with_parent": "↑↓:Pilih ←→:Induk Enter:%s Space:Tanda a:Semua u:MuatNaikTanda r:MuatNaikDir /:Tapis s:Isih g:PergiKe f:Folder t:Tersembunyi P:Akaun Tab:Tetapan Q:Keluar",
+		"nav.keys_no_parent":   "↑↓:Pilih Enter:%s Space:Tanda a:Semua u:MuatNaikTanda r:MuatNaikDir /:Tapis s:Isih g:PergiKe f:Folder t:Tersembunyi P:Akaun Tab:Tetapan Q:Keluar",
 		"settings.keys":        "↑↓:Pilih Enter:Simpan Tab:Muat Naik Esc:Kembali Q:Keluar",
-		"upload_list.keys":     "↑↓:Pilih d:Padam t:Bersih y:Padam Semua Tab:Fail Esc:Kembali Q:Keluar",
+		"upload_list.keys":     "↑↓:Pilih Shift+↑↓:Susun p:Jeda/Sambung d:Padam t:Bersih y:Padam Semua P:Akaun Tab:Fail Esc:Kembali Q:Keluar",
 		"error.keys":           "Tindakan: Enter:Cuba Lagi Esc:Kembali Q:Keluar",
+		"confirm.keys":         "Tindakan: Enter:Muat Naik ←→:Tempoh f:Folder Esc:Batal Q:Keluar",
+		"folder.keys":          "↑↓:Pilih Enter/→:Buka ←:Induk u:Muat naik ke sini d:Jadikan lalai n:Folder baharu r:Muat semula Esc:Kembali Q:Keluar",
+		"folder.create_keys":   "Enter:Cipta Esc:Batal",
 		"default.keys":         "Tindakan: Q:Keluar",
 
 		// Menu
@@ -825,9 +1264,22 @@ var translations = map[string]map[string]string{
 		"filebrowser.title":       "Pelayar Fail",
 		"filebrowser.show_hidden": " (Menunjukkan fail tersembunyi)",
 		"filebrowser.current_dir": "Direktori semasa: %s\n",
+		"filebrowser.marked":      "Dipilih: %d fail (%s)\n",
+		"filebrowser.destination":        "Muat naik ke: %s\n",
 		"filebrowser.legend":      "📁Folder 📄Fail 🟡Menunggu 🔵Memuat Naik 🟢Selesai 🔴Gagal\n\n",
 		"filebrowser.empty":       "Direktori kosong atau sedang dimuatkan...",
 		"filebrowser.scroll":      "\n[Menunjukkan %d-%d daripada %d item]",
+		"filebrowser.sort_name":          " · Isih ikut nama",
+		"filebrowser.sort_size":          " · Isih ikut saiz",
+		"filebrowser.sort_mtime":         " · Isih ikut masa ubah suai",
+		"filebrowser.filter":             "Tapisan: %s (%d/%d)\n",
+		"filebrowser.filter_count":       "Padanan: %d/%d\n",
+		"filebrowser.no_match":           "Tiada fail yang sepadan\n",
+		"filebrowser.filter_placeholder": "Taip untuk menapis nama fail",
+		"filebrowser.path_prompt":        "Pergi ke: ",
+		"filebrowser.path_placeholder":   "Taip atau tampal laluan",
+		"filebrowser.filter_keys":        "Taip untuk menapis ↑↓:Pilih Enter:Guna Esc:Kosongkan",
+		"filebrowser.path_keys":          "Enter:Pergi Esc:Batal",
 
 		// Settings
 		"settings.title":           "Tetapan Muat Naik",
@@ -835,8 +1287,11 @@ var translations = map[string]map[string]string{
 		"settings.require_sponsor": "⚠️  Sesetengah tetapan memerlukan status penaja\n\n",
 		"settings.chunk_size":      "Saiz Serpihan (MB):",
 		"settings.concurrency":     "Sambungan Serentak:",
+		"settings.parallel_uploads": "Fail Serentak:",
 		"settings.server":          "Pelayan Muat Naik:",
 		"settings.quick_upload":    "Muat Naik Pantas:",
+		"settings.model":                   "Tempoh Simpanan:",
+		"settings.folder":                  "Folder Muat Naik Lalai:",
 		"settings.language":        "Bahasa Antara Muka:",
 		"settings.default_server":  "Lalai",
 		"settings.on":              "Hidup",
@@ -844,29 +1299,78 @@ var translations = map[string]map[string]string{
 		"settings.switch_lr":       "(←/→ Tukar)",
 		"settings.toggle_space":    "(Ruang Togol)",
 		"settings.read_only":       "(Baca Sahaja)",
+		"settings.choose_space":            "(Ruang Pilih)",
 		"settings.chunk_placeholder":       "Saiz serpihan (MB)",
 		"settings.concurrency_placeholder": "Sambungan serentak",
+		"settings.parallel_uploads_placeholder": "Fail serentak",
 
 		// Upload manager
 		"upload_list.title":        "Pengurus Muat Naik",
+		"upload_list.paused":       " (baris gilir dijeda)",
 		"upload_list.empty":        "Tiada tugas muat naik",
 		"upload_list.col_filename": "Nama Fail",
 		"upload_list.col_size":     "Saiz",
 		"upload_list.col_progress": "Kemajuan",
 		"upload_list.col_speed":    "Kelajuan",
 		"upload_list.col_server":   "Pelayan",
+		"upload_list.col_destination": "Tempoh/Folder",
 		"upload_list.col_status":   "Status",
 
 		// Error screen
 		"error.title": "Ralat",
 		"error.retry": "• Enter: Cuba Lagi • Esc: Kembali",
 
+		// Upload confirmation
+		"confirm.title":       "Muat Naik Direktori",
+		"confirm.summary":     "Direktori: %s\nBilangan fail: %d\nJumlah saiz: %s",
+		"confirm.skipped":     "Melangkau %d fail (sudah dalam senarai, melebihi 50GB atau tidak boleh dibaca)",
+		"confirm.files_title": "Muat Naik Fail",
+		"confirm.file":        "Fail: %s\nSaiz: %s",
+		"confirm.files":       "Bilangan fail: %d\nJumlah saiz: %s",
+		"confirm.model":       "Tempoh simpanan: %s",
+		"confirm.folder":      "Muat naik ke: %s",
+		"confirm.help":        "• Enter: Mula muat naik • ←/→: Tukar tempoh • f: Pilih folder • Esc: Batal",
+
+		// File expiry
+		"model.24h":     "24 jam",
+		"model.3d":      "3 hari",
+		"model.7d":      "7 hari",
+		"model.forever": "Kekal",
+		"model.unknown": "Mod %d",
+
+		// Folder picker
+		"folder.title":            "Pilih Folder Muat Naik",
+		"folder.current":          "Lokasi: %s\n",
+		"folder.destination":      "Muat naik ke: %s",
+		"folder.loading":          "Memuatkan folder...",
+		"folder.empty":            "Tiada subfolder",
+		"folder.id_only":          "ID folder %s",
+		"folder.list_failed":      "Gagal mendapatkan senarai folder (status: %d)",
+		"folder.create_failed":    "Gagal mencipta folder (status: %d)",
+		"folder.save_failed":      "Gagal menyimpan folder lalai: %v",
+		"folder.name_placeholder": "Nama folder baharu",
+
+		// Account profiles
+		"profile.title":            "Tukar Akaun",
+		"profile.current":          " (semasa)",
+		"profile.no_token":         "Tiada token",
+		"profile.keys":             "↑↓:Pilih Enter:Tukar n:Akaun baharu Esc:Kembali Q:Keluar",
+		"profile.create_keys":      "Enter:Cipta Esc:Batal",
+		"profile.name_placeholder": "Nama profil baharu",
+		"profile.invalid_name":     "Nama hanya boleh mengandungi huruf, digit, '.', '_' dan '-' (maks 32 aksara) dan tidak boleh default",
+		"profile.exists":           "Profil sudah wujud: %s",
+		"profile.busy":             "Terdapat muat naik yang sedang berjalan atau beratur, tukar akaun selepas selesai",
+		"profile.save_failed":      "Gagal menyimpan konfigurasi: %v",
+
 		// Task statuses
+		"task.queued":         "Dalam giliran",
 		"task.starting":       "Bermula",
 		"task.pending":        "Menunggu",
+		"task.hashing":        "Mengira cincangan",
 		"task.uploading":      "Memuat Naik",
 		"task.completed":      "Selesai",
 		"task.failed":         "Gagal",
+		"task.cancelled":      "Dibatalkan",
 		"task.unknown_server": "Tidak Diketahui",
 
 		// Upload check messages
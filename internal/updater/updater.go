@@ -11,6 +11,8 @@ import (
 	"runtime"
 	"syscall"
 	"time"
+
+	"tmplink_uploader/internal/config"
 )
 
 const (
@@ -32,21 +34,6 @@ type UpdateInfo struct {
 	CurrentVersion string
 }
 
-// SharedConfig represents the shared configuration structure for both GUI and CLI
-type SharedConfig struct {
-	Token              string    `json:"token"`
-	UploadServer       string    `json:"upload_server"`
-	SelectedServerName string    `json:"selected_server_name"`
-	ChunkSize          int       `json:"chunk_size"`
-	MaxConcurrent      int       `json:"max_concurrent"`
-	QuickUpload        bool      `json:"quick_upload"`
-	SkipUpload         bool      `json:"skip_upload"`
-	LastUpdateCheck    time.Time `json:"last_update_check"`
-	// CLI专用字段
-	Model int    `json:"model"`
-	MrID  string `json:"mr_id"`
-}
-
 // GetPlatformSuffix returns the platform suffix based on runtime.GOOS and runtime.GOARCH
 func GetPlatformSuffix() string {
 	switch runtime.GOOS {
@@ -295,80 +282,31 @@ func AutoUpdate(programType string, currentVersion string) error {
 	return nil
 }
 
-// getSharedConfigPath returns the shared config file path
-func getSharedConfigPath() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return ".tmplink_config.json"
-	}
-	return filepath.Join(homeDir, ".tmplink_config.json")
-}
-
 // shouldCheckUpdate checks if enough time has passed since last update check
 func shouldCheckUpdate(programType string) bool {
-	configPath := getSharedConfigPath()
-	
-	data, err := os.ReadFile(configPath)
+	saved, err := config.Load()
 	if err != nil {
-		// Config file doesn't exist, should check
+		// Config file unreadable or invalid, should check
 		return true
 	}
-	
-	var config SharedConfig
-	if err := json.Unmarshal(data, &config); err != nil {
-		// Invalid format, should check
-		return true
-	}
-	
+
 	// Check if enough time has passed
-	if config.LastUpdateCheck.IsZero() {
+	if saved.LastUpdateCheck.IsZero() {
 		return true
 	}
-	
-	return time.Since(config.LastUpdateCheck) > UPDATE_CHECK_INTERVAL
+
+	return time.Since(saved.LastUpdateCheck) > UPDATE_CHECK_INTERVAL
 }
 
 // saveUpdateCheckTime saves the current time as last update check time
+// Only last_update_check is written, other settings in the shared config are kept as they are
 func saveUpdateCheckTime(programType string) error {
-	configPath := getSharedConfigPath()
-	now := time.Now()
-	
-	// Try to load existing config
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		// Config file doesn't exist, create a new one with default values
-		configDir := filepath.Dir(configPath)
-		if err := os.MkdirAll(configDir, 0755); err != nil {
-			return err
-		}
-		
-		config := SharedConfig{
-			ChunkSize:       3,
-			MaxConcurrent:   5,
-			QuickUpload:     true,
-			SkipUpload:      false,
-			Model:           0,
-			MrID:            "0",
-			LastUpdateCheck: now,
-		}
-		newData, err := json.MarshalIndent(config, "", "  ")
-		if err != nil {
-			return err
-		}
-		return os.WriteFile(configPath, newData, 0644)
-	}
-	
-	// Update existing config
-	var config SharedConfig
-	if err := json.Unmarshal(data, &config); err != nil {
-		return err
-	}
-	config.LastUpdateCheck = now
-	newData, err := json.MarshalIndent(config, "", "  ")
+	saved, err := config.Load()
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(configPath, newData, 0644)
+	saved.LastUpdateCheck = time.Now()
+	return saved.Save()
 }
 
 // CheckUpdateOnStartup performs a background update check on program startup and auto-updates if found